@@ -0,0 +1,119 @@
+package main
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alan-b-lima/almodon/pkg/cert"
+)
+
+const (
+	_AuthorityValidity   = 10 * 365 * 24 * time.Hour
+	_CertificateValidity = 825 * 24 * time.Hour
+)
+
+// CertDev generates a certificate authority for development, if there
+// is none in the directory yet, and issues a server certificate and a
+// client certificate, for the control plane, from it.
+func CertDev(name string, args []string) error {
+	var dir, hosts, client string
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.StringVar(&dir, "dir", "certs", "directory the certificates are written to")
+	flags.StringVar(&hosts, "hosts", "localhost,127.0.0.1,::1", "comma-separated hosts of the server certificate")
+	flags.StringVar(&client, "client", "operator", "common name of the client certificate")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	ca, created, err := authority(path("ca.pem"), path("ca-key.pem"))
+	if err != nil {
+		return err
+	}
+
+	names := strings.Split(hosts, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+
+	srv, srvKey, err := ca.IssueServer(names, _CertificateValidity)
+	if err != nil {
+		return err
+	}
+	if err := write(path("cert.pem"), path("key.pem"), srv, srvKey); err != nil {
+		return err
+	}
+
+	cli, cliKey, err := ca.IssueClient(client, _CertificateValidity)
+	if err != nil {
+		return err
+	}
+	if err := write(path("client.pem"), path("client-key.pem"), cli, cliKey); err != nil {
+		return err
+	}
+
+	if created {
+		fmt.Printf("Certificate authority created at %s, install it as a trusted root to browse without warnings\n", path("ca.pem"))
+	} else {
+		fmt.Printf("Certificate authority reused from %s\n", path("ca.pem"))
+	}
+
+	fmt.Printf("Server certificate issued for %s\n", strings.Join(names, ", "))
+	fmt.Printf("Client certificate issued for %s\n\n", client)
+	fmt.Printf("Serve with -tls.cert %s -tls.key %s\n", path("cert.pem"), path("key.pem"))
+	fmt.Printf("and with -admin.client-ca %s to require the client certificate on the control plane\n", path("ca.pem"))
+
+	return nil
+}
+
+// authority loads the certificate authority from its files or, if
+// there are none, creates it.
+func authority(certFile, keyFile string) (*cert.Authority, bool, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		ca, err := cert.NewAuthority("Almodon Development CA", _AuthorityValidity)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return ca, true, write(certFile, keyFile, ca.Certificate, ca.Key)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ca, err := cert.ParseAuthority(certPEM, keyPEM)
+	return ca, false, err
+}
+
+func write(certFile, keyFile string, crt *x509.Certificate, key crypto.Signer) error {
+	keyPEM, err := cert.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		return err
+	}
+
+	return os.WriteFile(certFile, cert.EncodeCertificate(crt), 0o644)
+}
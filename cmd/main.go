@@ -1,176 +1,74 @@
 package main
 
 import (
-	"bytes"
-	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
-	"net"
-	"net/http"
 	"os"
-	"os/signal"
-	"strconv"
 	"strings"
-	"syscall"
-	"time"
 
-	"github.com/alan-b-lima/almodon/internal/api/v1"
-	"github.com/alan-b-lima/almodon/internal/middleware"
-
-	"github.com/alan-b-lima/ansi-escape-sequences"
+	"github.com/alan-b-lima/almodon/internal/config"
 )
 
-var StdOut = os.Stdout
-
-func main() {
-	log := middleware.NewLogger(StdOut, "")
-	style := Styles()
-
-	ln, err := net.Listen("tcp", ":4545")
-	if err != nil {
-		log.Println(err)
-		return
-	}
-
-	url := "http://" + strings.Replace(ln.Addr().String(), "[::]", "localhost", 1)
-	log.Printf("Server listening at %s\n", style.HyperLink(url))
-
-	var mux http.ServeMux
-
-	api, err := api.New()
-	if err != nil {
-		log.Println(err)
-		return
-	}
-	defer api.Close()
-
-	mux.Handle("/", http.FileServer(http.Dir("../ui/web/")))
-	mux.Handle("/api/", api)
-	mux.HandleFunc("/terminate/{timeout}", Terminate)
-
-	srv := http.Server{Handler: TrafficLogMiddleware(log, style, &mux)}
-
-	done := EnableGracefulShutdown(func() {
-		log.Println("Shutting server down...")
-		srv.Shutdown(context.Background())
-	})
-
-	if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
-		log.Println(err)
-	}
-
-	<-done
-}
-
-func TrafficLogMiddleware(log *middleware.Logger, s Style, handler http.Handler) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		rw := middleware.NewW(w)
-
-		handler.ServeHTTP(rw, r)
-
-		pen := s.StatusCodePen(rw.StatusCode())
-
-		var barr [32]byte
-		b := bytes.NewBuffer(barr[:0])
-		pen.Writer = b
-
-		io.WriteString(&pen, fmt.Sprintf(" %03d ", rw.StatusCode()))
-		log.Printf("%s %s %s %s\n", b.String(), r.RemoteAddr, r.Method, r.URL)
-	}
-}
-
-var Signals chan<- os.Signal
-
-func EnableGracefulShutdown(fn func()) <-chan struct{} {
-	signals := make(chan os.Signal, 1)
-	Signals = signals
-
-	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
-	done := make(chan struct{}, 1)
-
-	go func() {
-		<-signals
-		fn()
-		done <- struct{}{}
-	}()
-
-	return done
+type command struct {
+	run   func(name string, args []string) error
+	usage string
 }
 
-type Style struct {
-	HyperLink func(string) string
-
-	Pens    map[int]ansi.Pen
-	NoStyle ansi.Pen
-
-	Enabled bool
+var commands = map[string]command{
+	"serve":             {Serve, "runs the server, the default command"},
+	"user create":       {UserCreate, "creates a user, reading their password from stdin"},
+	"user set-password": {UserSetPassword, "sets the password of a user, reading it from stdin"},
+	"user set-role":     {UserSetRole, "sets the role of a user"},
+	"session purge":     {SessionPurge, "deletes expired sessions, or the session of a user"},
+	"cert dev":          {CertDev, "generates certificates for development, signed by a local authority"},
 }
 
-func Styles() (s Style) {
-	s.Enabled = ansi.EnableVirtualTerminal(os.Stdout.Fd()) == nil
-	s.NoStyle.SetStyle(false)
-
-	if s.Enabled {
-		var Success ansi.Pen
-		var Redirect ansi.Pen
-		var ClientError ansi.Pen
-		var ServerError ansi.Pen
-
-		Success.BGColor(ansi.RGBFromHex(0x0ed145))
-		Success.FGColor(ansi.RGBFromHex(0xffffff))
-
-		Redirect.BGColor(ansi.RGBFromHex(0x4b53cc))
-		Redirect.FGColor(ansi.RGBFromHex(0xffffff))
-
-		ClientError.BGColor(ansi.RGBFromHex(0xea1d1d))
-		ClientError.FGColor(ansi.RGBFromHex(0xffffff))
-
-		ServerError.BGColor(ansi.RGBFromHex(0x88001b))
-		ServerError.FGColor(ansi.RGBFromHex(0xffffff))
+var order = []string{"serve", "user create", "user set-password", "user set-role", "session purge", "cert dev"}
 
-		s.Pens = map[int]ansi.Pen{
-			2: Success,
-			3: Redirect,
-			4: ClientError,
-			5: ServerError,
+func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+
+		if len(args) > 0 {
+			if _, in := commands[name+" "+args[0]]; in {
+				name, args = name+" "+args[0], args[1:]
+			}
 		}
-		s.HyperLink = hyperlink
-
-		return s
 	}
 
-	s.HyperLink = func(s string) string { return s }
-	s.Pens = map[int]ansi.Pen{}
-
-	return s
-}
-
-func (s *Style) StatusCodePen(status int) ansi.Pen {
-	pen, in := s.Pens[status/100]
+	cmd, in := commands[name]
 	if !in {
-		return s.NoStyle
+		usage()
+		os.Exit(2)
 	}
 
-	return pen
+	err := cmd.run(name, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		os.Exit(1)
+	}
 }
 
-func hyperlink(link string) string {
-	var pen ansi.Pen
-	pen.FGColor(ansi.RGBFromHex(0x4e8597))
-
-	return pen.Sprint(ansi.HyperLinkP(link))
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags]\n\ncommands:\n", os.Args[0])
+	for _, name := range order {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nrun %s <command> -h for the flags of a command\n", os.Args[0])
 }
 
-func Terminate(w http.ResponseWriter, r *http.Request) {
-	ms, err := strconv.Atoi(r.PathValue("timeout"))
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+// load loads the configuration out of the arguments of the command,
+// along with its own flags, defined by define, if not nil.
+func load(name string, args []string, define func(fs *flag.FlagSet)) (config.Config, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	if define != nil {
+		define(fs)
 	}
 
-	go func() {
-		time.Sleep(time.Duration(ms) * time.Millisecond)
-		Signals <- syscall.SIGTERM
-	}()
-
-	w.WriteHeader(http.StatusNoContent)
+	return config.Load(fs, args, os.LookupEnv)
 }
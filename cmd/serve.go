@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/api/v1"
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/config"
+	"github.com/alan-b-lima/almodon/internal/control"
+	"github.com/alan-b-lima/almodon/internal/domain/session"
+	"github.com/alan-b-lima/almodon/internal/health"
+	"github.com/alan-b-lima/almodon/internal/logging"
+	"github.com/alan-b-lima/almodon/internal/middleware"
+	"github.com/alan-b-lima/almodon/internal/storage"
+	"github.com/alan-b-lima/almodon/internal/support/resource"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/cert"
+	"github.com/alan-b-lima/almodon/pkg/metrics"
+)
+
+var StdOut = os.Stdout
+
+// Serve runs the server until it is signaled to stop, either by
+// SIGINT or SIGTERM, or through the control plane. SIGHUP reloads the
+// configuration.
+//
+// Once stopping, the server reports not being ready, at /readyz, for
+// shutdown.drain while still listening, then waits for the requests in
+// flight up to shutdown.timeout.
+func Serve(name string, args []string) (err error) {
+	cfg, err := load(name, args, nil)
+	if err != nil {
+		return err
+	}
+
+	level := new(slog.LevelVar)
+	level.Set(cfg.Log.Level)
+
+	log := logging.New(StdOut, cfg.Log.Format, level)
+	errorLog := slog.NewLogLogger(log.Handler(), slog.LevelWarn)
+
+	var certs *cert.Reloader
+	if cfg.TLS.Cert != "" {
+		certs, err = cert.NewReloader(cfg.TLS.Cert, cfg.TLS.Key)
+		if err != nil {
+			return err
+		}
+		certs.ErrorLog = errorLog
+	} else if cfg.Cookies.Secure {
+		log.Warn("Cookies are secure, but served over plain HTTP, browsers only keep them for localhost")
+	}
+
+	if cfg.Mail.Addr == "" {
+		log.Warn("No mail server configured, e-mails are kept pending in the outbox until one is")
+	}
+
+	repos, err := storage.Open(cfg.Storage)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, repos.Close()) }()
+
+	registry := metrics.NewRegistry()
+	instrument(registry, repos)
+
+	srv := server{
+		name:     name,
+		args:     args,
+		cfg:      cfg,
+		repos:    repos,
+		certs:    certs,
+		level:    level,
+		registry: registry,
+		stop:     make(chan struct{}),
+	}
+
+	app, err := newApp(cfg, repos, registry)
+	if err != nil {
+		return err
+	}
+	srv.app.Store(app)
+	defer func() { err = errors.Join(err, srv.app.Load().Close()) }()
+
+	ln, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		return err
+	}
+
+	scheme := "http://"
+	if certs != nil {
+		ln = tls.NewListener(ln, srv.tlsConfig())
+		scheme = "https://"
+	}
+
+	url := scheme + strings.Replace(ln.Addr().String(), "[::]", "localhost", 1)
+	log.Info("Server listening", "url", url)
+
+	probes := health.New(map[string]health.Check{
+		"storage": repos.Ping,
+	}, log)
+
+	// probes are kept out of the access logs and metrics, as they
+	// happen every few seconds
+	var public http.ServeMux
+	public.Handle("GET /healthz", probes)
+	public.Handle("GET /readyz", probes)
+	public.Handle("/", middleware.RequestID(middleware.AccessLog(log, middleware.Metrics(registry, &srv))))
+
+	servers := []*http.Server{{
+		Handler:  &public,
+		ErrorLog: errorLog,
+	}}
+	listeners := []net.Listener{ln}
+
+	if cfg.Admin.Listen != "" {
+		aln, handler, err := srv.control(cfg, log)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+
+		log.Info("Control plane listening", "address", aln.Addr().String())
+
+		servers = append(servers, &http.Server{
+			Handler:  middleware.RequestID(middleware.AccessLog(log, handler)),
+			ErrorLog: errorLog,
+		})
+		listeners = append(listeners, aln)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	errs := make(chan error, len(servers))
+	for i, s := range servers {
+		go func() {
+			if err := s.Serve(listeners[i]); err != http.ErrServerClosed {
+				errs <- err
+			}
+		}()
+	}
+
+loop:
+	for {
+		select {
+		case sig := <-signals:
+			if sig != syscall.SIGHUP {
+				break loop
+			}
+
+			if err := srv.Reload(); err != nil {
+				log.Error("Failed to reload the configuration", "error", err)
+			} else {
+				log.Info("Configuration reloaded")
+			}
+
+		case <-srv.stop:
+			break loop
+
+		case err = <-errs:
+			break loop
+		}
+	}
+
+	drain, timeout := srv.timeouts()
+	log.Info("Draining server", "drain", drain)
+	probes.Drain(drain)
+
+	log.Info("Shutting server down", "timeout", timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, s := range servers {
+		serr := s.Shutdown(ctx)
+		if errors.Is(serr, context.DeadlineExceeded) {
+			log.Warn("Requests still in flight were cut off")
+
+			// the listeners are closed by then, only the connections
+			// are left to close
+			s.Close()
+			continue
+		}
+
+		err = errors.Join(err, serr)
+	}
+
+	return err
+}
+
+// server serves the application, which is replaced as a whole when
+// the configuration is reloaded.
+type server struct {
+	name string
+	args []string
+
+	mu    sync.Mutex
+	cfg   config.Config
+	repos *storage.Repositories
+	certs *cert.Reloader
+	level *slog.LevelVar
+	app   atomic.Pointer[app]
+
+	registry *metrics.Registry
+
+	stop chan struct{}
+	once sync.Once
+}
+
+type app struct {
+	http.ServeMux
+	api *api.Handler
+}
+
+func newApp(cfg config.Config, repos *storage.Repositories, registry *metrics.Registry) (*app, error) {
+	api, err := api.New(cfg, repos, registry)
+	if err != nil {
+		return nil, err
+	}
+
+	a := app{api: api}
+	a.Handle("/", resource.Routed(http.FileServer(http.Dir(cfg.Static))))
+	a.Handle("/api/", api)
+
+	return &a, nil
+}
+
+func (a *app) Close() error {
+	return a.api.Close()
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.app.Load().ServeHTTP(w, r)
+}
+
+// timeout returns how long requests in flight are waited for on
+// shutdown, as last configured.
+func (s *server) timeouts() (drain, timeout time.Duration) {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+
+	return time.Duration(s.cfg.Shutdown.Drain), time.Duration(s.cfg.Shutdown.Timeout)
+}
+
+func (s *server) Shutdown() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+// Reload reloads the configuration, from the same arguments, file and
+// environment the server was started with, and replaces the
+// application. Requests already being served finish with the previous
+// one. The certificate and the log level are reloaded as well, though
+// settings bound to listeners, logs or storage are not.
+func (s *server) Reload() error {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+
+	cfg, err := load(s.name, s.args, nil)
+	if err != nil {
+		return xerrors.ErrConfigReload.New(err)
+	}
+
+	switch {
+	case cfg.Listen != s.cfg.Listen:
+		return xerrors.ErrRestartRequired.New("listen")
+	case cfg.Log.Format != s.cfg.Log.Format:
+		return xerrors.ErrRestartRequired.New("log.format")
+	case cfg.TLS != s.cfg.TLS:
+		return xerrors.ErrRestartRequired.New("tls")
+	case cfg.Admin != s.cfg.Admin:
+		return xerrors.ErrRestartRequired.New("admin")
+	case cfg.Storage != s.cfg.Storage:
+		return xerrors.ErrRestartRequired.New("storage")
+	}
+
+	if s.certs != nil {
+		if err := s.certs.Reload(); err != nil {
+			return xerrors.ErrConfigReload.New(err)
+		}
+	}
+
+	app, err := newApp(cfg, s.repos, s.registry)
+	if err != nil {
+		return xerrors.ErrConfigReload.New(err)
+	}
+
+	s.cfg = cfg
+	s.level.Set(cfg.Log.Level)
+
+	return s.app.Swap(app).Close()
+}
+
+// instrument registers the metrics of the server that outlive the
+// application, which registers its own.
+//
+// Stock levels are not measured: products hold no stock, and no
+// inventory domain exists to read it from. Their gauges are deferred
+// until one does.
+func instrument(registry *metrics.Registry, repos *storage.Repositories) {
+	registry.GaugeFunc("almodon_sessions_active", "Sessions that have not expired.", func() float64 {
+		count, err := session.Count(repos.Sessions)
+		if err != nil {
+			return math.NaN()
+		}
+
+		return float64(count)
+	})
+
+	registry.GaugeFunc("go_goroutines", "Goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+}
+
+func (s *server) tlsConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: s.certs.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+}
+
+// control listens for the control plane. Unix sockets are restricted
+// to the owner of the process, and listeners requiring client
+// certificates to their holders, who are trusted, whereas other
+// listeners are restricted to chiefs, as are the routes of the API.
+func (s *server) control(cfg config.Config, log *slog.Logger) (net.Listener, http.Handler, error) {
+	policy := api.Policy()
+
+	if path, ok := strings.CutPrefix(cfg.Admin.Listen, "unix:"); ok {
+		// a socket left behind by a server that was not shut down
+		// gracefully would fail the listening
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, err
+		}
+
+		ln, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := os.Chmod(path, 0o600); err != nil {
+			ln.Close()
+			return nil, nil, err
+		}
+
+		return ln, control.New(s, nil, policy, s.repos.Audit, s.registry, log), nil
+	}
+
+	var tlsConfig *tls.Config
+	if s.certs != nil {
+		tlsConfig = s.tlsConfig()
+	}
+
+	if cfg.Admin.ClientCA != "" {
+		pem, err := os.ReadFile(cfg.Admin.ClientCA)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("%s: no certificates found", cfg.Admin.ClientCA)
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	ln, err := net.Listen("tcp", cfg.Admin.Listen)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+
+	var identify control.Identifier
+	if cfg.Admin.ClientCA == "" {
+		identify = func(r *http.Request) (auth.Actor, error) {
+			return resource.Session(s.app.Load().api.Gatekeeper(), r)
+		}
+	}
+
+	cookies := resource.CookiePolicy{
+		Secure:   cfg.Cookies.Secure,
+		Domain:   cfg.Cookies.Domain,
+		SameSite: http.SameSite(cfg.Cookies.SameSite),
+	}
+	csrf := middleware.NewCSRF(cookies)
+
+	return ln, csrf.Handler(control.New(s, identify, policy, s.repos.Audit, s.registry, log)), nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/alan-b-lima/almodon/internal/domain/session"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+)
+
+func SessionPurge(name string, args []string) (err error) {
+	var siape int
+
+	cfg, err := load(name, args, func(fs *flag.FlagSet) {
+		fs.IntVar(&siape, "user", 0, "SIAPE of the user whose session is deleted, instead of the expired ones")
+	})
+	if err != nil {
+		return err
+	}
+
+	repos, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, repos.Close()) }()
+
+	if siape != 0 {
+		res, err := user.GetBySIAPE(repos.Users, siape)
+		if err != nil {
+			return err
+		}
+
+		return session.Revoke(repos.Sessions, res.UUID)
+	}
+
+	count, err := session.Purge(repos.Sessions)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d expired sessions deleted\n", count)
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/config"
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/internal/domain/session"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/storage"
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"golang.org/x/term"
+)
+
+// operator is the actor of the commands, which are run from the
+// machine itself and are not tied to any user.
+var operator = auth.NewUnlogged().WithAddress("cli")
+
+func UserCreate(name string, args []string) (err error) {
+	var (
+		siape int
+		uname string
+		email string
+		role  string
+	)
+
+	cfg, err := load(name, args, func(fs *flag.FlagSet) {
+		fs.IntVar(&siape, "siape", 0, "SIAPE of the user")
+		fs.StringVar(&uname, "name", "", "name of the user")
+		fs.StringVar(&email, "email", "", "e-mail of the user")
+		fs.StringVar(&role, "role", "user", `role of the user, "chief", "promoted-admin", "admin" or "user"`)
+	})
+	if err != nil {
+		return err
+	}
+
+	r, ok := auth.FromString(role)
+	if !ok {
+		return fmt.Errorf("unknown role %q", role)
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	repos, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, repos.Close()) }()
+
+	uuid, err := user.Create(repos.Users, siape, uname, email, password, r)
+	if err != nil {
+		return err
+	}
+
+	res, err := user.Get(repos.Users, uuid)
+	if err != nil {
+		return err
+	}
+
+	if err := audit.Record(repos.Audit, operator, "users:create", uuid, nil, state(&res)); err != nil {
+		return err
+	}
+
+	fmt.Println(uuid)
+	return nil
+}
+
+func UserSetPassword(name string, args []string) (err error) {
+	var siape int
+
+	cfg, err := load(name, args, func(fs *flag.FlagSet) {
+		fs.IntVar(&siape, "siape", 0, "SIAPE of the user")
+	})
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	repos, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, repos.Close()) }()
+
+	res, err := user.GetBySIAPE(repos.Users, siape)
+	if err != nil {
+		return err
+	}
+
+	var none opt.Opt[string]
+	if err := user.Patch(repos.Users, res.UUID, entity.Match(res.Version), none, none, opt.Some(password), opt.None[auth.Role]()); err != nil {
+		return err
+	}
+
+	// whoever knew the old password is logged out
+	if err := session.Revoke(repos.Sessions, res.UUID); err != nil {
+		return err
+	}
+
+	return audit.Record(repos.Audit, operator, "users:update-password", res.UUID, nil, nil)
+}
+
+func UserSetRole(name string, args []string) (err error) {
+	var (
+		siape int
+		role  string
+	)
+
+	cfg, err := load(name, args, func(fs *flag.FlagSet) {
+		fs.IntVar(&siape, "siape", 0, "SIAPE of the user")
+		fs.StringVar(&role, "role", "", `role of the user, "chief", "promoted-admin", "admin" or "user"`)
+	})
+	if err != nil {
+		return err
+	}
+
+	r, ok := auth.FromString(role)
+	if !ok {
+		return fmt.Errorf("unknown role %q", role)
+	}
+
+	repos, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, repos.Close()) }()
+
+	before, err := user.GetBySIAPE(repos.Users, siape)
+	if err != nil {
+		return err
+	}
+
+	var none opt.Opt[string]
+	if err := user.Patch(repos.Users, before.UUID, entity.Match(before.Version), none, none, none, opt.Some(r)); err != nil {
+		return err
+	}
+
+	after, err := user.Get(repos.Users, before.UUID)
+	if err != nil {
+		return err
+	}
+
+	return audit.Record(repos.Audit, operator, "users:update-role", before.UUID, state(&before), state(&after))
+}
+
+// open opens the configured repositories, which are written back when
+// closed, so the server must not be running meanwhile.
+func open(cfg config.Config) (*storage.Repositories, error) {
+	if cfg.Storage.Backend == "memory" {
+		return nil, errors.New("the memory backend keeps nothing between runs, set storage.backend to json")
+	}
+
+	repos, err := storage.Open(cfg.Storage)
+	if errors.Is(err, storage.ErrLocked) {
+		return nil, fmt.Errorf("%s is in use, stop the server before running this command", cfg.Storage.Path)
+	}
+
+	return repos, err
+}
+
+// readPassword reads the password out of the first line of stdin. If
+// stdin is a terminal, it prompts for the password and reads it
+// without echoing it.
+func readPassword() (string, error) {
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Password: ")
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+
+		return string(password), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", errors.New("password must be given through stdin")
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// state is how users are recorded in the audit log, which must not
+// hold their passwords.
+func state(e *user.Entity) user.Response {
+	return user.Response{
+		UUID:  e.UUID,
+		SIAPE: e.SIAPE,
+		Name:  e.Name,
+		Email: e.Email,
+		Role:  e.Role.String(),
+	}
+}
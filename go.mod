@@ -5,6 +5,7 @@ go 1.25.1
 require (
 	github.com/alan-b-lima/ansi-escape-sequences v1.1.0
 	golang.org/x/crypto v0.43.0
+	golang.org/x/term v0.36.0
 )
 
 require golang.org/x/sys v0.37.0 // indirect
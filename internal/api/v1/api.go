@@ -1,59 +1,142 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
-
-	promotionrepo "github.com/alan-b-lima/almodon/internal/domain/promotion/repository"
-	sessionrepo "github.com/alan-b-lima/almodon/internal/domain/session/repository"
-	userrepo "github.com/alan-b-lima/almodon/internal/domain/user/repository"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/config"
+	audits "github.com/alan-b-lima/almodon/internal/domain/audit/resource"
+	auditserve "github.com/alan-b-lima/almodon/internal/domain/audit/service"
+	outboxserve "github.com/alan-b-lima/almodon/internal/domain/outbox/service"
+	products "github.com/alan-b-lima/almodon/internal/domain/product/resource"
+	productserve "github.com/alan-b-lima/almodon/internal/domain/product/service"
+	tokens "github.com/alan-b-lima/almodon/internal/domain/token/resource"
+	tokenserve "github.com/alan-b-lima/almodon/internal/domain/token/service"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	userauth "github.com/alan-b-lima/almodon/internal/domain/user/authenticator"
 	users "github.com/alan-b-lima/almodon/internal/domain/user/resource"
 	userserve "github.com/alan-b-lima/almodon/internal/domain/user/service"
+	"github.com/alan-b-lima/almodon/internal/middleware"
+	"github.com/alan-b-lima/almodon/internal/storage"
+	"github.com/alan-b-lima/almodon/internal/support/resource"
+	"github.com/alan-b-lima/almodon/pkg/mail"
+	"github.com/alan-b-lima/almodon/pkg/metrics"
+	"github.com/alan-b-lima/almodon/pkg/oidc"
 )
 
 type Handler struct {
 	http.ServeMux
-	cleanup []closer
+	gatekeeper user.Gatekeeper
+	cleanup    []closer
 }
 
-func New() (*Handler, error) {
+// New creates the API over the repositories, which are not closed
+// along with it, so that many APIs may be created over them, as in
+// reloading the configuration. Its metrics are kept in the registry.
+func New(cfg config.Config, repos *storage.Repositories, registry *metrics.Registry) (*Handler, error) {
 	var r Handler
 
-	var (
-		repoPromotions = promotionrepo.NewMap()
-		repoSessions   = sessionrepo.NewMap()
-		repoUsers      = userrepo.NewMap()
-	)
+	spec, docs, err := specification()
+	if err != nil {
+		return nil, err
+	}
+
+	authn, err := authenticator(cfg.LDAP, repos.Users)
+	if err != nil {
+		return nil, err
+	}
+
+	sso, err := identityProvider(cfg.OIDC)
+	if err != nil {
+		return nil, err
+	}
+
+	cookies := resource.CookiePolicy{
+		Secure:   cfg.Cookies.Secure,
+		Domain:   cfg.Cookies.Domain,
+		SameSite: http.SameSite(cfg.Cookies.SameSite),
+	}
+
+	serveAudit := auditserve.NewService(repos.Audit)
+	serveMessages := outboxserve.NewService(repos.Messages, sender(cfg.Mail), cfg.Mail.From)
 
-	serveUsers := userserve.NewService(repoUsers, repoSessions, repoPromotions)
+	serveUsers := userserve.NewService(repos.Users, repos.Sessions, cfg.Lifetimes(), repos.Promotions, repos.TwoFactors, repos.Tokens, authn, sso, serveMessages)
+	serveTokens := tokenserve.NewService(repos.Tokens)
+	serveProducts := productserve.NewService(repos.Products)
 
-	authServeUsers := userserve.New(serveUsers)
+	instrumentedServeUsers := userserve.NewInstrumented(serveUsers, registry)
 
-	users := users.New(authServeUsers)
+	auditedServeUsers := userserve.NewAudited(instrumentedServeUsers, serveAudit)
+	auditedServeTokens := tokenserve.NewAudited(serveTokens, repos.Tokens, serveAudit)
+	auditedServeProducts := productserve.NewAudited(serveProducts, serveAudit)
+
+	policy := Policy()
+
+	authServeAudit := auditserve.New(serveAudit, policy)
+	authServeUsers := userserve.New(auditedServeUsers, policy)
+	authServeTokens := tokenserve.New(auditedServeTokens, policy)
+	authServeProducts := productserve.New(auditedServeProducts, policy)
+
+	audits := audits.New(authServeAudit, authServeUsers)
+	users := users.New(authServeUsers, cookies)
+	tokens := tokens.New(authServeTokens, authServeUsers)
+	products := products.New(authServeProducts, authServeUsers)
 
 	resources := map[string]http.Handler{
-		"users": users,
+		"audit":    audits,
+		"products": products,
+		"tokens":   tokens,
+		"users":    users,
 	}
 
+	csrf := middleware.NewCSRF(cookies)
+	idempotency := middleware.NewIdempotency(authServeUsers, time.Duration(cfg.Idempotency.Window))
+
 	for name, handler := range resources {
-		r.Handle("/api/v1/"+name+"/", http.StripPrefix("/api/v1", handler))
+		r.Handle("/api/v1/"+name+"/", csrf.Handler(idempotency.Handler(http.StripPrefix("/api/v1", handler))))
 	}
 
-	r.attach(repoPromotions)
-	r.attach(repoSessions)
-	r.attach(repoUsers)
+	r.Handle("GET /api/v1/csrf/{$}", resource.Routed(http.HandlerFunc(csrf.Token)))
+	r.Handle("GET /api/v1/openapi.json", resource.Routed(spec))
+	r.Handle("GET /api/v1/docs/{$}", resource.Routed(docs))
+
+	r.gatekeeper = authServeUsers
+
+	r.attach(serveAudit)
+	r.attach(serveMessages)
 	r.attach(serveUsers)
+	r.attach(serveTokens)
+	r.attach(serveProducts)
+	r.attach(instrumentedServeUsers)
+	r.attach(auditedServeUsers)
+	r.attach(auditedServeTokens)
+	r.attach(auditedServeProducts)
+	r.attach(authServeAudit)
 	r.attach(authServeUsers)
+	r.attach(authServeTokens)
+	r.attach(authServeProducts)
+	r.attach(audits)
 	r.attach(users)
+	r.attach(tokens)
+	r.attach(products)
 
 	return &r, nil
 }
 
+// Gatekeeper resolves the actors of requests to the API.
+func (h *Handler) Gatekeeper() user.Gatekeeper {
+	return h.gatekeeper
+}
+
 func (h *Handler) Close() error {
 	errs := make([]error, 0, len(h.cleanup))
 
-	for _, closer := range h.cleanup {
-		errs = append(errs, closer.Close())
+	// closes in reverse order of attachment, so that resources are
+	// closed before the services they depend on
+	for i := len(h.cleanup) - 1; i >= 0; i-- {
+		errs = append(errs, h.cleanup[i].Close())
 	}
 
 	return errors.Join(errs...)
@@ -70,3 +153,64 @@ func (h *Handler) attach(a any) bool {
 	h.cleanup = append(h.cleanup, closer)
 	return true
 }
+
+// authenticator authenticates users against the directory, if one is
+// configured, falling back to their local passwords.
+func authenticator(cfg config.LDAP, users user.GetterBySIAPE) (user.Authenticator, error) {
+	local := userauth.NewLocal(users)
+	if cfg.Addr == "" {
+		return local, nil
+	}
+
+	ldap, err := userauth.NewLDAP(userauth.LDAPConfig{
+		Addr:         cfg.Addr,
+		StartTLS:     cfg.StartTLS,
+		CAFile:       cfg.CAFile,
+		BindDN:       cfg.BindDN,
+		BindPassword: cfg.BindPassword,
+		BaseDN:       cfg.BaseDN,
+		Filter:       cfg.Filter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return userauth.NewChain(ldap, local), nil
+}
+
+const _DiscoveryTimeout = 10 * time.Second
+
+func identityProvider(cfg config.OIDC) (user.IdentityProvider, error) {
+	if cfg.Issuer == "" {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), _DiscoveryTimeout)
+	defer cancel()
+
+	rp, err := oidc.New(ctx, oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       []string{"profile", "email"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return userauth.NewOIDC(rp, userauth.OIDCConfig{SIAPEClaim: cfg.SIAPEClaim}), nil
+}
+
+func sender(cfg config.Mail) mail.Sender {
+	if cfg.Addr == "" {
+		return nil
+	}
+
+	return &mail.SMTP{
+		Addr:       cfg.Addr,
+		Username:   cfg.Username,
+		Password:   cfg.Password,
+		RequireTLS: cfg.RequireTLS,
+	}
+}
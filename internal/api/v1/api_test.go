@@ -0,0 +1,109 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/internal/api/v1"
+	"github.com/alan-b-lima/almodon/internal/config"
+	audits "github.com/alan-b-lima/almodon/internal/domain/audit/resource"
+	products "github.com/alan-b-lima/almodon/internal/domain/product/resource"
+	tokens "github.com/alan-b-lima/almodon/internal/domain/token/resource"
+	users "github.com/alan-b-lima/almodon/internal/domain/user/resource"
+	"github.com/alan-b-lima/almodon/internal/storage"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/metrics"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+func TestRoutes(t *testing.T) {
+	cfg := config.Default()
+
+	repos, err := storage.Open(cfg.Storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repos.Close()
+
+	handler, err := New(cfg, repos, metrics.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	wildcards := strings.NewReplacer("{$}", "", "{uuid}", uuid.NewUUIDv7().String(), "{siape}", "123456")
+
+	routes := slices.Concat(audits.Routes, products.Routes, tokens.Routes, users.Routes)
+	for _, route := range routes {
+		method, pattern, _ := strings.Cut(route.Pattern, " ")
+
+		// a bearer token lets unsafe requests past the CSRF check, to be
+		// refused, if at all, by the handler of the route
+		r := httptest.NewRequest(method, "/api/v1"+wildcards.Replace(pattern), nil)
+		r.Header.Set("Authorization", "Bearer almpat_token")
+		r.Header.Set("Accept", "application/json")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		var res errors.Error
+		json.Unmarshal(w.Body.Bytes(), &res)
+
+		if w.Code == http.StatusMethodNotAllowed || res.Title == "resource-not-found" {
+			t.Errorf("%s: %s: expected the route to be handled, got %d: %s", route.ID, route.Pattern, w.Code, w.Body)
+		}
+	}
+}
+
+func TestVersionRequired(t *testing.T) {
+	cfg := config.Default()
+
+	repos, err := storage.Open(cfg.Storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repos.Close()
+
+	handler, err := New(cfg, repos, metrics.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	target := "/api/v1/users/" + uuid.NewUUIDv7().String()
+
+	type Tests struct {
+		method, path string
+		ifMatch      string
+		status       int
+	}
+
+	tests := []Tests{
+		{http.MethodPatch, target, "", http.StatusPreconditionRequired},
+		{http.MethodPut, target + "/password/", "", http.StatusPreconditionRequired},
+		{http.MethodPut, target + "/role/", "", http.StatusPreconditionRequired},
+		{http.MethodDelete, target, "", http.StatusPreconditionRequired},
+		{http.MethodPut, target + "/password/", `"1"`, http.StatusUnauthorized},
+		{http.MethodPut, target + "/role/", `"1"`, http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, strings.NewReader("{}"))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", "application/json")
+		if test.ifMatch != "" {
+			r.Header.Set("If-Match", test.ifMatch)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("%s %s: If-Match %s: expected %d, got %d: %s", test.method, test.path, test.ifMatch, test.status, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	audits "github.com/alan-b-lima/almodon/internal/domain/audit/resource"
+	products "github.com/alan-b-lima/almodon/internal/domain/product/resource"
+	tokens "github.com/alan-b-lima/almodon/internal/domain/token/resource"
+	users "github.com/alan-b-lima/almodon/internal/domain/user/resource"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/openapi"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// Specification describes the API as an OpenAPI document, its paths
+// are relative to /api/v1.
+func Specification() *openapi.Document {
+	doc := openapi.New(openapi.Info{Title: "Almodon", Version: "1"}, openapi.Server{URL: "/api/v1"})
+
+	doc.Define(reflect.TypeFor[uuid.UUID](), &openapi.Schema{Type: openapi.Types{"string"}, Format: "uuid"})
+
+	kinds := make([]any, 0)
+	for _, kind := range errors.Kinds() {
+		kinds = append(kinds, kind.String())
+	}
+
+	// mirrors the marshaling of errors.Error, whose cause is either
+	// another error, a plain message or many of those, when joined,
+	// errors about fields of the request are located by a pointer
+	cause := &openapi.Schema{OneOf: []*openapi.Schema{openapi.Ref("Error"), {Type: openapi.Types{"string"}}}}
+	ref := doc.Component("Error", &openapi.Schema{
+		Type: openapi.Types{"object"},
+		Properties: map[string]*openapi.Schema{
+			"kind":    {Type: openapi.Types{"string"}, Enum: kinds},
+			"title":   {Type: openapi.Types{"string"}},
+			"message": {Type: openapi.Types{"string"}},
+			"cause":   {OneOf: append(cause.OneOf, &openapi.Schema{Type: openapi.Types{"array"}, Items: cause})},
+			"pointer": {Type: openapi.Types{"string"}, Format: "json-pointer", Description: "the field of the request the error is about, if any"},
+		},
+		Required: []string{"kind", "title", "message"},
+	})
+	doc.Define(reflect.TypeFor[errors.Error](), ref)
+
+	doc.Fallback(&openapi.Response{
+		Description: "Error",
+		Content:     map[string]openapi.MediaType{openapi.JSON: {Schema: ref}},
+	})
+
+	doc.Add(users.Routes...)
+	doc.Add(tokens.Routes...)
+	doc.Add(products.Routes...)
+	doc.Add(audits.Routes...)
+	doc.Add(openapi.Route{
+		Pattern: "GET /csrf/{$}",
+		ID:      "csrfToken",
+		Summary: "Gets the CSRF token, setting its cookie",
+		Tags:    []string{"csrf"},
+		Response: struct {
+			Token string `json:"token"`
+		}{},
+	})
+
+	return doc
+}
+
+//go:embed docs/index.html
+var docsPage []byte
+
+// specification serves the document and a page rendering it.
+func specification() (spec, docs http.HandlerFunc, err error) {
+	buf, err := json.Marshal(Specification())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	spec = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(buf)
+	}
+
+	docs = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(docsPage)
+	}
+
+	return spec, docs, nil
+}
@@ -0,0 +1,42 @@
+package api
+
+import "github.com/alan-b-lima/almodon/internal/auth"
+
+// Policy declares who is allowed to perform each of the actions of
+// the API. Actions not declared here are denied to everyone.
+func Policy() *auth.Policy {
+	var (
+		self        = auth.IsSubject()
+		logged      = auth.Logged()
+		staff       = auth.HasRole(auth.Admin)
+		chief       = auth.HasRole(auth.Chief)
+		chiefOrSelf = auth.Any(chief, self)
+	)
+
+	return auth.NewPolicy().
+		Allow("audit:list", chief).
+		Allow("control:shutdown", chief).
+		Allow("control:reload", chief).
+		Allow("control:stats", chief).
+		Allow("control:pprof", chief).
+		Allow("control:metrics", chief).
+		Allow("users:list", chief).
+		Allow("users:get", chiefOrSelf).
+		Allow("users:create", chief).
+		Allow("users:patch", chiefOrSelf).
+		Allow("users:update-password", chiefOrSelf).
+		Allow("users:update-role", chief).
+		Allow("users:delete", chiefOrSelf).
+		Allow("users:enroll-two-factor", self).
+		Allow("users:confirm-two-factor", self).
+		Allow("users:disable-two-factor", chiefOrSelf).
+		Allow("tokens:list", self).
+		Allow("tokens:create", self).
+		Allow("tokens:delete", self).
+		Allow("products:list", logged).
+		Allow("products:search", logged).
+		Allow("products:get", logged).
+		Allow("products:create", staff).
+		Allow("products:patch", staff).
+		Allow("products:delete", staff)
+}
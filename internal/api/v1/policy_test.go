@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/internal/api/v1"
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+func TestPolicy(t *testing.T) {
+	var (
+		chief = auth.NewLogged(uuid.NewUUIDv7(), auth.Chief)
+		admin = auth.NewLogged(uuid.NewUUIDv7(), auth.Admin)
+		user  = auth.NewLogged(uuid.NewUUIDv7(), auth.User)
+		guest = auth.NewUnlogged()
+	)
+
+	var (
+		self  = auth.Resource{Subject: user.User()}
+		other = auth.Resource{Subject: chief.User()}
+		none  = auth.Resource{}
+	)
+
+	type Tests struct {
+		act     auth.Actor
+		action  string
+		rc      auth.Resource
+		allowed bool
+	}
+
+	tests := []Tests{
+		{chief, "audit:list", none, true},
+		{user, "audit:list", none, false},
+
+		{chief, "control:shutdown", none, true},
+		{user, "control:shutdown", none, false},
+		{guest, "control:reload", none, false},
+		{user, "control:pprof", self, false},
+		{chief, "control:metrics", none, true},
+
+		{chief, "users:list", none, true},
+		{user, "users:list", none, false},
+		{guest, "users:list", none, false},
+
+		{chief, "users:get", self, true},
+		{user, "users:get", self, true},
+		{user, "users:get", other, false},
+		{guest, "users:get", self, false},
+
+		{chief, "users:create", none, true},
+		{user, "users:create", none, false},
+
+		{user, "users:patch", self, true},
+		{user, "users:patch", other, false},
+		{user, "users:update-password", self, true},
+		{user, "users:update-password", other, false},
+
+		{chief, "users:update-role", self, true},
+		{user, "users:update-role", self, false},
+
+		{chief, "users:delete", self, true},
+		{user, "users:delete", self, true},
+		{user, "users:delete", other, false},
+
+		{user, "users:enroll-two-factor", self, true},
+		{chief, "users:enroll-two-factor", self, false},
+		{user, "users:confirm-two-factor", self, true},
+		{chief, "users:confirm-two-factor", self, false},
+		{user, "users:disable-two-factor", self, true},
+		{chief, "users:disable-two-factor", self, true},
+		{user, "users:disable-two-factor", other, false},
+
+		{user, "tokens:list", self, true},
+		{user, "tokens:create", self, true},
+		{user, "tokens:delete", self, true},
+		{guest, "tokens:list", none, false},
+
+		{user, "products:list", none, true},
+		{user, "products:search", none, true},
+		{guest, "products:search", none, false},
+		{user, "products:get", none, true},
+		{admin, "products:create", none, true},
+		{chief, "products:patch", none, true},
+		{user, "products:create", none, false},
+		{user, "products:delete", none, false},
+	}
+
+	policy := Policy()
+	for _, test := range tests {
+		if policy.Authorize(test.act, test.action, test.rc) != test.allowed {
+			if test.allowed {
+				t.Errorf("%v should be allowed to %s", test.act.Role(), test.action)
+			} else {
+				t.Errorf("%v should not be allowed to %s", test.act.Role(), test.action)
+			}
+		}
+	}
+}
@@ -1,13 +1,20 @@
 package auth
 
-import "github.com/alan-b-lima/almodon/pkg/uuid"
+import (
+	"time"
+
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
 
 // Actor is a system entity that can access services through it's
 // role. It is related the user entity, as a Actor is a shrinked
 // version of an user.
 type Actor struct {
-	user uuid.UUID
-	role Role
+	user    uuid.UUID
+	role    Role
+	scopes  []Scope
+	address string
+	expires time.Time
 }
 
 // NewLogged creates a new actor. This function does not check
@@ -19,6 +26,21 @@ func NewLogged(user uuid.UUID, role Role) Actor {
 	}
 }
 
+// NewScoped creates a new actor restricted to the given scopes, as
+// the ones acting through personal access tokens. This function does
+// not check whether the fact is real.
+func NewScoped(user uuid.UUID, role Role, scopes []Scope) Actor {
+	if scopes == nil {
+		scopes = []Scope{}
+	}
+
+	return Actor{
+		user:   user,
+		role:   role,
+		scopes: scopes,
+	}
+}
+
 // NewUnlogged creates a new unlogged actor. It's also equivalent to
 // the zero value of [Actor].
 func NewUnlogged() Actor {
@@ -34,3 +56,51 @@ func (act *Actor) User() uuid.UUID {
 func (act *Actor) Role() Role {
 	return act.role
 }
+
+// Address returns the network address the actor acts from, empty if
+// unknown.
+func (act *Actor) Address() string {
+	return act.address
+}
+
+// WithAddress returns a copy of the actor acting from the given
+// network address.
+func (act Actor) WithAddress(address string) Actor {
+	act.address = address
+	return act
+}
+
+// Expires returns when the credential the actor acts through expires,
+// false if it does not.
+func (act *Actor) Expires() (time.Time, bool) {
+	return act.expires, !act.expires.IsZero()
+}
+
+// WithExpiry returns a copy of the actor whose credential expires at
+// the given time.
+func (act Actor) WithExpiry(expires time.Time) Actor {
+	act.expires = expires
+	return act
+}
+
+// Scopes returns the scopes the actor is restricted to, nil if the
+// actor is not restricted at all.
+func (act *Actor) Scopes() []Scope {
+	return act.scopes
+}
+
+// InScope returns whether the action is allowed by the scopes of the
+// actor, actors without scopes are allowed any action.
+func (act *Actor) InScope(action string) bool {
+	if act.scopes == nil {
+		return true
+	}
+
+	for _, scope := range act.scopes {
+		if scope.Covers(action) {
+			return true
+		}
+	}
+
+	return false
+}
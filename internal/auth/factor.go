@@ -0,0 +1,21 @@
+package auth
+
+// MultiFactor is the permission that determines which roles can only
+// be exercised by actors authenticated with a second factor.
+var MultiFactor = Permit(Promoted)
+
+// RequiresMultiFactor returns whether the role can only be exercised
+// by actors authenticated with a second factor.
+func RequiresMultiFactor(role Role) bool {
+	return role.IsValid() && MultiFactor.Authorize(role)
+}
+
+// SingleFactorRole returns the strongest role, not stronger than the
+// given one, that does not require a second factor.
+func SingleFactorRole(role Role) Role {
+	for RequiresMultiFactor(role) {
+		role++
+	}
+
+	return role
+}
@@ -0,0 +1,120 @@
+package auth
+
+import "github.com/alan-b-lima/almodon/pkg/uuid"
+
+// Resource is what an action is performed on, as far as policies are
+// concerned.
+type Resource struct {
+	// Subject is the user the resource is, or belongs to, if any.
+	Subject uuid.UUID
+
+	// Attributes are further facts about the resource that custom
+	// conditions may inspect.
+	Attributes map[string]string
+}
+
+// Condition is a requirement over an actor and a resource for an
+// action to be allowed.
+type Condition func(act *Actor, rc *Resource) bool
+
+// Policy is a set of rules that allow actions. An action is allowed
+// if all the conditions of any of its rules hold, actions without
+// rules are never allowed.
+type Policy struct {
+	rules map[string][][]Condition
+}
+
+// NewPolicy creates a policy that allows nothing.
+func NewPolicy() *Policy {
+	return &Policy{rules: make(map[string][][]Condition)}
+}
+
+// Allow adds a rule that allows the action if all of the conditions
+// hold. A rule without conditions allows the action to anyone,
+// including unlogged actors. It returns the policy, so that rules can
+// be chained.
+func (p *Policy) Allow(action string, conditions ...Condition) *Policy {
+	p.rules[action] = append(p.rules[action], conditions)
+	return p
+}
+
+// Actions returns the actions that have rules in the policy.
+func (p *Policy) Actions() []string {
+	actions := make([]string, 0, len(p.rules))
+	for action := range p.rules {
+		actions = append(actions, action)
+	}
+
+	return actions
+}
+
+// Authorize returns whether the actor is allowed to perform the
+// action on the resource, which requires the action to be within
+// the scopes of the actor as well.
+func (p *Policy) Authorize(act Actor, action string, rc Resource) bool {
+	if !act.InScope(action) {
+		return false
+	}
+
+	for _, rule := range p.rules[action] {
+		if all(rule, &act, &rc) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Logged holds for any logged actor.
+func Logged() Condition {
+	return func(act *Actor, rc *Resource) bool {
+		return act.Role().IsValid()
+	}
+}
+
+// HasRole holds for actors with any of the roles, or stronger ones,
+// according to the default hierarchy.
+func HasRole(roles ...Role) Condition {
+	perm := Permit(roles...)
+	return func(act *Actor, rc *Resource) bool {
+		return perm.Authorize(act.Role())
+	}
+}
+
+// IsSubject holds for logged actors that are the subject of the
+// resource.
+func IsSubject() Condition {
+	return func(act *Actor, rc *Resource) bool {
+		return act.Role().IsValid() && !rc.Subject.IsNil() && act.User() == rc.Subject
+	}
+}
+
+// Any holds if any of the conditions holds.
+func Any(conditions ...Condition) Condition {
+	return func(act *Actor, rc *Resource) bool {
+		for _, cond := range conditions {
+			if cond(act, rc) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// All holds if all of the conditions hold.
+func All(conditions ...Condition) Condition {
+	return func(act *Actor, rc *Resource) bool {
+		return all(conditions, act, rc)
+	}
+}
+
+func all(conditions []Condition, act *Actor, rc *Resource) bool {
+	for _, cond := range conditions {
+		if !cond(act, rc) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,84 @@
+package auth_test
+
+import (
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+func TestPolicyAuthorize(t *testing.T) {
+	var (
+		chief = NewLogged(uuid.NewUUIDv7(), Chief)
+		admin = NewLogged(uuid.NewUUIDv7(), Admin)
+		user  = NewLogged(uuid.NewUUIDv7(), User)
+		token = NewScoped(chief.User(), Chief, []Scope{"requests:get"})
+		guest = NewUnlogged()
+	)
+
+	// ownClinic holds for actors whose clinic matches the one of the
+	// resource, which shows custom conditions over attributes
+	clinics := map[uuid.UUID]string{admin.User(): "north"}
+	ownClinic := func(act *Actor, rc *Resource) bool {
+		clinic, ok := clinics[act.User()]
+		return ok && clinic == rc.Attributes["clinic"]
+	}
+
+	policy := NewPolicy().
+		Allow("requests:get").
+		Allow("requests:create", Logged()).
+		Allow("requests:approve", HasRole(Chief)).
+		Allow("requests:approve", HasRole(Admin), ownClinic).
+		Allow("requests:delete", Any(HasRole(Chief), IsSubject())).
+		Allow("requests:cancel", All(HasRole(Admin), IsSubject()))
+
+	var (
+		north = Resource{Subject: user.User(), Attributes: map[string]string{"clinic": "north"}}
+		south = Resource{Subject: user.User(), Attributes: map[string]string{"clinic": "south"}}
+		mine  = Resource{Subject: admin.User()}
+		none  = Resource{}
+	)
+
+	type Tests struct {
+		act     Actor
+		action  string
+		rc      Resource
+		allowed bool
+	}
+
+	tests := []Tests{
+		{guest, "requests:get", none, true},
+		{guest, "requests:create", none, false},
+		{user, "requests:create", none, true},
+
+		{chief, "requests:approve", south, true},
+		{admin, "requests:approve", north, true},
+		{admin, "requests:approve", south, false},
+		{user, "requests:approve", north, false},
+
+		{chief, "requests:delete", mine, true},
+		{admin, "requests:delete", mine, true},
+		{user, "requests:delete", mine, false},
+		{user, "requests:delete", north, true},
+		{guest, "requests:delete", none, false},
+
+		{admin, "requests:cancel", mine, true},
+		{chief, "requests:cancel", mine, false},
+		{user, "requests:cancel", north, false},
+
+		{token, "requests:get", none, true},
+		{token, "requests:approve", north, false},
+
+		{chief, "requests:undeclared", none, false},
+	}
+
+	for _, test := range tests {
+		if policy.Authorize(test.act, test.action, test.rc) != test.allowed {
+			if test.allowed {
+				t.Errorf("%v should be allowed to %s", test.act.Role(), test.action)
+			} else {
+				t.Errorf("%v should not be allowed to %s", test.act.Role(), test.action)
+			}
+		}
+	}
+}
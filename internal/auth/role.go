@@ -69,6 +69,20 @@ func DefaultHierarchy(r0, r1 Role) bool {
 	return r0 >= r1
 }
 
+// Weaker returns the weaker of two roles according to the hierarchy,
+// that is, the one whose permissions are inherited by the other. If
+// neither inherits the other, Unlogged is returned.
+func Weaker(hierarchy Hierarchy, r0, r1 Role) Role {
+	switch {
+	case hierarchy(r0, r1):
+		return r0
+	case hierarchy(r1, r0):
+		return r1
+	}
+
+	return Unlogged
+}
+
 // FromString returns the Role corresponding to the given string. If
 // the string does not correspond to any Role, the second return
 // value is false.
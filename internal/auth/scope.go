@@ -0,0 +1,62 @@
+package auth
+
+import "strings"
+
+// Scope restricts the actions an actor can perform. A scope is
+// either an action, such as "users:get", a wildcard over the actions
+// of a resource, such as "users:*", or a wildcard over every action,
+// "*".
+type Scope string
+
+// Everything is the scope that covers every action.
+const Everything Scope = "*"
+
+// ParseScope returns the Scope corresponding to the given string. If
+// the string is not a well-formed scope, the second return value is
+// false.
+func ParseScope(str string) (Scope, bool) {
+	if Scope(str) == Everything {
+		return Everything, true
+	}
+
+	resource, action, ok := strings.Cut(str, ":")
+	if !ok || !isName(resource) || action != "*" && !isName(action) {
+		return "", false
+	}
+
+	return Scope(str), true
+}
+
+// Covers returns whether the scope covers the given action, or every
+// action covered by the given scope, if it is a scope itself.
+func (s Scope) Covers(action string) bool {
+	if s == Everything {
+		return true
+	}
+
+	if resource, ok := strings.CutSuffix(string(s), ":*"); ok {
+		prefix, _, _ := strings.Cut(action, ":")
+		return prefix == resource
+	}
+
+	return string(s) == action
+}
+
+// String returns the string representation of the Scope.
+func (s Scope) String() string {
+	return string(s)
+}
+
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '-') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,100 @@
+package auth_test
+
+import (
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+func TestParseScope(t *testing.T) {
+	type Tests struct {
+		scope string
+		valid bool
+	}
+
+	tests := []Tests{
+		{"*", true},
+		{"users:*", true},
+		{"users:get", true},
+		{"users:update-role", true},
+
+		{"", false},
+		{"users", false},
+		{"users:", false},
+		{":get", false},
+		{"*:get", false},
+		{"Users:get", false},
+		{"users:get:all", false},
+	}
+
+	for _, test := range tests {
+		if _, ok := ParseScope(test.scope); ok != test.valid {
+			t.Errorf("validity of %q should be %v", test.scope, test.valid)
+		}
+	}
+}
+
+func TestScopeCovers(t *testing.T) {
+	type Tests struct {
+		scope  Scope
+		action string
+		covers bool
+	}
+
+	tests := []Tests{
+		{Everything, "users:get", true},
+		{Everything, "*", true},
+
+		{"users:*", "users:get", true},
+		{"users:*", "users:*", true},
+		{"users:*", "tokens:get", false},
+		{"users:*", "*", false},
+
+		{"users:get", "users:get", true},
+		{"users:get", "users:list", false},
+		{"users:get", "users:*", false},
+	}
+
+	for _, test := range tests {
+		if test.scope.Covers(test.action) != test.covers {
+			t.Errorf("%q covering %q should be %v", test.scope, test.action, test.covers)
+		}
+	}
+}
+
+func TestActorInScope(t *testing.T) {
+	unscoped := NewLogged(uuid.NewUUIDv7(), User)
+	if !unscoped.InScope("users:delete") {
+		t.Errorf("actor without scopes should be allowed any action")
+	}
+
+	empty := NewScoped(uuid.NewUUIDv7(), User, nil)
+	if empty.InScope("users:get") {
+		t.Errorf("actor with empty scopes should not be allowed any action")
+	}
+
+	scoped := NewScoped(uuid.NewUUIDv7(), User, []Scope{"users:get", "tokens:*"})
+	if !scoped.InScope("users:get") || !scoped.InScope("tokens:delete") || scoped.InScope("users:delete") {
+		t.Errorf("actor should be allowed exactly the actions covered by its scopes")
+	}
+}
+
+func TestWeaker(t *testing.T) {
+	type Tests struct {
+		x, y, weaker Role
+	}
+
+	tests := []Tests{
+		{Chief, Admin, Admin},
+		{Admin, Chief, Admin},
+		{User, User, User},
+		{Promoted, Unlogged, Unlogged},
+	}
+
+	for _, test := range tests {
+		if role := Weaker(DefaultHierarchy, test.x, test.y); role != test.weaker {
+			t.Errorf("weaker of %v and %v should be %v, got %v", test.x, test.y, test.weaker, role)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+// Package config loads the settings of the server. Settings are read,
+// in increasing order of precedence, from their defaults, a JSON file,
+// environment variables and command-line flags.
+//
+// Every setting has a dotted name, such as "session.max-age", which is
+// its flag, and from which its environment variable is derived, as in
+// ALMODON_SESSION_MAX_AGE. In the file, settings are nested objects
+// with snake-cased keys, as in {"session": {"max_age": "10m"}}.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/domain/promotion"
+	"github.com/alan-b-lima/almodon/internal/domain/session"
+)
+
+// EnvPrefix prefixes the environment variables of every setting.
+const EnvPrefix = "ALMODON_"
+
+// DefaultIdempotencyWindow is how long responses are kept for retries,
+// unless configured otherwise.
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+type Config struct {
+	Listen string `json:"listen"`
+	Static string `json:"static"`
+	TLS    TLS    `json:"tls"`
+	Admin  Admin  `json:"admin"`
+	Log    Log    `json:"log"`
+
+	Shutdown Shutdown `json:"shutdown"`
+
+	Storage   Storage   `json:"storage"`
+	Cookies   Cookies   `json:"cookies"`
+	Session   Session   `json:"session"`
+	Promotion Promotion `json:"promotion"`
+
+	Idempotency Idempotency `json:"idempotency"`
+
+	Mail Mail `json:"mail"`
+	LDAP LDAP `json:"ldap"`
+	OIDC OIDC `json:"oidc"`
+}
+
+type (
+	// TLS is the certificate the server is served with, in PEM files,
+	// which are reloaded as they change. The server is served over
+	// plain HTTP if Cert is empty.
+	TLS struct {
+		Cert string `json:"cert"`
+		Key  string `json:"key"`
+	}
+
+	// Admin is the listener of the control plane, either an address,
+	// as host:port, or a Unix socket, as in "unix:/run/almodon.sock".
+	// The control plane is disabled if Listen is empty.
+	Admin struct {
+		Listen string `json:"listen"`
+
+		// ClientCA is the authority client certificates are verified
+		// against, in a PEM file. If set, only clients presenting one
+		// are accepted, and they are trusted as operators.
+		ClientCA string `json:"client_ca"`
+	}
+
+	// Log is how the server logs, Format is either "text", colored on
+	// terminals, or "json".
+	Log struct {
+		Format string     `json:"format"`
+		Level  slog.Level `json:"level"`
+	}
+
+	// Shutdown is how the server shuts down, Drain is how long it keeps
+	// listening, while reporting not being ready, so that it is taken
+	// out of rotation before refusing connections. Timeout bounds how
+	// long requests in flight are waited for before being cut off.
+	Shutdown struct {
+		Drain   Duration `json:"drain"`
+		Timeout Duration `json:"timeout"`
+	}
+
+	Storage struct {
+		// Backend is either "memory", which loses everything on
+		// shutdown, or "json", which keeps records in files under Path.
+		Backend string `json:"backend"`
+		Path    string `json:"path"`
+	}
+
+	Cookies struct {
+		Secure   bool     `json:"secure"`
+		Domain   string   `json:"domain"`
+		SameSite SameSite `json:"same_site"`
+	}
+
+	Session struct {
+		MaxAge        Duration `json:"max_age"`
+		PendingMaxAge Duration `json:"pending_max_age"`
+	}
+
+	Promotion struct {
+		MaxAge Duration `json:"max_age"`
+	}
+
+	// Idempotency is how long responses to requests carrying an
+	// Idempotency-Key are kept, to be replayed to their retries.
+	Idempotency struct {
+		Window Duration `json:"window"`
+	}
+
+	// Mail is the SMTP server notifications are sent through, they
+	// are kept pending if Addr is empty.
+	Mail struct {
+		Addr       string `json:"addr"`
+		Username   string `json:"username"`
+		Password   string `json:"password"`
+		From       string `json:"from"`
+		RequireTLS bool   `json:"require_tls"`
+	}
+
+	// LDAP is the directory users are authenticated against, before
+	// their local passwords, it is disabled if Addr is empty.
+	LDAP struct {
+		Addr         string `json:"addr"`
+		StartTLS     bool   `json:"start_tls"`
+		CAFile       string `json:"ca_file"`
+		BindDN       string `json:"bind_dn"`
+		BindPassword string `json:"bind_password"`
+		BaseDN       string `json:"base_dn"`
+		Filter       string `json:"filter"`
+	}
+
+	// OIDC is the OpenID Connect provider used for single sign-on, it
+	// is disabled if Issuer is empty.
+	OIDC struct {
+		Issuer       string `json:"issuer"`
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		RedirectURL  string `json:"redirect_url"`
+		SIAPEClaim   string `json:"siape_claim"`
+	}
+)
+
+// Default returns the configuration used for settings that are not
+// given anywhere.
+func Default() Config {
+	return Config{
+		Listen: ":4545",
+		Static: "../ui/web/",
+		Log: Log{
+			Format: "text",
+			Level:  slog.LevelInfo,
+		},
+		Shutdown: Shutdown{
+			Drain:   Duration(5 * time.Second),
+			Timeout: Duration(30 * time.Second),
+		},
+		Storage: Storage{
+			Backend: "memory",
+			Path:    "data",
+		},
+		Cookies: Cookies{
+			Secure:   true,
+			SameSite: SameSite(http.SameSiteLaxMode),
+		},
+		Session: Session{
+			MaxAge:        Duration(session.DefaultLifetimes.MaxAge),
+			PendingMaxAge: Duration(session.DefaultLifetimes.PendingMaxAge),
+		},
+		Promotion: Promotion{
+			MaxAge: Duration(promotion.DefaultMaxAge),
+		},
+		Idempotency: Idempotency{
+			Window: Duration(DefaultIdempotencyWindow),
+		},
+	}
+}
+
+// Load loads the configuration out of the command-line arguments and
+// the environment, as given by lookup, such as [os.LookupEnv]. The
+// flags of the settings are defined in fs, which may define further
+// flags of its own. The file is taken from the -config flag, or else
+// from ALMODON_CONFIG, and is optional.
+func Load(fs *flag.FlagSet, args []string, lookup func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+
+	path := fs.String("config", "", "path of the JSON configuration file")
+
+	settings := flag.NewFlagSet("", flag.ContinueOnError)
+	cfg.Bind(settings)
+	settings.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+
+	// flags are parsed twice, first to find the file, which they then
+	// override, along with the environment
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if *path == "" {
+		*path, _ = lookup(EnvPrefix + "CONFIG")
+	}
+
+	if *path != "" {
+		if err := cfg.load(*path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var errs []error
+	settings.VisitAll(func(f *flag.Flag) {
+		if val, ok := lookup(Env(f.Name)); ok {
+			if err := f.Value.Set(val); err != nil {
+				errs = append(errs, fmt.Errorf("config: %s: %w", Env(f.Name), err))
+			}
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// Env returns the environment variable of the setting of the given
+// name.
+func Env(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
+// Bind defines a flag for every setting in the flag set, defaulting
+// to and storing into the configuration.
+func (c *Config) Bind(fs *flag.FlagSet) {
+	fs.StringVar(&c.Listen, "listen", c.Listen, "address the server listens at")
+	fs.StringVar(&c.Static, "static", c.Static, "directory of the static files of the web interface")
+	fs.StringVar(&c.TLS.Cert, "tls.cert", c.TLS.Cert, "PEM file of the TLS certificate, serving over HTTPS")
+	fs.StringVar(&c.TLS.Key, "tls.key", c.TLS.Key, "PEM file of the key of the TLS certificate")
+
+	fs.StringVar(&c.Admin.Listen, "admin.listen", c.Admin.Listen, `address or "unix:" socket the control plane listens at`)
+	fs.StringVar(&c.Admin.ClientCA, "admin.client-ca", c.Admin.ClientCA, "PEM file of the authority of client certificates of the control plane")
+
+	fs.StringVar(&c.Log.Format, "log.format", c.Log.Format, `format of the logs, either "text" or "json"`)
+	fs.TextVar(&c.Log.Level, "log.level", c.Log.Level, `minimum level logged, "debug", "info", "warn" or "error"`)
+
+	fs.Var(&c.Shutdown.Drain, "shutdown.drain", "how long the server is reported not ready before it stops listening on shutdown")
+	fs.Var(&c.Shutdown.Timeout, "shutdown.timeout", "how long requests in flight are waited for on shutdown")
+
+	fs.StringVar(&c.Storage.Backend, "storage.backend", c.Storage.Backend, `where records are kept, either "memory" or "json"`)
+	fs.StringVar(&c.Storage.Path, "storage.path", c.Storage.Path, "directory of the records of the json backend")
+
+	fs.BoolVar(&c.Cookies.Secure, "cookies.secure", c.Cookies.Secure, "restrict cookies to HTTPS")
+	fs.StringVar(&c.Cookies.Domain, "cookies.domain", c.Cookies.Domain, "domain cookies are shared with")
+	fs.Var(&c.Cookies.SameSite, "cookies.same-site", `SameSite mode of cookies, "lax", "strict" or "none"`)
+
+	fs.Var(&c.Session.MaxAge, "session.max-age", "lifetime of sessions")
+	fs.Var(&c.Session.PendingMaxAge, "session.pending-max-age", "lifetime of sessions awaiting a second factor")
+	fs.Var(&c.Promotion.MaxAge, "promotion.max-age", "lifetime of promotions")
+	fs.Var(&c.Idempotency.Window, "idempotency.window", "how long responses are kept for retries with the same Idempotency-Key")
+
+	fs.StringVar(&c.Mail.Addr, "mail.addr", c.Mail.Addr, "address of the SMTP server, as host:port")
+	fs.StringVar(&c.Mail.Username, "mail.username", c.Mail.Username, "username of the SMTP server")
+	fs.StringVar(&c.Mail.Password, "mail.password", c.Mail.Password, "password of the SMTP server")
+	fs.StringVar(&c.Mail.From, "mail.from", c.Mail.From, "sender of notifications")
+	fs.BoolVar(&c.Mail.RequireTLS, "mail.require-tls", c.Mail.RequireTLS, "refuse SMTP servers without STARTTLS")
+
+	fs.StringVar(&c.LDAP.Addr, "ldap.addr", c.LDAP.Addr, "address of the directory, as host:port")
+	fs.BoolVar(&c.LDAP.StartTLS, "ldap.start-tls", c.LDAP.StartTLS, "secure the directory connection with StartTLS")
+	fs.StringVar(&c.LDAP.CAFile, "ldap.ca-file", c.LDAP.CAFile, "PEM file of the authorities trusted for the directory, rather than the system ones")
+	fs.StringVar(&c.LDAP.BindDN, "ldap.bind-dn", c.LDAP.BindDN, "DN of the service account of the directory")
+	fs.StringVar(&c.LDAP.BindPassword, "ldap.bind-password", c.LDAP.BindPassword, "password of the service account of the directory")
+	fs.StringVar(&c.LDAP.BaseDN, "ldap.base-dn", c.LDAP.BaseDN, "DN users are searched under")
+	fs.StringVar(&c.LDAP.Filter, "ldap.filter", c.LDAP.Filter, "filter users are searched with, containing {siape}")
+
+	fs.StringVar(&c.OIDC.Issuer, "oidc.issuer", c.OIDC.Issuer, "URL of the OpenID Connect provider")
+	fs.StringVar(&c.OIDC.ClientID, "oidc.client-id", c.OIDC.ClientID, "client ID at the provider")
+	fs.StringVar(&c.OIDC.ClientSecret, "oidc.client-secret", c.OIDC.ClientSecret, "client secret at the provider")
+	fs.StringVar(&c.OIDC.RedirectURL, "oidc.redirect-url", c.OIDC.RedirectURL, "URL of the single sign-on callback")
+	fs.StringVar(&c.OIDC.SIAPEClaim, "oidc.siape-claim", c.OIDC.SIAPEClaim, "claim of the ID token holding the SIAPE")
+}
+
+// Validate reports every setting that is invalid.
+func (c *Config) Validate() error {
+	var errs []error
+	report := func(format string, v ...any) {
+		errs = append(errs, fmt.Errorf("config: "+format, v...))
+	}
+
+	if c.Listen == "" {
+		report("listen must not be empty")
+	}
+
+	if (c.TLS.Cert == "") != (c.TLS.Key == "") {
+		report("tls.cert and tls.key must be given together")
+	}
+
+	if c.Admin.Listen == "unix:" {
+		report("admin.listen must name the path of the socket")
+	}
+
+	if c.Admin.ClientCA != "" {
+		if c.TLS.Cert == "" {
+			report("admin.client-ca requires tls.cert")
+		}
+
+		if c.Admin.Listen == "" || strings.HasPrefix(c.Admin.Listen, "unix:") {
+			report("admin.client-ca requires admin.listen to be an address")
+		}
+	}
+
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		report("log.format must be either \"text\" or \"json\", got %q", c.Log.Format)
+	}
+
+	if c.Shutdown.Drain < 0 {
+		report("shutdown.drain must not be negative")
+	}
+
+	if c.Shutdown.Timeout <= 0 {
+		report("shutdown.timeout must be positive")
+	}
+
+	switch c.Storage.Backend {
+	case "memory":
+	case "json":
+		if c.Storage.Path == "" {
+			report("storage.path must not be empty for the json backend")
+		}
+	default:
+		report("storage.backend must be either \"memory\" or \"json\", got %q", c.Storage.Backend)
+	}
+
+	if http.SameSite(c.Cookies.SameSite) == http.SameSiteNoneMode && !c.Cookies.Secure {
+		report("cookies.same-site \"none\" requires cookies.secure")
+	}
+
+	if err := c.Lifetimes().Validate(); err != nil {
+		report("session: %v", err)
+	}
+
+	if err := promotion.ValidateMaxAge(time.Duration(c.Promotion.MaxAge)); err != nil {
+		report("promotion.max-age: %v", err)
+	}
+
+	if c.Idempotency.Window <= 0 {
+		report("idempotency.window must be positive")
+	}
+
+	if c.Mail.Addr != "" && c.Mail.From == "" {
+		report("mail.from must not be empty if mail.addr is set")
+	}
+
+	if c.LDAP.Addr != "" && c.LDAP.BaseDN == "" {
+		report("ldap.base-dn must not be empty if ldap.addr is set")
+	}
+
+	if c.LDAP.CAFile != "" && !c.LDAP.StartTLS {
+		report("ldap.ca-file requires ldap.start-tls")
+	}
+
+	if c.OIDC.Issuer != "" && (c.OIDC.ClientID == "" || c.OIDC.RedirectURL == "") {
+		report("oidc.client-id and oidc.redirect-url must not be empty if oidc.issuer is set")
+	}
+
+	return errors.Join(errs...)
+}
+
+// Lifetimes returns the lifetimes of sessions.
+func (c *Config) Lifetimes() session.Lifetimes {
+	return session.Lifetimes{
+		MaxAge:        time.Duration(c.Session.MaxAge),
+		PendingMaxAge: time.Duration(c.Session.PendingMaxAge),
+	}
+}
+
+func (c *Config) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	return nil
+}
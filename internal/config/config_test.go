@@ -0,0 +1,138 @@
+package config_test
+
+import (
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/internal/config"
+)
+
+func load(args []string, vars map[string]string) (Config, error) {
+	fs := flag.NewFlagSet("almodon", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	return Load(fs, args, env(vars))
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		val, ok := vars[key]
+		return val, ok
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "almodon.json")
+	file := `{
+		"listen": ":8000",
+		"static": "/srv/almodon",
+		"session": {"max_age": "30m"},
+		"cookies": {"same_site": "strict"}
+	}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := map[string]string{
+		"ALMODON_CONFIG":            path,
+		"ALMODON_LISTEN":            ":9000",
+		"ALMODON_SESSION_MAX_AGE":   "1h",
+		"ALMODON_COOKIES_SECURE":    "false",
+		"ALMODON_STORAGE_BACKEND":   "json",
+		"ALMODON_PROMOTION_MAX_AGE": "2h",
+		"ALMODON_LOG_LEVEL":         "warn",
+	}
+
+	cfg, err := load([]string{"-listen", ":9999", "-storage.path", "/var/lib/almodon"}, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Tests struct {
+		setting  string
+		got, exp any
+	}
+
+	tests := []Tests{
+		{"listen", cfg.Listen, ":9999"},
+		{"static", cfg.Static, "/srv/almodon"},
+		{"session.max-age", time.Duration(cfg.Session.MaxAge), time.Hour},
+		{"session.pending-max-age", time.Duration(cfg.Session.PendingMaxAge), 5 * time.Minute},
+		{"promotion.max-age", time.Duration(cfg.Promotion.MaxAge), 2 * time.Hour},
+		{"cookies.same-site", http.SameSite(cfg.Cookies.SameSite), http.SameSiteStrictMode},
+		{"cookies.secure", cfg.Cookies.Secure, false},
+		{"storage.backend", cfg.Storage.Backend, "json"},
+		{"storage.path", cfg.Storage.Path, "/var/lib/almodon"},
+		{"log.level", cfg.Log.Level, slog.LevelWarn},
+	}
+
+	for _, test := range tests {
+		if test.got != test.exp {
+			t.Errorf("%s: expected %v, got %v", test.setting, test.exp, test.got)
+		}
+	}
+}
+
+func TestLoadValidation(t *testing.T) {
+	type Tests struct {
+		args  []string
+		vars  map[string]string
+		valid bool
+	}
+
+	tests := []Tests{
+		{nil, nil, true},
+		{[]string{"-storage.backend", "postgres"}, nil, false},
+		{[]string{"-storage.backend", "json", "-storage.path", ""}, nil, false},
+		{[]string{"-session.max-age", "0s"}, nil, false},
+		{[]string{"-session.max-age", "720h"}, nil, false},
+		{[]string{"-promotion.max-age", "-1h"}, nil, false},
+		{[]string{"-cookies.same-site", "none", "-cookies.secure=false"}, nil, false},
+		{[]string{"-cookies.same-site", "sometimes"}, nil, false},
+		{nil, map[string]string{"ALMODON_SESSION_MAX_AGE": "ten minutes"}, false},
+		{nil, map[string]string{"ALMODON_MAIL_ADDR": "smtp.ufvjm.edu.br:587"}, false},
+		{nil, map[string]string{"ALMODON_MAIL_ADDR": "smtp.ufvjm.edu.br:587", "ALMODON_MAIL_FROM": "almodon@ufvjm.edu.br"}, true},
+		{[]string{"-log.format", "json", "-log.level", "debug"}, nil, true},
+		{[]string{"-log.format", "xml"}, nil, false},
+		{[]string{"-shutdown.timeout", "0s"}, nil, false},
+		{[]string{"-shutdown.drain", "-1s"}, nil, false},
+		{[]string{"-shutdown.drain", "0s"}, nil, true},
+		{[]string{"-idempotency.window", "0s"}, nil, false},
+		{nil, map[string]string{"ALMODON_IDEMPOTENCY_WINDOW": "1h"}, true},
+		{[]string{"-log.level", "loud"}, nil, false},
+		{[]string{"-tls.cert", "cert.pem"}, nil, false},
+		{[]string{"-tls.cert", "cert.pem", "-tls.key", "key.pem"}, nil, true},
+		{[]string{"-admin.listen", "unix:"}, nil, false},
+		{[]string{"-admin.listen", ":4546", "-admin.client-ca", "ca.pem"}, nil, false},
+		{[]string{"-admin.listen", "unix:/run/almodon.sock", "-admin.client-ca", "ca.pem", "-tls.cert", "cert.pem", "-tls.key", "key.pem"}, nil, false},
+		{[]string{"-admin.listen", ":4546", "-admin.client-ca", "ca.pem", "-tls.cert", "cert.pem", "-tls.key", "key.pem"}, nil, true},
+		{[]string{"-ldap.addr", "ldap.ufvjm.edu.br:389"}, nil, false},
+		{[]string{"-ldap.addr", "ldap.ufvjm.edu.br:389", "-ldap.base-dn", "dc=ufvjm,dc=edu,dc=br", "-ldap.ca-file", "ca.pem"}, nil, false},
+		{[]string{"-ldap.addr", "ldap.ufvjm.edu.br:389", "-ldap.base-dn", "dc=ufvjm,dc=edu,dc=br", "-ldap.start-tls", "-ldap.ca-file", "ca.pem"}, nil, true},
+		{[]string{"-oidc.issuer", "https://sso.ufvjm.edu.br"}, nil, false},
+		{nil, map[string]string{"ALMODON_CONFIG": filepath.Join(t.TempDir(), "missing.json")}, false},
+	}
+
+	for _, test := range tests {
+		_, err := load(test.args, test.vars)
+		if (err == nil) != test.valid {
+			if test.valid {
+				t.Errorf("%v %v: did not expect error, but got: %v", test.args, test.vars, err)
+			} else {
+				t.Errorf("%v %v: expected error, but got none", test.args, test.vars)
+			}
+		}
+	}
+}
+
+func TestEnv(t *testing.T) {
+	if env := Env("session.pending-max-age"); env != "ALMODON_SESSION_PENDING_MAX_AGE" {
+		t.Errorf("expected ALMODON_SESSION_PENDING_MAX_AGE, got %s", env)
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Duration is a [time.Duration] written as in "10m" or "1h30m", both
+// in files and elsewhere.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d *Duration) Set(str string) error {
+	val, err := time.ParseDuration(str)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(val)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(buf []byte) error {
+	var str string
+	if err := json.Unmarshal(buf, &str); err != nil {
+		return err
+	}
+
+	return d.Set(str)
+}
+
+// SameSite is a [http.SameSite] written as "lax", "strict" or "none".
+type SameSite http.SameSite
+
+var sameSites = map[string]http.SameSite{
+	"lax":    http.SameSiteLaxMode,
+	"strict": http.SameSiteStrictMode,
+	"none":   http.SameSiteNoneMode,
+}
+
+func (s SameSite) String() string {
+	for str, mode := range sameSites {
+		if http.SameSite(s) == mode {
+			return str
+		}
+	}
+
+	return ""
+}
+
+func (s *SameSite) Set(str string) error {
+	mode, ok := sameSites[str]
+	if !ok {
+		return fmt.Errorf("must be \"lax\", \"strict\" or \"none\", got %q", str)
+	}
+
+	*s = SameSite(mode)
+	return nil
+}
+
+func (s SameSite) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *SameSite) UnmarshalJSON(buf []byte) error {
+	var str string
+	if err := json.Unmarshal(buf, &str); err != nil {
+		return err
+	}
+
+	return s.Set(str)
+}
@@ -0,0 +1,203 @@
+package control
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/internal/support/resource"
+	"github.com/alan-b-lima/almodon/internal/support/service"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/metrics"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// Operations are what the control plane acts upon, implemented by
+// the server.
+type Operations interface {
+	// Shutdown begins a graceful shutdown, without waiting for it.
+	Shutdown()
+
+	// Reload reloads the configuration, reporting why it could not be
+	// applied, if so.
+	Reload() error
+}
+
+// Identifier resolves the actor of a request to the control plane.
+type Identifier func(r *http.Request) (auth.Actor, error)
+
+type Handler struct {
+	http.ServeMux
+	ops      Operations
+	identify Identifier
+	policy   *auth.Policy
+	records  audit.Creater
+	registry *metrics.Registry
+	log      *slog.Logger
+	started  time.Time
+}
+
+// New creates the control plane. Requests are identified by identify
+// and authorized by the policy, every access is recorded in the audit
+// trail, and is refused if it cannot be. Refused attempts are logged
+// to log instead, as anyone reaching the listener could otherwise
+// grow the trail at will. A nil identify trusts every request as an
+// operator, for listeners whose access is already restricted, as Unix
+// sockets are by their file permissions, and TLS listeners by client
+// certificates.
+//
+// Metrics of the registry are exposed as well, whose scrapes are
+// authorized, though not recorded, as they happen every few seconds.
+func New(ops Operations, identify Identifier, policy *auth.Policy, records audit.Creater, registry *metrics.Registry, log *slog.Logger) *Handler {
+	h := Handler{
+		ops:      ops,
+		identify: identify,
+		policy:   policy,
+		records:  records,
+		registry: registry,
+		log:      log,
+		started:  time.Now(),
+	}
+
+	routes := map[string]struct {
+		action  string
+		handler http.HandlerFunc
+	}{
+		"POST /shutdown/{$}": {"control:shutdown", h.Shutdown},
+		"POST /reload/{$}":   {"control:reload", h.Reload},
+		"GET /stats/{$}":     {"control:stats", h.Stats},
+
+		"GET /debug/pprof/":        {"control:pprof", pprof.Index},
+		"GET /debug/pprof/cmdline": {"control:pprof", pprof.Cmdline},
+		"GET /debug/pprof/profile": {"control:pprof", pprof.Profile},
+		"GET /debug/pprof/symbol":  {"control:pprof", pprof.Symbol},
+		"GET /debug/pprof/trace":   {"control:pprof", pprof.Trace},
+	}
+
+	for route, r := range routes {
+		h.Handle(route, h.guard(r.action, r.handler, true))
+	}
+	h.Handle("GET /metrics", h.guard("control:metrics", registry.ServeHTTP, false))
+	h.HandleFunc("/", resource.NotFound)
+
+	return &h
+}
+
+func (h *Handler) guard(action string, handler http.HandlerFunc, recorded bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		act := operator(r)
+
+		if h.identify != nil {
+			var err error
+			act, err = h.identify(r)
+			if err != nil {
+				h.refuse(w, auth.NewUnlogged().WithAddress(resource.Address(r)), action, "unauthenticated", err)
+				return
+			}
+
+			if err := service.Authorize(h.policy, act, action, auth.Resource{}); err != nil {
+				h.refuse(w, act, action, "denied", err)
+				return
+			}
+		}
+
+		if recorded {
+			if err := audit.Record(h.records, act, action, uuid.UUID{}, nil, nil); err != nil {
+				h.log.Error("Failed to record a control plane access", "action", action, "error", err)
+				resource.WriteJsonError(w, err)
+				return
+			}
+		}
+
+		handler(w, r)
+	}
+}
+
+// refuse logs the refused attempt, even of unrecorded actions, and
+// writes the error.
+func (h *Handler) refuse(w http.ResponseWriter, act auth.Actor, action, outcome string, err error) {
+	reason := err.Error()
+	if err, ok := errors.AsType[*errors.Error](err); ok {
+		reason = err.Title
+	}
+
+	h.log.Warn("Control plane access refused", "action", action, "outcome", outcome, "reason", reason, "user", act.User(), "address", act.Address())
+	resource.WriteJsonError(w, err)
+}
+
+// operator returns the actor of a trusted request, whose address is
+// that of the socket, or, given a client certificate, its common name
+// and the network address.
+func operator(r *http.Request) auth.Actor {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		name := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		return auth.NewUnlogged().WithAddress(name + "@" + resource.Address(r))
+	}
+
+	return auth.NewUnlogged().WithAddress("unix")
+}
+
+func (h *Handler) Shutdown(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusAccepted)
+
+	// the response is flushed before the server stops accepting
+	// requests
+	http.NewResponseController(w).Flush()
+
+	h.ops.Shutdown()
+}
+
+func (h *Handler) Reload(w http.ResponseWriter, r *http.Request) {
+	if err := h.ops.Reload(); err != nil {
+		resource.WriteJsonError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type (
+	StatsResponse struct {
+		Started    time.Time      `json:"started"`
+		Uptime     string         `json:"uptime"`
+		Go         string         `json:"go"`
+		Goroutines int            `json:"goroutines"`
+		Memory     MemoryResponse `json:"memory"`
+	}
+
+	MemoryResponse struct {
+		Alloc       uint64 `json:"alloc"`
+		TotalAlloc  uint64 `json:"total_alloc"`
+		Sys         uint64 `json:"sys"`
+		HeapObjects uint64 `json:"heap_objects"`
+		NumGC       uint32 `json:"num_gc"`
+	}
+)
+
+func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	res := StatsResponse{
+		Started:    h.started,
+		Uptime:     time.Since(h.started).Round(time.Second).String(),
+		Go:         runtime.Version(),
+		Goroutines: runtime.NumGoroutine(),
+		Memory: MemoryResponse{
+			Alloc:       mem.Alloc,
+			TotalAlloc:  mem.TotalAlloc,
+			Sys:         mem.Sys,
+			HeapObjects: mem.HeapObjects,
+			NumGC:       mem.NumGC,
+		},
+	}
+
+	if err := resource.EncodeJSON(&res, http.StatusOK, w, r); err != nil {
+		resource.WriteJsonError(w, err)
+		return
+	}
+}
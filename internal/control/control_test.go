@@ -0,0 +1,103 @@
+package control_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alan-b-lima/almodon/internal/api/v1"
+	"github.com/alan-b-lima/almodon/internal/auth"
+	. "github.com/alan-b-lima/almodon/internal/control"
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	auditrepo "github.com/alan-b-lima/almodon/internal/domain/audit/repository"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/metrics"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type operations struct {
+	shutdown bool
+}
+
+func (o *operations) Shutdown()     { o.shutdown = true }
+func (o *operations) Reload() error { return nil }
+
+// failing fails to record anything.
+type failing struct{}
+
+func (failing) Create(audit.Entity) error { return errors.New("disk full") }
+
+func TestControl(t *testing.T) {
+	as := func(act auth.Actor) Identifier {
+		return func(r *http.Request) (auth.Actor, error) { return act, nil }
+	}
+
+	unidentified := func(r *http.Request) (auth.Actor, error) { return auth.Actor{}, xerrors.ErrTokenInvalid }
+
+	type Tests struct {
+		identify Identifier
+		status   int
+		shutdown bool
+		recorded int
+		outcome  string
+	}
+
+	tests := []Tests{
+		{nil, http.StatusAccepted, true, 1, ""},
+		{as(auth.NewLogged(uuid.NewUUIDv7(), auth.Chief)), http.StatusAccepted, true, 1, ""},
+		{as(auth.NewLogged(uuid.NewUUIDv7(), auth.User)), http.StatusForbidden, false, 0, "denied"},
+		{as(auth.NewUnlogged()), http.StatusUnauthorized, false, 0, "denied"},
+		{unidentified, http.StatusUnauthorized, false, 0, "unauthenticated"},
+	}
+
+	for _, test := range tests {
+		var ops operations
+		var logs bytes.Buffer
+		records := auditrepo.NewMap()
+		h := New(&ops, test.identify, api.Policy(), records, metrics.NewRegistry(), slog.New(slog.NewTextHandler(&logs, nil)))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/shutdown/", nil))
+
+		if w.Code != test.status {
+			t.Errorf("expected status %d, got %d", test.status, w.Code)
+		}
+
+		if ops.shutdown != test.shutdown {
+			t.Errorf("expected shutdown to be %t", test.shutdown)
+		}
+
+		res, err := audit.List(records, audit.Filter{}, listing.Query{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.TotalRecords != test.recorded {
+			t.Errorf("expected %d records, got %d", test.recorded, res.TotalRecords)
+		}
+
+		if test.outcome != "" && !bytes.Contains(logs.Bytes(), []byte("outcome="+test.outcome)) {
+			t.Errorf("expected the refusal to be logged as %s, got %q", test.outcome, logs.String())
+		}
+	}
+}
+
+func TestControlUnrecorded(t *testing.T) {
+	var ops operations
+	h := New(&ops, nil, api.Policy(), failing{}, metrics.NewRegistry(), slog.New(slog.DiscardHandler))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/shutdown/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	if ops.shutdown {
+		t.Error("expected an access that could not be recorded not to shut down")
+	}
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// Recorder records actions performed on resources. Before and after
+// are the states of the target around the action, nil if it did not
+// exist, and are compared field by field.
+type Recorder interface {
+	Record(act auth.Actor, action string, target uuid.UUID, before, after any) error
+}
+
+func List(repo Lister, filter Filter, q listing.Query) (Entities, error) {
+	return repo.List(filter, q)
+}
+
+// Record records that the actor performed the action on the target.
+// The resource of the record is taken from the action, e.g. "users"
+// for "users:patch".
+func Record(repo Creater, act auth.Actor, action string, target uuid.UUID, before, after any) error {
+	changes, err := Diff(before, after)
+	if err != nil {
+		return xerrors.ErrAuditRecord.New(err)
+	}
+
+	resource, _, _ := strings.Cut(action, ":")
+
+	e := Entity{
+		UUID:     uuid.NewUUIDv7(),
+		Actor:    act.User(),
+		Role:     act.Role(),
+		Action:   action,
+		Resource: resource,
+		Target:   target,
+		Address:  act.Address(),
+		Time:     time.Now(),
+		Changes:  changes,
+	}
+
+	return repo.Create(e)
+}
+
+// Diff compares the JSON representations of before and after, which
+// must be objects or nil, and returns the fields that differ, sorted
+// by name.
+func Diff(before, after any) ([]Change, error) {
+	b, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for field, value := range b {
+		if other, in := a[field]; !in || !bytes.Equal(value, other) {
+			changes = append(changes, Change{Field: field, Before: value, After: a[field]})
+		}
+	}
+
+	for field, value := range a {
+		if _, in := b[field]; !in {
+			changes = append(changes, Change{Field: field, After: value})
+		}
+	}
+
+	slices.SortFunc(changes, func(c0, c1 Change) int {
+		return strings.Compare(c0.Field, c1.Field)
+	})
+
+	return changes, nil
+}
+
+func fields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
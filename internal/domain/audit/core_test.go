@@ -0,0 +1,117 @@
+package audit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	. "github.com/alan-b-lima/almodon/internal/domain/audit"
+	auditrepo "github.com/alan-b-lima/almodon/internal/domain/audit/repository"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+func TestDiff(t *testing.T) {
+	type User struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+
+	type Tests struct {
+		before, after any
+		fields        []string
+	}
+
+	alan := User{Name: "Alan", Email: "alan@ufvjm.edu.br"}
+	renamed := User{Name: "Alan Lima", Email: "alan@ufvjm.edu.br"}
+
+	tests := []Tests{
+		{alan, alan, nil},
+		{alan, renamed, []string{"name"}},
+		{nil, alan, []string{"email", "name"}},
+		{alan, nil, []string{"email", "name"}},
+		{nil, nil, nil},
+	}
+
+	for _, test := range tests {
+		changes, err := Diff(test.before, test.after)
+		if err != nil {
+			t.Errorf("did not expect error, but got: %v", err)
+			continue
+		}
+
+		if len(changes) != len(test.fields) {
+			t.Errorf("expected %d changes, got %d: %v", len(test.fields), len(changes), changes)
+			continue
+		}
+
+		for i, change := range changes {
+			if change.Field != test.fields[i] {
+				t.Errorf("expected change on %q, got on %q", test.fields[i], change.Field)
+			}
+		}
+	}
+}
+
+func TestRecordAndList(t *testing.T) {
+	repo := auditrepo.NewMap()
+
+	chief := auth.NewLogged(uuid.NewUUIDv7(), auth.Chief).WithAddress("10.0.0.1")
+	user := auth.NewLogged(uuid.NewUUIDv7(), auth.User)
+	target := uuid.NewUUIDv7()
+
+	start := time.Now()
+
+	records := []struct {
+		act    auth.Actor
+		action string
+	}{
+		{chief, "users:create"},
+		{chief, "users:patch"},
+		{user, "tokens:create"},
+	}
+
+	for _, record := range records {
+		if err := Record(repo, record.act, record.action, target, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type Tests struct {
+		filter  Filter
+		actions []string
+	}
+
+	tests := []Tests{
+		{Filter{}, []string{"tokens:create", "users:patch", "users:create"}},
+		{Filter{Actor: opt.Some(chief.User())}, []string{"users:patch", "users:create"}},
+		{Filter{Resource: opt.Some("tokens")}, []string{"tokens:create"}},
+		{Filter{Target: opt.Some(uuid.NewUUIDv7())}, nil},
+		{Filter{Since: opt.Some(start)}, []string{"tokens:create", "users:patch", "users:create"}},
+		{Filter{Until: opt.Some(start)}, nil},
+	}
+
+	for _, test := range tests {
+		res, err := List(repo, test.filter, listing.Query{Sort: Recent, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.TotalRecords != len(test.actions) {
+			t.Errorf("expected %d records, got %d", len(test.actions), res.TotalRecords)
+			continue
+		}
+
+		for i, record := range res.Records {
+			if record.Action != test.actions[i] {
+				t.Errorf("expected action %q, got %q", test.actions[i], record.Action)
+			}
+		}
+	}
+
+	res, _ := List(repo, Filter{Actor: opt.Some(chief.User())}, listing.Query{Sort: Recent, Limit: 1})
+	if record := res.Records[0]; record.Address != "10.0.0.1" || record.Role != auth.Chief || record.Resource != "users" {
+		t.Errorf("unexpected record: %+v", record)
+	}
+}
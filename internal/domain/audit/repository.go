@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Repository interface {
+	Lister
+	Creater
+}
+
+type (
+	Lister interface {
+		List(filter Filter, q listing.Query) (Entities, error)
+	}
+
+	Creater interface {
+		Create(Entity) error
+	}
+)
+
+type (
+	Filter struct {
+		Actor    opt.Opt[uuid.UUID]
+		Resource opt.Opt[string]
+		Target   opt.Opt[uuid.UUID]
+		Since    opt.Opt[time.Time]
+		Until    opt.Opt[time.Time]
+	}
+
+	Entities = listing.Page[Entity]
+
+	Entity struct {
+		UUID     uuid.UUID
+		Actor    uuid.UUID
+		Role     auth.Role
+		Action   string
+		Resource string
+		Target   uuid.UUID
+		Address  string
+		Time     time.Time
+		Changes  []Change
+	}
+
+	Change struct {
+		Field  string
+		Before json.RawMessage
+		After  json.RawMessage
+	}
+)
+
+// Listing describes the fields records may be sorted by.
+var Listing = listing.Schema[Entity]{
+	ID: func(e *Entity) uuid.UUID { return e.UUID },
+	Fields: map[string]func(*Entity) listing.Key{
+		"time":     func(e *Entity) listing.Key { return listing.Time(e.Time) },
+		"action":   func(e *Entity) listing.Key { return listing.String(e.Action) },
+		"resource": func(e *Entity) listing.Key { return listing.String(e.Resource) },
+	},
+}
+
+// Recent is the order records are listed in by default, most recent
+// first.
+var Recent = []listing.Order{{Field: "time", Desc: true}}
+
+// Matches returns whether the record satisfies all the criteria set
+// in the filter.
+func (f *Filter) Matches(e *Entity) bool {
+	if actor, ok := f.Actor.Unwrap(); ok && e.Actor != actor {
+		return false
+	}
+
+	if resource, ok := f.Resource.Unwrap(); ok && e.Resource != resource {
+		return false
+	}
+
+	if target, ok := f.Target.Unwrap(); ok && e.Target != target {
+		return false
+	}
+
+	if since, ok := f.Since.Unwrap(); ok && e.Time.Before(since) {
+		return false
+	}
+
+	if until, ok := f.Until.Unwrap(); ok && !e.Time.Before(until) {
+		return false
+	}
+
+	return true
+}
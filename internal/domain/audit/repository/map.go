@@ -0,0 +1,163 @@
+package auditrepo
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Map struct {
+	repo []audit.Entity
+	mu   sync.RWMutex
+
+	datapath string
+}
+
+func NewMap() audit.Repository {
+	return &Map{}
+}
+
+func NewPersistantMap(datapath string) (audit.Repository, error) {
+	repo := Map{datapath: datapath}
+
+	if err := repo.init(); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+func (m *Map) init() error {
+	f, err := os.Open(m.datapath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var repo []entity
+	if err := json.NewDecoder(f).Decode(&repo); err != nil {
+		return err
+	}
+
+	m.repo = make([]audit.Entity, len(repo))
+	for i, record := range repo {
+		m.repo[i] = entity_from_json(record)
+	}
+
+	return nil
+}
+
+func (m *Map) Close() error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	repo := make([]entity, len(m.repo))
+	for i, record := range m.repo {
+		repo[i] = json_for_entity(record)
+	}
+
+	return json.NewEncoder(f).Encode(repo)
+}
+
+// Ping reports whether the data file can still be written.
+func (m *Map) Ping() error {
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func (m *Map) List(filter audit.Filter, q listing.Query) (audit.Entities, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	return audit.Listing.List(m.repo, filter.Matches, q)
+}
+
+func (m *Map) Create(e audit.Entity) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	m.repo = append(m.repo, e)
+	return nil
+}
+
+type entity struct {
+	UUID     uuid.UUID `json:"uuid"`
+	Actor    uuid.UUID `json:"actor"`
+	Role     string    `json:"role"`
+	Action   string    `json:"action"`
+	Resource string    `json:"resource"`
+	Target   uuid.UUID `json:"target"`
+	Address  string    `json:"address"`
+	Time     time.Time `json:"time"`
+	Changes  []change  `json:"changes"`
+}
+
+type change struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+func json_for_entity(e audit.Entity) entity {
+	changes := make([]change, len(e.Changes))
+	for i, c := range e.Changes {
+		changes[i] = change(c)
+	}
+
+	return entity{
+		UUID:     e.UUID,
+		Actor:    e.Actor,
+		Role:     e.Role.String(),
+		Action:   e.Action,
+		Resource: e.Resource,
+		Target:   e.Target,
+		Address:  e.Address,
+		Time:     e.Time,
+		Changes:  changes,
+	}
+}
+
+func entity_from_json(e entity) audit.Entity {
+	role, _ := auth.FromString(e.Role)
+
+	changes := make([]audit.Change, len(e.Changes))
+	for i, c := range e.Changes {
+		changes[i] = audit.Change(c)
+	}
+
+	return audit.Entity{
+		UUID:     e.UUID,
+		Actor:    e.Actor,
+		Role:     role,
+		Action:   e.Action,
+		Resource: e.Resource,
+		Target:   e.Target,
+		Address:  e.Address,
+		Time:     e.Time,
+		Changes:  changes,
+	}
+}
@@ -0,0 +1,30 @@
+package audits
+
+import (
+	"net/http"
+
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/support/resource"
+)
+
+type Resource struct {
+	http.ServeMux
+	Audit      audit.Service
+	Gatekeeper user.Gatekeeper
+}
+
+func New(audit audit.Service, gatekeeper user.Gatekeeper) http.Handler {
+	rc := Resource{Audit: audit, Gatekeeper: gatekeeper}
+
+	handlers := map[string]http.HandlerFunc{
+		"listAudit": resource.Endpoint(gatekeeper, http.StatusOK, audit.List),
+	}
+
+	for _, route := range Routes {
+		rc.Handle(route.Pattern, resource.Routed(handlers[route.ID]))
+	}
+	rc.Handle("/", resource.Routed(http.HandlerFunc(resource.NotFound)))
+
+	return &rc
+}
@@ -0,0 +1,12 @@
+package audits
+
+import (
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/pkg/openapi"
+)
+
+// Routes describes the routes of the resource, under whose patterns
+// [New] registers the handlers of their operations, by ID.
+var Routes = []openapi.Route{
+	{Pattern: "GET /audit/{$}", ID: "listAudit", Summary: "Lists audit records", Tags: []string{"audit"}, Query: audit.ListRequest{}, Response: audit.ListResponse{}},
+}
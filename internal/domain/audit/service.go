@@ -0,0 +1,8 @@
+package audit
+
+import "github.com/alan-b-lima/almodon/internal/auth"
+
+type Service interface {
+	Recorder
+	List(act auth.Actor, req ListRequest) (ListResponse, error)
+}
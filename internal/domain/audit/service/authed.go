@@ -0,0 +1,27 @@
+package auditserve
+
+import (
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/internal/support/service"
+)
+
+type AuthService struct {
+	audit.Service
+	policy *auth.Policy
+}
+
+func New(service audit.Service, policy *auth.Policy) audit.Service {
+	return &AuthService{
+		Service: service,
+		policy:  policy,
+	}
+}
+
+func (s *AuthService) List(act auth.Actor, req audit.ListRequest) (audit.ListResponse, error) {
+	if err := service.Authorize(s.policy, act, "audit:list", auth.Resource{}); err != nil {
+		return audit.ListResponse{}, err
+	}
+
+	return s.Service.List(act, req)
+}
@@ -0,0 +1,81 @@
+package auditserve
+
+import (
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Service struct {
+	records audit.Repository
+}
+
+func NewService(records audit.Repository) audit.Service {
+	return &Service{
+		records: records,
+	}
+}
+
+func (s *Service) List(act auth.Actor, req audit.ListRequest) (audit.ListResponse, error) {
+	if err := listing.CheckOffset(req.Offset); err != nil {
+		return audit.ListResponse{}, err
+	}
+
+	filter := audit.Filter{
+		Actor:    req.Actor,
+		Resource: req.Resource,
+		Target:   req.Target,
+		Since:    req.Since,
+		Until:    req.Until,
+	}
+
+	sort, err := audit.Listing.ParseSort(req.Sort...)
+	if err != nil {
+		return audit.ListResponse{}, err
+	}
+
+	if len(sort) == 0 {
+		sort = audit.Recent
+	}
+
+	res, err := audit.List(s.records, filter, listing.Query{Sort: sort, After: listing.Cursor(req.Cursor), Limit: req.Limit})
+	if err != nil {
+		return audit.ListResponse{}, err
+	}
+
+	lres := audit.ListResponse{
+		Length:       len(res.Records),
+		Records:      make([]audit.Response, len(res.Records)),
+		TotalRecords: res.TotalRecords,
+		Next:         string(res.Next),
+	}
+	for i := range res.Records {
+		lres.Records[i] = transform(&res.Records[i])
+	}
+
+	return lres, nil
+}
+
+func (s *Service) Record(act auth.Actor, action string, target uuid.UUID, before, after any) error {
+	return audit.Record(s.records, act, action, target, before, after)
+}
+
+func transform(e *audit.Entity) audit.Response {
+	changes := make([]audit.ChangeResponse, len(e.Changes))
+	for i, change := range e.Changes {
+		changes[i] = audit.ChangeResponse(change)
+	}
+
+	return audit.Response{
+		UUID:     e.UUID,
+		Actor:    e.Actor,
+		Role:     e.Role.String(),
+		Action:   e.Action,
+		Resource: e.Resource,
+		Target:   e.Target,
+		Address:  e.Address,
+		Time:     e.Time,
+		Changes:  changes,
+	}
+}
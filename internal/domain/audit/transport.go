@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type (
+	ListRequest struct {
+		Actor    opt.Opt[uuid.UUID] `query:"actor"`
+		Resource opt.Opt[string]    `query:"resource"`
+		Target   opt.Opt[uuid.UUID] `query:"target"`
+		Since    opt.Opt[time.Time] `query:"since"`
+		Until    opt.Opt[time.Time] `query:"until"`
+		Sort     []string           `query:"sort"`
+		Cursor   string             `query:"cursor"`
+		Offset   opt.Opt[int]       `query:"offset,deprecated"`
+		Limit    int                `query:"limit" default:"10"`
+	}
+)
+
+type (
+	ListResponse struct {
+		Length       int        `json:"length"`
+		Records      []Response `json:"records" encode:"rows"`
+		TotalRecords int        `json:"total_records"`
+		Next         string     `json:"next,omitempty" header:"Next-Cursor"`
+	}
+
+	Response struct {
+		UUID     uuid.UUID        `json:"uuid"`
+		Actor    uuid.UUID        `json:"actor"`
+		Role     string           `json:"role"`
+		Action   string           `json:"action"`
+		Resource string           `json:"resource"`
+		Target   uuid.UUID        `json:"target"`
+		Address  string           `json:"address"`
+		Time     time.Time        `json:"time"`
+		Changes  []ChangeResponse `json:"changes"`
+	}
+
+	ChangeResponse struct {
+		Field  string          `json:"field"`
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+	}
+)
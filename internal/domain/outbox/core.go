@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/alan-b-lima/almodon/pkg/mail"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+const (
+	_MaxAttempts = 8
+	_BaseBackoff = 1 * time.Minute
+	_MaxBackoff  = 6 * time.Hour
+	_Retention   = 7 * 24 * time.Hour
+)
+
+type Enqueuer interface {
+	Enqueue(tmpl Template, to []string, data any) (uuid.UUID, error)
+}
+
+func Get(repo Getter, uuid uuid.UUID) (Entity, error) {
+	return repo.Get(uuid)
+}
+
+func Enqueue(repo Creater, tmpl Template, to []string, data any) (uuid.UUID, error) {
+	subject, text, html, err := Render(tmpl, data)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	m, err := New(to, subject, text, html)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return m.UUID(), repo.Create(translate(&m))
+}
+
+// Deliver sends, through sender, at most limit messages due at now,
+// it returns how many of them were processed. A failed delivery is
+// retried with exponential backoff until _MaxAttempts is reached,
+// at which point the message is marked as failed.
+func Deliver(repo interface {
+	Lister
+	Updater
+}, sender mail.Sender, from string, now time.Time, limit int) (int, error) {
+	due, err := repo.ListDue(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, m := range due {
+		msg := mail.Message{
+			From:    from,
+			To:      m.To,
+			Subject: m.Subject,
+			Text:    m.Text,
+			HTML:    m.HTML,
+		}
+
+		var pm PartialEntity
+		pm.Attempts = opt.Some(m.Attempts + 1)
+
+		if err := sender.Send(msg); err != nil {
+			pm.LastError = opt.Some(err.Error())
+
+			if m.Attempts+1 >= _MaxAttempts {
+				pm.Status = opt.Some(Failed)
+			} else {
+				pm.NextAttempt = opt.Some(now.Add(backoff(m.Attempts)))
+			}
+		} else {
+			pm.Status = opt.Some(Sent)
+			pm.LastError = opt.Some("")
+		}
+
+		if err := repo.Update(m.UUID, pm); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(due), nil
+}
+
+// Purge deletes the messages sent or failed that were enqueued more
+// than _Retention before now, returning how many there were. Pending
+// messages are kept, however old.
+func Purge(repo Purger, now time.Time) (int, error) {
+	return repo.Purge(now.Add(-_Retention))
+}
+
+func backoff(attempts int) time.Duration {
+	delay := _BaseBackoff << attempts
+	if delay <= 0 || delay > _MaxBackoff {
+		return _MaxBackoff
+	}
+
+	return delay
+}
+
+func translate(m *Message) Entity {
+	return Entity{
+		UUID:        m.UUID(),
+		To:          m.To(),
+		Subject:     m.Subject(),
+		Text:        m.Text(),
+		HTML:        m.HTML(),
+		Status:      Pending,
+		NextAttempt: m.Created(),
+		Created:     m.Created(),
+	}
+}
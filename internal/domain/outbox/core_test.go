@@ -0,0 +1,132 @@
+package outbox_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/internal/domain/outbox"
+	outboxrepo "github.com/alan-b-lima/almodon/internal/domain/outbox/repository"
+	"github.com/alan-b-lima/almodon/pkg/mail"
+)
+
+func TestRender(t *testing.T) {
+	type Tests struct {
+		tmpl    Template
+		data    any
+		subject string
+	}
+
+	tests := []Tests{
+		{AccountCreated, AccountCreatedData{Name: "Alan", SIAPE: 123456, Role: "Chefia"}, "Sua conta no Almodon foi criada"},
+	}
+
+	for _, test := range tests {
+		subject, text, html, err := Render(test.tmpl, test.data)
+		if err != nil {
+			t.Errorf("Template %q: did not expect error, but got: %v", test.tmpl, err)
+			continue
+		}
+
+		if subject != test.subject {
+			t.Errorf("Template %q: expected subject %q, got %q", test.tmpl, test.subject, subject)
+		}
+
+		if !strings.Contains(text, "Olá, Alan!") || !strings.Contains(html, "Olá, Alan!") {
+			t.Errorf("Template %q: expected greeting in both bodies", test.tmpl)
+		}
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	repo := outboxrepo.NewMap()
+
+	uuid, err := Enqueue(repo, AccountCreated, []string{"alan@ufvjm.edu.br"}, AccountCreatedData{Name: "Alan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fail := true
+	sender := mail.SenderFunc(func(mail.Message) error {
+		if fail {
+			return errors.New("connection refused")
+		}
+
+		return nil
+	})
+
+	now := time.Now()
+	if _, err := Deliver(repo, sender, "almodon@ufvjm.edu.br", now, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	res, _ := Get(repo, uuid)
+	if res.Status != Pending || res.Attempts != 1 || !res.NextAttempt.After(now) {
+		t.Fatalf("expected message to be rescheduled, got %+v", res)
+	}
+
+	if n, _ := Deliver(repo, sender, "almodon@ufvjm.edu.br", now, 10); n != 0 {
+		t.Fatalf("expected no message due before the backoff, got %d", n)
+	}
+
+	fail = false
+	if _, err := Deliver(repo, sender, "almodon@ufvjm.edu.br", res.NextAttempt, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	res, _ = Get(repo, uuid)
+	if res.Status != Sent || res.Attempts != 2 {
+		t.Fatalf("expected message to be sent, got %+v", res)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	repo := outboxrepo.NewMap()
+
+	sent, err := Enqueue(repo, AccountCreated, []string{"alan@ufvjm.edu.br"}, AccountCreatedData{Name: "Alan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if _, err := Deliver(repo, mail.SenderFunc(func(mail.Message) error { return nil }), "almodon@ufvjm.edu.br", now, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := Enqueue(repo, AccountCreated, []string{"otavio@ufvjm.edu.br"}, AccountCreatedData{Name: "Otávio"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Tests struct {
+		now   time.Time
+		count int
+	}
+
+	tests := []Tests{
+		{now, 0},
+		{now.Add(6 * 24 * time.Hour), 0},
+		{now.Add(8 * 24 * time.Hour), 1},
+		{now.Add(8 * 24 * time.Hour), 0},
+	}
+
+	for i, test := range tests {
+		count, err := Purge(repo, test.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if count != test.count {
+			t.Errorf("%d: expected %d messages purged, got %d", i, test.count, count)
+		}
+	}
+
+	if _, err := Get(repo, sent); err == nil {
+		t.Error("expected the sent message to be purged")
+	}
+
+	if _, err := Get(repo, pending); err != nil {
+		t.Errorf("expected the pending message to be kept, got %v", err)
+	}
+}
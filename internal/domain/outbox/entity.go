@@ -0,0 +1,84 @@
+package outbox
+
+import (
+	netmail "net/mail"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Message struct {
+	uuid    uuid.UUID
+	to      []string
+	subject string
+	text    string
+	html    string
+	created time.Time
+}
+
+func New(to []string, subject, text, html string) (Message, error) {
+	var m Message
+
+	err := errors.Join(
+		m.SetTo(to),
+		m.SetSubject(subject),
+		m.SetBody(text, html),
+	)
+	if err != nil {
+		return Message{}, xerrors.ErrMessageCreation.New(err)
+	}
+
+	m.uuid = uuid.NewUUIDv7()
+	m.created = time.Now()
+	return m, nil
+}
+
+func (m *Message) UUID() uuid.UUID    { return m.uuid }
+func (m *Message) To() []string       { return m.to }
+func (m *Message) Subject() string    { return m.subject }
+func (m *Message) Text() string       { return m.text }
+func (m *Message) HTML() string       { return m.html }
+func (m *Message) Created() time.Time { return m.created }
+
+func (m *Message) SetTo(to []string) error { return entity.Set(&m.to, to, ProcessRecipients) }
+func (m *Message) SetSubject(subject string) error {
+	return entity.Set(&m.subject, subject, ProcessSubject)
+}
+
+func (m *Message) SetBody(text, html string) error {
+	if text == "" && html == "" {
+		return xerrors.ErrMessageEmpty
+	}
+
+	m.text, m.html = text, html
+	return nil
+}
+
+func ProcessRecipients(to []string) ([]string, error) {
+	if len(to) == 0 {
+		return nil, xerrors.ErrNoRecipients
+	}
+
+	res := make([]string, 0, len(to))
+	for _, rcpt := range to {
+		addr, err := netmail.ParseAddress(rcpt)
+		if err != nil {
+			return nil, xerrors.ErrRecipientInvalid.New(rcpt)
+		}
+
+		res = append(res, addr.String())
+	}
+
+	return res, nil
+}
+
+func ProcessSubject(subject string) (string, error) {
+	if subject == "" {
+		return "", xerrors.ErrSubjectEmpty
+	}
+
+	return subject, nil
+}
@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Repository interface {
+	Lister
+	Getter
+	Creater
+	Updater
+	Purger
+}
+
+type (
+	Lister interface {
+		ListDue(now time.Time, limit int) ([]Entity, error)
+	}
+
+	Getter interface {
+		Get(uuid.UUID) (Entity, error)
+	}
+
+	Creater interface {
+		Create(Entity) error
+	}
+
+	Updater interface {
+		Update(uuid.UUID, PartialEntity) error
+	}
+
+	Purger interface {
+		Purge(before time.Time) (int, error)
+	}
+)
+
+type Status uint8
+
+const (
+	Pending Status = iota
+	Sent
+	Failed
+)
+
+type (
+	Entity struct {
+		UUID        uuid.UUID
+		To          []string
+		Subject     string
+		Text        string
+		HTML        string
+		Status      Status
+		Attempts    int
+		NextAttempt time.Time
+		LastError   string
+		Created     time.Time
+	}
+
+	PartialEntity struct {
+		Status      opt.Opt[Status]
+		Attempts    opt.Opt[int]
+		NextAttempt opt.Opt[time.Time]
+		LastError   opt.Opt[string]
+	}
+)
+
+func (s Status) String() string {
+	return statusStrings[s]
+}
+
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Status) UnmarshalText(buf []byte) error {
+	status, ok := StatusFromString(string(buf))
+	if !ok {
+		return xerrors.ErrBadStatus
+	}
+
+	*s = status
+	return nil
+}
+
+func StatusFromString(string string) (Status, bool) {
+	status, in := stringStatuses[string]
+	return status, in
+}
+
+var statusStrings = map[Status]string{
+	Pending: "pending",
+	Sent:    "sent",
+	Failed:  "failed",
+}
+
+var stringStatuses = map[string]Status{
+	"pending": Pending,
+	"sent":    Sent,
+	"failed":  Failed,
+}
@@ -0,0 +1,217 @@
+package outboxrepo
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/domain/outbox"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Map struct {
+	uuidIndex map[uuid.UUID]int
+
+	repo []outbox.Entity
+	mu   sync.RWMutex
+
+	datapath string
+}
+
+func NewMap() outbox.Repository {
+	repo := Map{
+		uuidIndex: make(map[uuid.UUID]int),
+	}
+
+	return &repo
+}
+
+func NewPersistantMap(datapath string) (outbox.Repository, error) {
+	repo := Map{
+		uuidIndex: make(map[uuid.UUID]int),
+		datapath:  datapath,
+	}
+
+	if err := repo.init(); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+func (m *Map) init() error {
+	f, err := os.Open(m.datapath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var repo []entity
+	if err := json.NewDecoder(f).Decode(&repo); err != nil {
+		return err
+	}
+
+	m.repo = make([]outbox.Entity, len(repo))
+	for i, record := range repo {
+		m.repo[i] = outbox.Entity(record)
+		m.uuidIndex[record.UUID] = i
+	}
+
+	return nil
+}
+
+func (m *Map) Close() error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	repo := make([]entity, len(m.repo))
+	for i, record := range m.repo {
+		repo[i] = entity(record)
+	}
+
+	return json.NewEncoder(f).Encode(repo)
+}
+
+// Ping reports whether the data file can still be written.
+func (m *Map) Ping() error {
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func (m *Map) ListDue(now time.Time, limit int) ([]outbox.Entity, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	var res []outbox.Entity
+	for _, record := range m.repo {
+		if record.Status == outbox.Pending && !record.NextAttempt.After(now) {
+			res = append(res, record)
+		}
+	}
+
+	slices.SortFunc(res, func(a, b outbox.Entity) int {
+		return a.NextAttempt.Compare(b.NextAttempt)
+	})
+
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res, nil
+}
+
+func (m *Map) Get(uuid uuid.UUID) (outbox.Entity, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	index, in := m.uuidIndex[uuid]
+	if !in {
+		return outbox.Entity{}, xerrors.ErrMessageNotFound
+	}
+
+	return m.repo[index], nil
+}
+
+func (m *Map) Create(message outbox.Entity) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	m.uuidIndex[message.UUID] = len(m.repo)
+	m.repo = append(m.repo, message)
+
+	return nil
+}
+
+func (m *Map) Update(uuid uuid.UUID, message outbox.PartialEntity) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.uuidIndex[uuid]
+	if !in {
+		return xerrors.ErrMessageNotFound
+	}
+
+	e := &m.repo[index]
+
+	some_then(&e.Status, message.Status)
+	some_then(&e.Attempts, message.Attempts)
+	some_then(&e.NextAttempt, message.NextAttempt)
+	some_then(&e.LastError, message.LastError)
+
+	return nil
+}
+
+func (m *Map) Purge(before time.Time) (int, error) {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	var count int
+	for i := len(m.repo) - 1; i >= 0; i-- {
+		if e := m.repo[i]; e.Status != outbox.Pending && e.Created.Before(before) {
+			m.delete(e.UUID)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m *Map) delete(uuid uuid.UUID) {
+	index, in := m.uuidIndex[uuid]
+	if !in {
+		return
+	}
+
+	delete(m.uuidIndex, uuid)
+
+	last := len(m.repo) - 1
+	if index != last {
+		m.repo[index] = m.repo[last]
+		m.uuidIndex[m.repo[index].UUID] = index
+	}
+	m.repo = m.repo[:last]
+}
+
+func some_then[F any](dst *F, src opt.Opt[F]) {
+	val, ok := src.Unwrap()
+	if !ok {
+		return
+	}
+
+	*dst = val
+}
+
+type entity struct {
+	UUID        uuid.UUID     `json:"uuid"`
+	To          []string      `json:"to"`
+	Subject     string        `json:"subject"`
+	Text        string        `json:"text"`
+	HTML        string        `json:"html"`
+	Status      outbox.Status `json:"status"`
+	Attempts    int           `json:"attempts"`
+	NextAttempt time.Time     `json:"next_attempt"`
+	LastError   string        `json:"last_error"`
+	Created     time.Time     `json:"created"`
+}
@@ -0,0 +1,97 @@
+package outboxserve
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/domain/outbox"
+	"github.com/alan-b-lima/almodon/pkg/mail"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+const (
+	_Interval = 1 * time.Minute
+	_Batch    = 32
+)
+
+type Service struct {
+	messages outbox.Repository
+	sender   mail.Sender
+	from     string
+
+	wake   chan struct{}
+	cancel chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewService creates an outbox service. Messages are enqueued on
+// messages and delivered, in background, by sender on behalf of
+// from. If sender is nil, messages are kept pending until a service
+// with a sender is created over the same repository.
+func NewService(messages outbox.Repository, sender mail.Sender, from string) outbox.Enqueuer {
+	s := Service{
+		messages: messages,
+		sender:   sender,
+		from:     from,
+		wake:     make(chan struct{}, 1),
+		cancel:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if sender == nil {
+		close(s.done)
+		return &s
+	}
+
+	go dispatch(&s)
+
+	return &s
+}
+
+func (s *Service) Enqueue(tmpl outbox.Template, to []string, data any) (uuid.UUID, error) {
+	uuid, err := outbox.Enqueue(s.messages, tmpl, to, data)
+	if err != nil {
+		return uuid, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return uuid, nil
+}
+
+func (s *Service) Close() error {
+	s.once.Do(func() { close(s.cancel) })
+	<-s.done
+
+	return nil
+}
+
+func dispatch(s *Service) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(_Interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := outbox.Deliver(s.messages, s.sender, s.from, time.Now(), _Batch)
+			if err != nil || n < _Batch {
+				break
+			}
+		}
+
+		outbox.Purge(s.messages, time.Now())
+
+		select {
+		case <-s.cancel:
+			return
+
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
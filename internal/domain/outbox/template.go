@@ -0,0 +1,82 @@
+package outbox
+
+import (
+	"embed"
+	htemplate "html/template"
+	"strings"
+	ttemplate "text/template"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+)
+
+type Template string
+
+const AccountCreated Template = "account-created"
+
+type AccountCreatedData struct {
+	Name  string
+	SIAPE int
+	Role  string
+}
+
+//go:embed templates
+var files embed.FS
+
+var location = func() *time.Location {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		return time.FixedZone("BRT", -3*60*60)
+	}
+
+	return loc
+}()
+
+var funcs = map[string]any{
+	"date":     func(t time.Time) string { return t.In(location).Format("02/01/2006") },
+	"datetime": func(t time.Time) string { return t.In(location).Format("02/01/2006 às 15:04") },
+}
+
+type template struct {
+	text *ttemplate.Template
+	html *htemplate.Template
+}
+
+var templates = map[Template]template{}
+
+func init() {
+	for _, name := range [...]Template{AccountCreated} {
+		text := ttemplate.Must(ttemplate.New(string(name)+".txt").Funcs(funcs).ParseFS(files, "templates/"+string(name)+".txt"))
+		html := htemplate.Must(htemplate.New("layout").Funcs(funcs).ParseFS(files, "templates/layout.html", "templates/"+string(name)+".html"))
+
+		templates[name] = template{text: text, html: html}
+	}
+}
+
+func Render(name Template, data any) (subject, text, html string, err error) {
+	tmpl, in := templates[name]
+	if !in {
+		return "", "", "", xerrors.ErrTemplateNotFound.New(name)
+	}
+
+	var b strings.Builder
+
+	if err := tmpl.text.ExecuteTemplate(&b, "subject", data); err != nil {
+		return "", "", "", xerrors.ErrTemplateRender.New(err)
+	}
+	subject = strings.TrimSpace(b.String())
+	b.Reset()
+
+	if err := tmpl.text.ExecuteTemplate(&b, string(name)+".txt", data); err != nil {
+		return "", "", "", xerrors.ErrTemplateRender.New(err)
+	}
+	text = b.String()
+	b.Reset()
+
+	if err := tmpl.html.ExecuteTemplate(&b, "layout", data); err != nil {
+		return "", "", "", xerrors.ErrTemplateRender.New(err)
+	}
+	html = b.String()
+
+	return subject, text, html, nil
+}
@@ -0,0 +1,85 @@
+package product
+
+import (
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+	"github.com/alan-b-lima/almodon/pkg/validate"
+)
+
+// MaxSearchResults bounds the results of a search.
+const MaxSearchResults = 50
+
+func List(products Lister, filter Filter, q listing.Query) (Entities, error) {
+	return products.List(filter, q)
+}
+
+// Search searches products by their names, codes and descriptions,
+// see [search.Index.Search], returning them ranked, at most limit of
+// them, bounded by [MaxSearchResults].
+func Search(products Searcher, query string, limit int) ([]Entity, error) {
+	return products.Search(query, min(max(limit, 0), MaxSearchResults))
+}
+
+func Get(products Getter, uuid uuid.UUID) (Entity, error) {
+	return products.Get(uuid)
+}
+
+func Create(products Creater, name, description, catmat, siads, unit string) (uuid.UUID, error) {
+	p, err := New(name, description, catmat, siads, unit)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return p.UUID(), products.Create(translate(&p))
+}
+
+func Patch(products Patcher, uuid uuid.UUID, match entity.Precondition, name, description, catmat, siads, unit opt.Opt[string]) error {
+	var pp PartialEntity
+
+	err := errors.Join(
+		validate.At("/name", some_then(&pp.Name, name, ProcessName)),
+		validate.At("/description", some_then(&pp.Description, description, ProcessDescription)),
+		validate.At("/catmat", some_then(&pp.CATMAT, catmat, ProcessCATMAT)),
+		validate.At("/siads", some_then(&pp.SIADS, siads, ProcessSIADS)),
+		validate.At("/unit", some_then(&pp.Unit, unit, ProcessUnit)),
+	)
+	if err != nil {
+		return xerrors.ErrProductUpdate.New(err)
+	}
+
+	return products.Patch(uuid, match, pp)
+}
+
+func Delete(products Deleter, uuid uuid.UUID, match entity.Precondition) error {
+	return products.Delete(uuid, match)
+}
+
+func translate(p *Product) Entity {
+	return Entity{
+		UUID:        p.UUID(),
+		Name:        p.Name(),
+		Description: p.Description(),
+		CATMAT:      p.CATMAT(),
+		SIADS:       p.SIADS(),
+		Unit:        p.Unit(),
+	}
+}
+
+func some_then[F, R any](dst *opt.Opt[R], src opt.Opt[F], fn func(F) (R, error)) error {
+	val, ok := src.Unwrap()
+	if !ok {
+		return nil
+	}
+
+	res, err := fn(val)
+	if err != nil {
+		return err
+	}
+
+	*dst = opt.Some(res)
+	return nil
+}
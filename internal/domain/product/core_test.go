@@ -0,0 +1,153 @@
+package product_test
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/internal/domain/product"
+	productrepo "github.com/alan-b-lima/almodon/internal/domain/product/repository"
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+)
+
+func TestNew(t *testing.T) {
+	type Tests struct {
+		name, description, catmat, siads, unit string
+		pointers                               []string
+	}
+
+	tests := []Tests{
+		{"Resina composta", "Cor A2", "445566", "", "seringa", nil},
+		{"  Luva  ", "", "", "0012", "caixa", nil},
+		{"", "", "", "", "", []string{"/name", "/unit"}},
+		{"Gaze", "", "44A", "1234567890123", "pacote", []string{"/catmat", "/siads"}},
+		{strings.Repeat("x", 121), strings.Repeat("y", 1001), "", "", strings.Repeat("z", 17), []string{"/name", "/description", "/unit"}},
+	}
+
+	for _, test := range tests {
+		p, err := New(test.name, test.description, test.catmat, test.siads, test.unit)
+		if got := pointers(err); !slices.Equal(got, test.pointers) {
+			t.Errorf("%q: expected failures on %v, got %v", test.name, test.pointers, err)
+			continue
+		}
+
+		if err == nil && p.Name() != strings.TrimSpace(test.name) {
+			t.Errorf("expected the name to be trimmed, got %q", p.Name())
+		}
+	}
+}
+
+// pointers returns the pointers of the fields the error is about, as
+// marshaled to clients.
+func pointers(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	buf, _ := json.Marshal(err)
+
+	var res struct {
+		Cause []struct {
+			Pointer string `json:"pointer"`
+		} `json:"cause"`
+	}
+	json.Unmarshal(buf, &res)
+
+	var pointers []string
+	for _, cause := range res.Cause {
+		pointers = append(pointers, cause.Pointer)
+	}
+
+	return pointers
+}
+
+func TestSearch(t *testing.T) {
+	repo := productrepo.NewMap()
+
+	create := func(name, description, catmat, siads string) {
+		t.Helper()
+		if _, err := Create(repo, name, description, catmat, siads, "un"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	create("Resina composta fotopolimerizável", "Para restaurações diretas", "445566", "")
+	create("Anestésico local", "Lidocaína 2% com epinefrina", "270911", "")
+	create("Luva de procedimento", "Látex, não estéril", "269944", "00712")
+	create("Luva cirúrgica", "Látex, estéril", "269945", "00713")
+
+	names := func(query string) []string {
+		t.Helper()
+
+		res, err := Search(repo, query, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, e := range res {
+			names = append(names, e.Name)
+		}
+
+		return names
+	}
+
+	type Tests struct {
+		query string
+		exp   []string
+	}
+
+	tests := []Tests{
+		{"resina", []string{"Resina composta fotopolimerizável"}},
+		{"anestesico", []string{"Anestésico local"}},
+		{"ANESTÉS", []string{"Anestésico local"}},
+		{"luva", []string{"Luva de procedimento", "Luva cirúrgica"}},
+		{"luva cirurgica", []string{"Luva cirúrgica"}},
+		{"lidocaina", []string{"Anestésico local"}},
+		{"270911", []string{"Anestésico local"}},
+		{"0071", []string{"Luva de procedimento", "Luva cirúrgica"}},
+	}
+
+	for _, test := range tests {
+		if got := names(test.query); !slices.Equal(got, test.exp) {
+			t.Errorf("%q: expected %v, got %v", test.query, test.exp, got)
+		}
+	}
+
+	// patches and deletions are reflected by the index
+	page, err := List(repo, Filter{CATMAT: opt.Some("445566")}, listing.Query{Limit: 1})
+	if err != nil || len(page.Records) != 1 {
+		t.Fatalf("expected the resin to be listed, got %v, %v", page, err)
+	}
+	resin, version := page.Records[0].UUID, page.Records[0].Version
+
+	if err := Patch(repo, resin, entity.Match(version), opt.Some("Resina flow"), opt.None[string](), opt.None[string](), opt.None[string](), opt.None[string]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := names("flow"); !slices.Equal(got, []string{"Resina flow"}) {
+		t.Errorf("expected the patched name to be found, got %v", got)
+	}
+
+	if got := names("fotopolimerizavel"); len(got) != 0 {
+		t.Errorf("expected the former name not to be found, got %v", got)
+	}
+
+	// changes based on the former version are refused
+	if err := Delete(repo, resin, entity.Match(version)); !errors.Is(err, xerrors.ErrVersionMismatch) {
+		t.Fatalf("expected a version mismatch, got %v", err)
+	}
+
+	if err := Delete(repo, resin, entity.Match(version+1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := names("resina"); len(got) != 0 {
+		t.Errorf("expected the deleted product not to be found, got %v", got)
+	}
+}
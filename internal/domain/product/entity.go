@@ -0,0 +1,147 @@
+package product
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+	"github.com/alan-b-lima/almodon/pkg/validate"
+)
+
+const (
+	_MaxNameLength        = 120
+	_MaxDescriptionLength = 1000
+	_MaxUnitLength        = 16
+	_MaxCodeLength        = 12
+)
+
+// Product is a material supplied by the storeroom. Products are
+// identified in the federal catalogs by their CATMAT code, for the
+// catalog of materials, and by their SIADS code, for the asset
+// management system, both optional.
+type Product struct {
+	uuid        uuid.UUID
+	name        string
+	description string
+	catmat      string
+	siads       string
+	unit        string
+}
+
+func New(name, description, catmat, siads, unit string) (Product, error) {
+	var p Product
+
+	err := errors.Join(
+		validate.At("/name", p.SetName(name)),
+		validate.At("/description", p.SetDescription(description)),
+		validate.At("/catmat", p.SetCATMAT(catmat)),
+		validate.At("/siads", p.SetSIADS(siads)),
+		validate.At("/unit", p.SetUnit(unit)),
+	)
+	if err != nil {
+		return Product{}, xerrors.ErrProductCreation.New(err)
+	}
+
+	p.uuid = uuid.NewUUIDv7()
+	return p, nil
+}
+
+func (p *Product) UUID() uuid.UUID     { return p.uuid }
+func (p *Product) Name() string        { return p.name }
+func (p *Product) Description() string { return p.description }
+func (p *Product) CATMAT() string      { return p.catmat }
+func (p *Product) SIADS() string       { return p.siads }
+func (p *Product) Unit() string        { return p.unit }
+
+func (p *Product) SetName(name string) error   { return entity.Set(&p.name, name, ProcessName) }
+func (p *Product) SetCATMAT(code string) error { return entity.Set(&p.catmat, code, ProcessCATMAT) }
+func (p *Product) SetSIADS(code string) error  { return entity.Set(&p.siads, code, ProcessSIADS) }
+func (p *Product) SetUnit(unit string) error   { return entity.Set(&p.unit, unit, ProcessUnit) }
+
+func (p *Product) SetDescription(description string) error {
+	return entity.Set(&p.description, description, ProcessDescription)
+}
+
+var nameRules = []validate.Rule[string]{
+	validate.Required[string](xerrors.ErrNameEmpty),
+	maxRunes(_MaxNameLength, xerrors.ErrProductNameTooLong.New(_MaxNameLength)),
+}
+
+func ProcessName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if err := validate.Value(name, nameRules...); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+var descriptionRules = []validate.Rule[string]{
+	maxRunes(_MaxDescriptionLength, xerrors.ErrProductDescriptionTooLong.New(_MaxDescriptionLength)),
+}
+
+func ProcessDescription(description string) (string, error) {
+	description = strings.TrimSpace(description)
+	if err := validate.Value(description, descriptionRules...); err != nil {
+		return "", err
+	}
+
+	return description, nil
+}
+
+// codes are kept as strings, as their leading zeros are significant
+var reCode = regexp.MustCompile(`^[0-9]{1,12}$`)
+
+var (
+	catmatRules = []validate.Rule[string]{validate.Match(reCode, xerrors.ErrProductCodeInvalid.New("CATMAT", _MaxCodeLength))}
+	siadsRules  = []validate.Rule[string]{validate.Match(reCode, xerrors.ErrProductCodeInvalid.New("SIADS", _MaxCodeLength))}
+)
+
+func ProcessCATMAT(code string) (string, error) {
+	return processCode(code, catmatRules)
+}
+
+func ProcessSIADS(code string) (string, error) {
+	return processCode(code, siadsRules)
+}
+
+func processCode(code string, rules []validate.Rule[string]) (string, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return "", nil
+	}
+
+	if err := validate.Value(code, rules...); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+var unitRules = []validate.Rule[string]{
+	validate.Required[string](xerrors.ErrProductUnitEmpty),
+	maxRunes(_MaxUnitLength, xerrors.ErrProductUnitTooLong.New(_MaxUnitLength)),
+}
+
+func ProcessUnit(unit string) (string, error) {
+	unit = strings.TrimSpace(unit)
+	if err := validate.Value(unit, unitRules...); err != nil {
+		return "", err
+	}
+
+	return unit, nil
+}
+
+func maxRunes(n int, err error) validate.Rule[string] {
+	return func(val string) error {
+		if utf8.RuneCountInString(val) > n {
+			return err
+		}
+
+		return nil
+	}
+}
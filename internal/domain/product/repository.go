@@ -0,0 +1,111 @@
+package product
+
+import (
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/search"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Repository interface {
+	Lister
+	Searcher
+	Getter
+	Creater
+	Patcher
+	Deleter
+}
+
+type (
+	Lister interface {
+		List(filter Filter, q listing.Query) (Entities, error)
+	}
+
+	Searcher interface {
+		Search(query string, limit int) ([]Entity, error)
+	}
+
+	Getter interface {
+		Get(uuid.UUID) (Entity, error)
+	}
+
+	Creater interface {
+		Create(Entity) error
+	}
+
+	// Patcher patches products, provided their version matches the
+	// precondition, incrementing it.
+	Patcher interface {
+		Patch(uuid.UUID, entity.Precondition, PartialEntity) error
+	}
+
+	// Deleter deletes products, provided their version matches the
+	// precondition.
+	Deleter interface {
+		Delete(uuid.UUID, entity.Precondition) error
+	}
+)
+
+type (
+	Filter struct {
+		CATMAT opt.Opt[string]
+		SIADS  opt.Opt[string]
+	}
+
+	Entities = listing.Page[Entity]
+
+	Entity struct {
+		UUID        uuid.UUID
+		Name        string
+		Description string
+		CATMAT      string
+		SIADS       string
+		Unit        string
+		Version     entity.Version
+	}
+
+	PartialEntity struct {
+		Name        opt.Opt[string]
+		Description opt.Opt[string]
+		CATMAT      opt.Opt[string]
+		SIADS       opt.Opt[string]
+		Unit        opt.Opt[string]
+	}
+)
+
+// Listing describes the fields products may be sorted by, names are
+// sorted as searched, regardless of case and diacritics.
+var Listing = listing.Schema[Entity]{
+	ID: func(e *Entity) uuid.UUID { return e.UUID },
+	Fields: map[string]func(*Entity) listing.Key{
+		"name":   func(e *Entity) listing.Key { return listing.String(search.Fold(e.Name)) },
+		"catmat": func(e *Entity) listing.Key { return listing.String(e.CATMAT) },
+		"siads":  func(e *Entity) listing.Key { return listing.String(e.SIADS) },
+	},
+}
+
+// Matches returns whether the product satisfies all the criteria set
+// in the filter.
+func (f *Filter) Matches(e *Entity) bool {
+	if catmat, ok := f.CATMAT.Unwrap(); ok && e.CATMAT != catmat {
+		return false
+	}
+
+	if siads, ok := f.SIADS.Unwrap(); ok && e.SIADS != siads {
+		return false
+	}
+
+	return true
+}
+
+// Fields are the fields products are searched by, matches on names
+// and codes outweigh those on descriptions.
+func Fields(e *Entity) []search.Field {
+	return []search.Field{
+		{Text: e.Name, Weight: 3},
+		{Text: e.CATMAT, Weight: 2},
+		{Text: e.SIADS, Weight: 2},
+		{Text: e.Description, Weight: 1},
+	}
+}
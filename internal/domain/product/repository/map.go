@@ -0,0 +1,224 @@
+package productrepo
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/alan-b-lima/almodon/internal/domain/product"
+	entitypkg "github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/search"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Map struct {
+	uuidIndex map[uuid.UUID]int
+	search    *search.Index[uuid.UUID]
+
+	repo []product.Entity
+	mu   sync.RWMutex
+
+	datapath string
+}
+
+func NewMap() product.Repository {
+	return &Map{
+		uuidIndex: make(map[uuid.UUID]int),
+		search:    search.NewIndex[uuid.UUID](),
+	}
+}
+
+func NewPersistantMap(datapath string) (product.Repository, error) {
+	repo := Map{
+		uuidIndex: make(map[uuid.UUID]int),
+		search:    search.NewIndex[uuid.UUID](),
+		datapath:  datapath,
+	}
+
+	if err := repo.init(); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+// init reads the products, the search index is not persisted, but
+// rebuilt out of them.
+func (m *Map) init() error {
+	f, err := os.Open(m.datapath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var repo []entity
+	if err := json.NewDecoder(f).Decode(&repo); err != nil {
+		return err
+	}
+
+	m.repo = make([]product.Entity, len(repo))
+	for i, record := range repo {
+		m.repo[i] = product.Entity(record)
+		if record.Version == 0 {
+			m.repo[i].Version = 1
+		}
+		m.uuidIndex[record.UUID] = i
+		m.search.Add(record.UUID, product.Fields(&m.repo[i])...)
+	}
+
+	return nil
+}
+
+func (m *Map) Close() error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	repo := make([]entity, len(m.repo))
+	for i, record := range m.repo {
+		repo[i] = entity(record)
+	}
+
+	return json.NewEncoder(f).Encode(repo)
+}
+
+// Ping reports whether the data file can still be written.
+func (m *Map) Ping() error {
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func (m *Map) List(filter product.Filter, q listing.Query) (product.Entities, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	return product.Listing.List(m.repo, filter.Matches, q)
+}
+
+func (m *Map) Search(query string, limit int) ([]product.Entity, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	results := m.search.Search(query, limit)
+
+	res := make([]product.Entity, len(results))
+	for i, result := range results {
+		res[i] = m.repo[m.uuidIndex[result.Key]]
+	}
+
+	return res, nil
+}
+
+func (m *Map) Get(uuid uuid.UUID) (product.Entity, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	index, in := m.uuidIndex[uuid]
+	if !in {
+		return product.Entity{}, xerrors.ErrProductNotFound
+	}
+
+	return m.repo[index], nil
+}
+
+func (m *Map) Create(p product.Entity) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	p.Version = 1
+
+	m.uuidIndex[p.UUID] = len(m.repo)
+	m.repo = append(m.repo, p)
+	m.search.Add(p.UUID, product.Fields(&p)...)
+
+	return nil
+}
+
+func (m *Map) Patch(uuid uuid.UUID, match entitypkg.Precondition, p product.PartialEntity) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.uuidIndex[uuid]
+	if !in {
+		return xerrors.ErrProductNotFound
+	}
+
+	e := &m.repo[index]
+	if !match.Matches(e.Version) {
+		return xerrors.ErrVersionMismatch
+	}
+
+	some_then(&e.Name, p.Name)
+	some_then(&e.Description, p.Description)
+	some_then(&e.CATMAT, p.CATMAT)
+	some_then(&e.SIADS, p.SIADS)
+	some_then(&e.Unit, p.Unit)
+	e.Version++
+
+	m.search.Add(uuid, product.Fields(e)...)
+	return nil
+}
+
+func (m *Map) Delete(uuid uuid.UUID, match entitypkg.Precondition) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.uuidIndex[uuid]
+	if !in {
+		return xerrors.ErrProductNotFound
+	}
+
+	if !match.Matches(m.repo[index].Version) {
+		return xerrors.ErrVersionMismatch
+	}
+
+	delete(m.uuidIndex, uuid)
+	m.search.Remove(uuid)
+
+	m.repo = slices.Delete(m.repo, index, index+1)
+	for i := index; i < len(m.repo); i++ {
+		m.uuidIndex[m.repo[i].UUID] = i
+	}
+
+	return nil
+}
+
+func some_then[F any](dst *F, src opt.Opt[F]) {
+	val, ok := src.Unwrap()
+	if !ok {
+		return
+	}
+
+	*dst = val
+}
+
+type entity struct {
+	UUID        uuid.UUID         `json:"uuid"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	CATMAT      string            `json:"catmat"`
+	SIADS       string            `json:"siads"`
+	Unit        string            `json:"unit"`
+	Version     entitypkg.Version `json:"version"`
+}
@@ -0,0 +1,36 @@
+package products
+
+import (
+	"net/http"
+
+	"github.com/alan-b-lima/almodon/internal/domain/product"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/support/resource"
+)
+
+type Resource struct {
+	http.ServeMux
+	Products   product.Service
+	Gatekeeper user.Gatekeeper
+}
+
+func New(products product.Service, gatekeeper user.Gatekeeper) http.Handler {
+	rc := Resource{Products: products, Gatekeeper: gatekeeper}
+
+	handlers := map[string]http.HandlerFunc{
+		"listProducts":     resource.Endpoint(gatekeeper, http.StatusOK, products.List),
+		"searchProducts":   resource.Endpoint(gatekeeper, http.StatusOK, products.Search),
+		"completeProducts": resource.Endpoint(gatekeeper, http.StatusOK, products.Complete),
+		"getProduct":       resource.Endpoint(gatekeeper, http.StatusOK, products.Get),
+		"createProduct":    resource.Endpoint(gatekeeper, http.StatusCreated, products.Create),
+		"patchProduct":     resource.Action(gatekeeper, products.Patch),
+		"deleteProduct":    resource.Action(gatekeeper, products.Delete),
+	}
+
+	for _, route := range Routes {
+		rc.Handle(route.Pattern, resource.Routed(handlers[route.ID]))
+	}
+	rc.Handle("/", resource.Routed(http.HandlerFunc(resource.NotFound)))
+
+	return &rc
+}
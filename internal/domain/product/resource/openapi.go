@@ -0,0 +1,26 @@
+package products
+
+import (
+	"net/http"
+
+	"github.com/alan-b-lima/almodon/internal/domain/product"
+	"github.com/alan-b-lima/almodon/pkg/openapi"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// Routes describes the routes of the resource, under whose patterns
+// [New] registers the handlers of their operations, by ID.
+var Routes = []openapi.Route{
+	{Pattern: "GET /products/{$}", ID: "listProducts", Summary: "Lists products", Tags: _Tags, Query: product.ListRequest{}, Response: product.ListResponse{}},
+	{Pattern: "GET /products/search/{$}", ID: "searchProducts", Summary: "Searches products by name, description, CATMAT or SIADS code, regardless of case and accents", Tags: _Tags, Query: product.SearchRequest{}, Response: product.SearchResponse{}},
+	{Pattern: "GET /products/autocomplete/{$}", ID: "completeProducts", Summary: "Suggests products as their names are typed", Tags: _Tags, Query: product.CompleteRequest{}, Response: product.CompleteResponse{}},
+	{Pattern: "GET /products/{uuid}", ID: "getProduct", Summary: "Gets a product", Tags: _Tags, Params: _UUID, Response: product.Response{}},
+	{Pattern: "POST /products/{$}", ID: "createProduct", Summary: "Creates a product", Tags: _Tags, Body: product.CreateRequest{}, Status: http.StatusCreated, Response: uuid.UUID{}},
+	{Pattern: "PATCH /products/{uuid}", ID: "patchProduct", Summary: "Patches a product", Tags: _Tags, Params: _UUID, Headers: product.PatchRequest{}, Body: product.PatchRequest{}},
+	{Pattern: "DELETE /products/{uuid}", ID: "deleteProduct", Summary: "Deletes a product", Tags: _Tags, Params: _UUID, Headers: product.DeleteRequest{}},
+}
+
+var (
+	_Tags = []string{"products"}
+	_UUID = map[string]any{"uuid": uuid.UUID{}}
+)
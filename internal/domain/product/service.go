@@ -0,0 +1,17 @@
+package product
+
+import (
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Service interface {
+	List(act auth.Actor, req ListRequest) (ListResponse, error)
+	Search(act auth.Actor, req SearchRequest) (SearchResponse, error)
+	Complete(act auth.Actor, req CompleteRequest) (CompleteResponse, error)
+
+	Get(act auth.Actor, req GetRequest) (Response, error)
+	Create(act auth.Actor, req CreateRequest) (uuid.UUID, error)
+	Patch(act auth.Actor, req PatchRequest) error
+	Delete(act auth.Actor, req DeleteRequest) error
+}
@@ -0,0 +1,63 @@
+package productserve
+
+import (
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/internal/domain/product"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// AuditedService records the mutations performed through the service
+// it wraps. It is meant to be wrapped by an [AuthService], so that
+// only allowed actions are recorded. Failing to record an action fails
+// the request with the error, though the action has been performed by
+// then, so that it is not taken as fully done.
+type AuditedService struct {
+	product.Service
+	recorder audit.Recorder
+}
+
+func NewAudited(service product.Service, recorder audit.Recorder) product.Service {
+	return &AuditedService{
+		Service:  service,
+		recorder: recorder,
+	}
+}
+
+func (s *AuditedService) Create(act auth.Actor, req product.CreateRequest) (uuid.UUID, error) {
+	uuid, err := s.Service.Create(act, req)
+	if err != nil {
+		return uuid, err
+	}
+
+	return uuid, s.recorder.Record(act, "products:create", uuid, nil, s.state(act, uuid))
+}
+
+func (s *AuditedService) Patch(act auth.Actor, req product.PatchRequest) error {
+	before := s.state(act, req.UUID)
+	if err := s.Service.Patch(act, req); err != nil {
+		return err
+	}
+
+	return s.recorder.Record(act, "products:patch", req.UUID, before, s.state(act, req.UUID))
+}
+
+func (s *AuditedService) Delete(act auth.Actor, req product.DeleteRequest) error {
+	before := s.state(act, req.UUID)
+	if err := s.Service.Delete(act, req); err != nil {
+		return err
+	}
+
+	return s.recorder.Record(act, "products:delete", req.UUID, before, nil)
+}
+
+// state returns the product as seen through the service, nil if it
+// cannot be seen.
+func (s *AuditedService) state(act auth.Actor, uuid uuid.UUID) any {
+	res, err := s.Service.Get(act, product.GetRequest{UUID: uuid})
+	if err != nil {
+		return nil
+	}
+
+	return res
+}
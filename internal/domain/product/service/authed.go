@@ -0,0 +1,76 @@
+package productserve
+
+import (
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/product"
+	"github.com/alan-b-lima/almodon/internal/support/service"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type AuthService struct {
+	product.Service
+	policy *auth.Policy
+}
+
+func New(service product.Service, policy *auth.Policy) product.Service {
+	return &AuthService{
+		Service: service,
+		policy:  policy,
+	}
+}
+
+func (s *AuthService) List(act auth.Actor, req product.ListRequest) (product.ListResponse, error) {
+	if err := service.Authorize(s.policy, act, "products:list", auth.Resource{}); err != nil {
+		return product.ListResponse{}, err
+	}
+
+	return s.Service.List(act, req)
+}
+
+func (s *AuthService) Search(act auth.Actor, req product.SearchRequest) (product.SearchResponse, error) {
+	if err := service.Authorize(s.policy, act, "products:search", auth.Resource{}); err != nil {
+		return product.SearchResponse{}, err
+	}
+
+	return s.Service.Search(act, req)
+}
+
+func (s *AuthService) Complete(act auth.Actor, req product.CompleteRequest) (product.CompleteResponse, error) {
+	if err := service.Authorize(s.policy, act, "products:search", auth.Resource{}); err != nil {
+		return product.CompleteResponse{}, err
+	}
+
+	return s.Service.Complete(act, req)
+}
+
+func (s *AuthService) Get(act auth.Actor, req product.GetRequest) (product.Response, error) {
+	if err := service.Authorize(s.policy, act, "products:get", auth.Resource{}); err != nil {
+		return product.Response{}, err
+	}
+
+	return s.Service.Get(act, req)
+}
+
+func (s *AuthService) Create(act auth.Actor, req product.CreateRequest) (uuid.UUID, error) {
+	if err := service.Authorize(s.policy, act, "products:create", auth.Resource{}); err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return s.Service.Create(act, req)
+}
+
+func (s *AuthService) Patch(act auth.Actor, req product.PatchRequest) error {
+	if err := service.Authorize(s.policy, act, "products:patch", auth.Resource{}); err != nil {
+		return err
+	}
+
+	return s.Service.Patch(act, req)
+}
+
+func (s *AuthService) Delete(act auth.Actor, req product.DeleteRequest) error {
+	if err := service.Authorize(s.policy, act, "products:delete", auth.Resource{}); err != nil {
+		return err
+	}
+
+	return s.Service.Delete(act, req)
+}
@@ -0,0 +1,115 @@
+package productserve
+
+import (
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/product"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Service struct {
+	products product.Repository
+}
+
+func NewService(products product.Repository) product.Service {
+	return &Service{
+		products: products,
+	}
+}
+
+func (s *Service) List(act auth.Actor, req product.ListRequest) (product.ListResponse, error) {
+	var filter product.Filter
+	if req.CATMAT != "" {
+		filter.CATMAT = opt.Some(req.CATMAT)
+	}
+
+	if req.SIADS != "" {
+		filter.SIADS = opt.Some(req.SIADS)
+	}
+
+	sort, err := product.Listing.ParseSort(req.Sort...)
+	if err != nil {
+		return product.ListResponse{}, err
+	}
+
+	res, err := product.List(s.products, filter, listing.Query{Sort: sort, After: listing.Cursor(req.Cursor), Limit: req.Limit})
+	if err != nil {
+		return product.ListResponse{}, err
+	}
+
+	lres := product.ListResponse{
+		Length:       len(res.Records),
+		Records:      make([]product.Response, len(res.Records)),
+		TotalRecords: res.TotalRecords,
+		Next:         string(res.Next),
+	}
+	for i := range res.Records {
+		lres.Records[i] = transform(&res.Records[i])
+	}
+
+	return lres, nil
+}
+
+func (s *Service) Search(act auth.Actor, req product.SearchRequest) (product.SearchResponse, error) {
+	res, err := product.Search(s.products, req.Query, req.Limit)
+	if err != nil {
+		return product.SearchResponse{}, err
+	}
+
+	sres := product.SearchResponse{
+		Length:  len(res),
+		Records: make([]product.Response, len(res)),
+	}
+	for i := range res {
+		sres.Records[i] = transform(&res[i])
+	}
+
+	return sres, nil
+}
+
+func (s *Service) Complete(act auth.Actor, req product.CompleteRequest) (product.CompleteResponse, error) {
+	res, err := product.Search(s.products, req.Query, req.Limit)
+	if err != nil {
+		return product.CompleteResponse{}, err
+	}
+
+	cres := product.CompleteResponse{
+		Suggestions: make([]product.SuggestionResponse, len(res)),
+	}
+	for i, e := range res {
+		cres.Suggestions[i] = product.SuggestionResponse{
+			UUID:   e.UUID,
+			Name:   e.Name,
+			CATMAT: e.CATMAT,
+			Unit:   e.Unit,
+		}
+	}
+
+	return cres, nil
+}
+
+func (s *Service) Get(act auth.Actor, req product.GetRequest) (product.Response, error) {
+	res, err := product.Get(s.products, req.UUID)
+	if err != nil {
+		return product.Response{}, err
+	}
+
+	return transform(&res), nil
+}
+
+func (s *Service) Create(act auth.Actor, req product.CreateRequest) (uuid.UUID, error) {
+	return product.Create(s.products, req.Name, req.Description, req.CATMAT, req.SIADS, req.Unit)
+}
+
+func (s *Service) Patch(act auth.Actor, req product.PatchRequest) error {
+	return product.Patch(s.products, req.UUID, req.IfMatch, req.Name, req.Description, req.CATMAT, req.SIADS, req.Unit)
+}
+
+func (s *Service) Delete(act auth.Actor, req product.DeleteRequest) error {
+	return product.Delete(s.products, req.UUID, req.IfMatch)
+}
+
+func transform(e *product.Entity) product.Response {
+	return product.Response(*e)
+}
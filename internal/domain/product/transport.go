@@ -0,0 +1,89 @@
+package product
+
+import (
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type (
+	ListRequest struct {
+		CATMAT string   `query:"catmat"`
+		SIADS  string   `query:"siads"`
+		Sort   []string `query:"sort"`
+		Cursor string   `query:"cursor"`
+		Limit  int      `query:"limit" default:"10"`
+	}
+
+	SearchRequest struct {
+		Query string `query:"q,required"`
+		Limit int    `query:"limit" default:"10"`
+	}
+
+	CompleteRequest struct {
+		Query string `query:"q,required"`
+		Limit int    `query:"limit" default:"5"`
+	}
+
+	GetRequest struct {
+		UUID uuid.UUID `json:"-" path:"uuid"`
+	}
+
+	CreateRequest struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		CATMAT      string `json:"catmat"`
+		SIADS       string `json:"siads"`
+		Unit        string `json:"unit"`
+	}
+
+	PatchRequest struct {
+		UUID        uuid.UUID           `json:"-" path:"uuid"`
+		IfMatch     entity.Precondition `json:"-" header:"If-Match,required"`
+		Name        opt.Opt[string]     `json:"name"`
+		Description opt.Opt[string]     `json:"description"`
+		CATMAT      opt.Opt[string]     `json:"catmat"`
+		SIADS       opt.Opt[string]     `json:"siads"`
+		Unit        opt.Opt[string]     `json:"unit"`
+	}
+
+	DeleteRequest struct {
+		UUID    uuid.UUID           `json:"-" path:"uuid"`
+		IfMatch entity.Precondition `json:"-" header:"If-Match,required"`
+	}
+)
+
+type (
+	ListResponse struct {
+		Length       int        `json:"length"`
+		Records      []Response `json:"records" encode:"rows"`
+		TotalRecords int        `json:"total_records"`
+		Next         string     `json:"next,omitempty" header:"Next-Cursor"`
+	}
+
+	SearchResponse struct {
+		Length  int        `json:"length"`
+		Records []Response `json:"records" encode:"rows"`
+	}
+
+	CompleteResponse struct {
+		Suggestions []SuggestionResponse `json:"suggestions" encode:"rows"`
+	}
+
+	Response struct {
+		UUID        uuid.UUID      `json:"uuid"`
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		CATMAT      string         `json:"catmat"`
+		SIADS       string         `json:"siads"`
+		Unit        string         `json:"unit"`
+		Version     entity.Version `json:"-" header:"ETag"`
+	}
+
+	SuggestionResponse struct {
+		UUID   uuid.UUID `json:"uuid"`
+		Name   string    `json:"name"`
+		CATMAT string    `json:"catmat"`
+		Unit   string    `json:"unit"`
+	}
+)
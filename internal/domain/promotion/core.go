@@ -7,7 +7,7 @@ import (
 	"github.com/alan-b-lima/almodon/pkg/uuid"
 )
 
-const _MaxAge = 1 * 24 * time.Hour
+const DefaultMaxAge = 1 * 24 * time.Hour
 
 func Get(repo Getter, uuid uuid.UUID) (Entity, error) {
 	res, err := repo.Get(uuid)
@@ -22,13 +22,12 @@ func Get(repo Getter, uuid uuid.UUID) (Entity, error) {
 	return res, err
 }
 
-// TODO: verify validity of _MaxAge and turn it to an internal error
 func Create(repo Creater, user uuid.UUID) (uuid.UUID, error) {
-	return CreateWithMaxAge(repo, user, _MaxAge)
+	return CreateWithMaxAge(repo, user, DefaultMaxAge)
 }
 
 func CreateWithMaxAge(repo Creater, user uuid.UUID, maxAge time.Duration) (uuid.UUID, error) {
-	p, err := New(user, _MaxAge)
+	p, err := New(user, maxAge)
 	if err != nil {
 		return uuid.UUID{}, err
 	}
@@ -36,9 +35,8 @@ func CreateWithMaxAge(repo Creater, user uuid.UUID, maxAge time.Duration) (uuid.
 	return p.UUID(), repo.Create(translate(&p))
 }
 
-// TODO: verify validity of _MaxAge and turn it to an internal error
 func Update(repo Updater, uuid uuid.UUID) error {
-	return UpdateWithMaxAge(repo, uuid, _MaxAge)
+	return UpdateWithMaxAge(repo, uuid, DefaultMaxAge)
 }
 
 func UpdateWithMaxAge(repo Updater, uuid uuid.UUID, maxAge time.Duration) error {
@@ -51,9 +51,21 @@ func (s *Promotion) SetMaxAge(maxAge time.Duration) error {
 }
 
 func ProcessMaxAge(maxAge time.Duration) (time.Time, error) {
-	if maxAge > _MaxMaxAge {
-		return time.Time{}, xerrors.ErrPromotionTooLong.New(_MaxMaxAge)
+	if err := ValidateMaxAge(maxAge); err != nil {
+		return time.Time{}, err
 	}
 
 	return time.Now().Add(maxAge), nil
 }
+
+func ValidateMaxAge(maxAge time.Duration) error {
+	if maxAge <= 0 {
+		return xerrors.ErrPromotionTooShort
+	}
+
+	if maxAge > _MaxMaxAge {
+		return xerrors.ErrPromotionTooLong.New(_MaxMaxAge)
+	}
+
+	return nil
+}
@@ -4,10 +4,36 @@ import (
 	"time"
 
 	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
 	"github.com/alan-b-lima/almodon/pkg/uuid"
 )
 
-const _MaxAge = 10 * time.Minute
+// Lifetimes are how long sessions last, according to their level.
+type Lifetimes struct {
+	MaxAge        time.Duration
+	PendingMaxAge time.Duration
+}
+
+var DefaultLifetimes = Lifetimes{
+	MaxAge:        10 * time.Minute,
+	PendingMaxAge: 5 * time.Minute,
+}
+
+// Of returns the lifetime of sessions of the given level.
+func (l Lifetimes) Of(level Level) time.Duration {
+	if level == Pending {
+		return l.PendingMaxAge
+	}
+
+	return l.MaxAge
+}
+
+func (l Lifetimes) Validate() error {
+	return errors.Join(
+		ValidateMaxAge(l.MaxAge),
+		ValidateMaxAge(l.PendingMaxAge),
+	)
+}
 
 func Get(repo Getter, uuid uuid.UUID) (Entity, error) {
 	res, err := repo.Get(uuid)
@@ -22,29 +48,28 @@ func Get(repo Getter, uuid uuid.UUID) (Entity, error) {
 	return res, nil
 }
 
-// TODO: verify validity of [_MaxAge] and turn it to an internal error
-func CreateAndGet(repo Creater, user uuid.UUID) (Entity, error) {
-	return CreateAndGetWithMaxAge(repo, user, _MaxAge)
+func CreateAndGet(repo Creater, lifetimes Lifetimes, user uuid.UUID, level Level) (Entity, error) {
+	return CreateAndGetWithMaxAge(repo, user, level, lifetimes.Of(level))
 }
 
-func CreateAndGetWithMaxAge(repo Creater, user uuid.UUID, maxAge time.Duration) (Entity, error) {
-	s, err := New(user, maxAge)
+func CreateAndGetWithMaxAge(repo Creater, user uuid.UUID, level Level, maxAge time.Duration) (Entity, error) {
+	s, err := New(user, level, maxAge)
 	if err != nil {
 		return Entity{}, err
 	}
 
 	session := Entity{
-		s.UUID(),
-		s.User(),
-		s.Expires(),
+		UUID:    s.UUID(),
+		User:    s.User(),
+		Level:   s.Level(),
+		Expires: s.Expires(),
 	}
 
 	return session, repo.Create(session)
 }
 
-// TODO: verify validity of _MaxAge and turn it to an internal error
-func Update(repo Updater, uuid uuid.UUID) error {
-	return UpdateWithMaxAge(repo, uuid, _MaxAge)
+func Update(repo Updater, lifetimes Lifetimes, uuid uuid.UUID) error {
+	return UpdateWithMaxAge(repo, uuid, lifetimes.MaxAge)
 }
 
 func UpdateWithMaxAge(repo Updater, uuid uuid.UUID, maxAge time.Duration) error {
@@ -53,3 +78,41 @@ func UpdateWithMaxAge(repo Updater, uuid uuid.UUID, maxAge time.Duration) error
 
 	return repo.Update(uuid, s.Expires())
 }
+
+// _MaxFailures is how many times a pending session may fail to be
+// completed before it is deleted.
+const _MaxFailures = 5
+
+// Fail counts a failed attempt at completing the pending session,
+// deleting it once it has failed too many times, so that its user has
+// to authenticate again.
+func Fail(repo interface {
+	Failer
+	Deleter
+}, uuid uuid.UUID) error {
+	failures, err := repo.Fail(uuid)
+	if err != nil {
+		return err
+	}
+
+	if failures >= _MaxFailures {
+		return repo.Delete(uuid)
+	}
+
+	return nil
+}
+
+// Revoke deletes the session of the user, logging them out.
+func Revoke(repo DeleterByUser, user uuid.UUID) error {
+	return repo.DeleteByUser(user)
+}
+
+// Count returns how many sessions have not expired.
+func Count(repo Counter) (int, error) {
+	return repo.Count(time.Now())
+}
+
+// Purge deletes every expired session, returning how many there were.
+func Purge(repo Purger) (int, error) {
+	return repo.Purge(time.Now())
+}
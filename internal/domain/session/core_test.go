@@ -0,0 +1,35 @@
+package session_test
+
+import (
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/internal/domain/session"
+	sessionrepo "github.com/alan-b-lima/almodon/internal/domain/session/repository"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+func TestFail(t *testing.T) {
+	repo := sessionrepo.NewMap()
+	defer repo.(interface{ Close() error }).Close()
+
+	res, err := CreateAndGet(repo, DefaultLifetimes, uuid.NewUUIDv7(), Pending)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Tests struct {
+		exists bool
+	}
+
+	tests := []Tests{{true}, {true}, {true}, {true}, {false}}
+
+	for i, test := range tests {
+		if err := Fail(repo, res.UUID); err != nil {
+			t.Fatalf("failure %d: did not expect error, but got: %v", i+1, err)
+		}
+
+		if _, err := Get(repo, res.UUID); (err == nil) != test.exists {
+			t.Errorf("failure %d: expected the session to exist to be %t, got %v", i+1, test.exists, err)
+		}
+	}
+}
@@ -10,17 +10,37 @@ import (
 
 const _MaxMaxAge = 7 * 24 * time.Hour
 
+// Level is the assurance level of a session, that is, which factors
+// of authentication were presented when the session was created.
+type Level uint8
+
+const (
+	// SingleFactor is a session authenticated by password alone.
+	SingleFactor Level = iota
+
+	// MultiFactor is a session authenticated by password and a
+	// second factor.
+	MultiFactor
+
+	// Pending is a session whose password was accepted, but whose
+	// second factor is still awaited. It does not authenticate its
+	// user.
+	Pending
+)
+
 type Session struct {
 	uuid    uuid.UUID
 	user    uuid.UUID
+	level   Level
 	expires time.Time
 }
 
-func New(user uuid.UUID, maxAge time.Duration) (Session, error) {
+func New(user uuid.UUID, level Level, maxAge time.Duration) (Session, error) {
 	session := Session{}
 
 	err := errors.Join(
 		session.setUser(user),
+		session.setLevel(level),
 		session.SetMaxAge(maxAge),
 	)
 	if err != nil {
@@ -33,6 +53,7 @@ func New(user uuid.UUID, maxAge time.Duration) (Session, error) {
 
 func (s *Session) UUID() uuid.UUID    { return s.uuid }
 func (s *Session) User() uuid.UUID    { return s.user }
+func (s *Session) Level() Level       { return s.level }
 func (s *Session) Expires() time.Time { return s.expires }
 
 func (s *Session) setUser(uuid uuid.UUID) error {
@@ -40,11 +61,32 @@ func (s *Session) setUser(uuid uuid.UUID) error {
 	return nil
 }
 
+func (s *Session) setLevel(level Level) error {
+	if level > Pending {
+		return xerrors.ErrSessionLevelInvalid
+	}
+
+	s.level = level
+	return nil
+}
+
 func (s *Session) SetMaxAge(maxAge time.Duration) error {
+	if err := ValidateMaxAge(maxAge); err != nil {
+		return err
+	}
+
+	s.expires = time.Now().Add(maxAge)
+	return nil
+}
+
+func ValidateMaxAge(maxAge time.Duration) error {
+	if maxAge <= 0 {
+		return xerrors.ErrSessionTooShort
+	}
+
 	if maxAge > _MaxMaxAge {
 		return xerrors.ErrSessionTooLong.New(_MaxMaxAge)
 	}
 
-	s.expires = time.Now().Add(maxAge)
 	return nil
 }
@@ -10,7 +10,11 @@ type Repository interface {
 	Getter
 	Creater
 	Updater
+	Failer
 	Deleter
+	DeleterByUser
+	Purger
+	Counter
 }
 
 type (
@@ -26,15 +30,35 @@ type (
 		Update(uuid.UUID, time.Time) error
 	}
 
+	// Failer counts a failed attempt at completing the session,
+	// returning how many there have been.
+	Failer interface {
+		Fail(uuid.UUID) (int, error)
+	}
+
 	Deleter interface {
 		Delete(uuid.UUID) error
 	}
+
+	DeleterByUser interface {
+		DeleteByUser(uuid.UUID) error
+	}
+
+	Purger interface {
+		Purge(now time.Time) (int, error)
+	}
+
+	Counter interface {
+		Count(now time.Time) (int, error)
+	}
 )
 
 type (
 	Entity struct {
-		UUID    uuid.UUID
-		User    uuid.UUID
-		Expires time.Time
+		UUID     uuid.UUID
+		User     uuid.UUID
+		Level    Level
+		Expires  time.Time
+		Failures int
 	}
 )
@@ -1,6 +1,8 @@
 package sessionrepo
 
 import (
+	"encoding/json"
+	"os"
 	"sync"
 	"time"
 
@@ -17,21 +19,108 @@ type Map struct {
 
 	repo []session.Entity
 	mu   sync.RWMutex
+
+	datapath string
+	once     sync.Once
 }
 
 func NewMap() session.Repository {
-	repo := Map{
+	repo := newMap("")
+
+	go flush(repo)
+
+	return repo
+}
+
+func NewPersistantMap(datapath string) (session.Repository, error) {
+	repo := newMap(datapath)
+
+	if err := repo.init(); err != nil {
+		return nil, err
+	}
+
+	go flush(repo)
+
+	return repo, nil
+}
+
+func newMap(datapath string) *Map {
+	return &Map{
 		uuidIndex: make(map[uuid.UUID]int),
 		userIndex: make(map[uuid.UUID]int),
 		expiresHeap: sleepqueue{
 			new:    make(chan ess, 64),
-			cancel: make(chan struct{}, 1),
+			cancel: make(chan struct{}),
 		},
+		datapath: datapath,
 	}
+}
 
-	go flush(&repo)
+func (m *Map) init() error {
+	f, err := os.Open(m.datapath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var repo []entity
+	if err := json.NewDecoder(f).Decode(&repo); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, record := range repo {
+		if !record.Expires.After(now) {
+			continue
+		}
+
+		m.uuidIndex[record.UUID] = len(m.repo)
+		m.userIndex[record.User] = len(m.repo)
+		m.repo = append(m.repo, entity_from_json(record))
 
-	return &repo
+		// flush is not running yet, so the heap is filled directly
+		m.expiresHeap.heap.Push(ess{record.UUID, record.Expires})
+	}
+
+	return nil
+}
+
+func (m *Map) Close() error {
+	m.once.Do(func() { close(m.expiresHeap.cancel) })
+
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	repo := make([]entity, len(m.repo))
+	for i, record := range m.repo {
+		repo[i] = json_for_entity(record)
+	}
+
+	return json.NewEncoder(f).Encode(repo)
+}
+
+// Ping reports whether the data file can still be written.
+func (m *Map) Ping() error {
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
 }
 
 func (m *Map) Get(uuid uuid.UUID) (session.Entity, error) {
@@ -43,13 +132,14 @@ func (m *Map) Get(uuid uuid.UUID) (session.Entity, error) {
 		return session.Entity{}, xerrors.ErrSessionNotFound
 	}
 
+	// expired sessions are left for flush to delete, as the lock held
+	// does not allow it here
 	s := m.repo[index]
 	if time.Now().After(s.Expires) {
-		m.delete(s.UUID)
 		return session.Entity{}, xerrors.ErrSessionNotFound
 	}
 
-	return m.repo[index], nil
+	return s, nil
 }
 
 func (m *Map) Create(session session.Entity) error {
@@ -87,6 +177,21 @@ func (m *Map) Update(uuid uuid.UUID, expires time.Time) error {
 	return nil
 }
 
+func (m *Map) Fail(uuid uuid.UUID) (int, error) {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.uuidIndex[uuid]
+	if !in {
+		return 0, xerrors.ErrSessionNotFound
+	}
+
+	s := &m.repo[index]
+	s.Failures++
+
+	return s.Failures, nil
+}
+
 func (m *Map) Delete(uuid uuid.UUID) error {
 	defer m.mu.Unlock()
 	m.mu.Lock()
@@ -94,6 +199,47 @@ func (m *Map) Delete(uuid uuid.UUID) error {
 	return m.delete(uuid)
 }
 
+func (m *Map) DeleteByUser(user uuid.UUID) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.userIndex[user]
+	if !in {
+		return xerrors.ErrSessionNotFound
+	}
+
+	return m.delete(m.repo[index].UUID)
+}
+
+func (m *Map) Purge(now time.Time) (int, error) {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	var count int
+	for i := len(m.repo) - 1; i >= 0; i-- {
+		if s := m.repo[i]; !s.Expires.After(now) {
+			m.delete(s.UUID)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m *Map) Count(now time.Time) (int, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	var count int
+	for _, s := range m.repo {
+		if s.Expires.After(now) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
 func (m *Map) delete(uuid uuid.UUID) error {
 	index, in := m.uuidIndex[uuid]
 	if !in {
@@ -105,11 +251,31 @@ func (m *Map) delete(uuid uuid.UUID) error {
 	delete(m.uuidIndex, s.UUID)
 	delete(m.userIndex, s.User)
 
-	m.repo[index] = m.repo[len(m.repo)-1]
-	m.repo = m.repo[:len(m.repo)-1]
+	last := len(m.repo) - 1
+	if index != last {
+		m.repo[index] = m.repo[last]
+		m.uuidIndex[m.repo[index].UUID] = index
+		m.userIndex[m.repo[index].User] = index
+	}
+	m.repo = m.repo[:last]
+
 	return nil
 }
 
+// expire deletes the session if it has expired by now, as it may
+// have been extended since it was scheduled.
+func (m *Map) expire(uuid uuid.UUID, now time.Time) {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.uuidIndex[uuid]
+	if !in || m.repo[index].Expires.After(now) {
+		return
+	}
+
+	m.delete(uuid)
+}
+
 func flush(m *Map) {
 	h := m.expiresHeap
 
@@ -127,9 +293,9 @@ func flush(m *Map) {
 		case es := <-h.new:
 			h.heap.Push(es)
 
-		case <-after:
+		case now := <-after:
 			es := h.heap.Pop()
-			m.Delete(es.session)
+			m.expire(es.session, now)
 		}
 	}
 }
@@ -146,3 +312,31 @@ type ess struct {
 }
 
 func (o0 ess) Less(o1 ess) bool { return o0.expires.Before(o1.expires) }
+
+type entity struct {
+	UUID     uuid.UUID `json:"uuid"`
+	User     uuid.UUID `json:"user"`
+	Level    uint8     `json:"level"`
+	Expires  time.Time `json:"expires"`
+	Failures int       `json:"failures,omitempty"`
+}
+
+func json_for_entity(e session.Entity) entity {
+	return entity{
+		UUID:     e.UUID,
+		User:     e.User,
+		Level:    uint8(e.Level),
+		Expires:  e.Expires,
+		Failures: e.Failures,
+	}
+}
+
+func entity_from_json(e entity) session.Entity {
+	return session.Entity{
+		UUID:     e.UUID,
+		User:     e.User,
+		Level:    session.Level(e.Level),
+		Expires:  e.Expires,
+		Failures: e.Failures,
+	}
+}
@@ -0,0 +1,82 @@
+package token
+
+import (
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+func List(repo ListerByUser, user uuid.UUID, q listing.Query) (Entities, error) {
+	return repo.ListByUser(user, q)
+}
+
+// Create creates a token for the user, whose role is capped at the
+// given role. The secret is returned once and never stored.
+func Create(repo Creater, user uuid.UUID, role auth.Role, name string, scopes []string, expires opt.Opt[time.Time]) (Entity, string, error) {
+	t, err := New(user, role, name, scopes, expires)
+	if err != nil {
+		return Entity{}, "", err
+	}
+
+	res := translate(&t)
+	return res, t.Secret(), repo.Create(res)
+}
+
+// Delete deletes a token of the user. Tokens of other users are
+// reported as not found, so that their existence is not disclosed.
+func Delete(repo interface {
+	Getter
+	Deleter
+}, user uuid.UUID, uuid uuid.UUID) error {
+	res, err := repo.Get(uuid)
+	if err != nil {
+		return err
+	}
+
+	if res.User != user {
+		return xerrors.ErrTokenNotFound
+	}
+
+	return repo.Delete(uuid)
+}
+
+// Resolve finds the token of the given secret, refusing expired
+// ones, and records its use.
+func Resolve(repo interface {
+	GetterByDigest
+	Toucher
+}, secret string) (Entity, error) {
+	res, err := repo.GetByDigest(Digest(secret))
+	if err != nil {
+		return Entity{}, xerrors.ErrTokenInvalid
+	}
+
+	now := time.Now()
+	if expires, ok := res.Expires.Unwrap(); ok && now.After(expires) {
+		return Entity{}, xerrors.ErrTokenInvalid
+	}
+
+	if err := repo.Touch(res.UUID, now); err != nil {
+		return Entity{}, err
+	}
+	res.LastUsed = opt.Some(now)
+
+	return res, nil
+}
+
+func translate(t *Token) Entity {
+	return Entity{
+		UUID:    t.UUID(),
+		User:    t.User(),
+		Name:    t.Name(),
+		Digest:  t.Digest(),
+		Scopes:  t.Scopes(),
+		Role:    t.Role(),
+		Expires: t.Expires(),
+		Created: t.Created(),
+	}
+}
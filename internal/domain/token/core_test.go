@@ -0,0 +1,137 @@
+package token_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	. "github.com/alan-b-lima/almodon/internal/domain/token"
+	tokenrepo "github.com/alan-b-lima/almodon/internal/domain/token/repository"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+func title(err error) string {
+	if err, ok := errors.AsType[*errors.Error](err); ok {
+		return err.Title
+	}
+
+	return ""
+}
+
+func TestCreate(t *testing.T) {
+	repo := tokenrepo.NewMap()
+	user := uuid.NewUUIDv7()
+
+	res, secret, err := Create(repo, user, auth.User, "ci", []string{"products:*"}, opt.None[time.Time]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(secret, Prefix) || len(secret) <= len(Prefix) {
+		t.Errorf("expected the secret to start with %q, got %q", Prefix, secret)
+	}
+
+	if res.Digest != Digest(secret) {
+		t.Errorf("expected the token to be stored under the digest of its secret")
+	}
+
+	stored, err := repo.GetByDigest(Digest(secret))
+	if err != nil || stored.UUID != res.UUID {
+		t.Fatalf("expected the token to be found by its digest, got %+v, %v", stored, err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	repo := tokenrepo.NewMap()
+	user := uuid.NewUUIDv7()
+
+	live, secret, err := Create(repo, user, auth.User, "live", []string{"*"}, opt.Some(time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tokens cannot be created already expired, so one is stored as if
+	// it had been created a while ago
+	const expired = Prefix + "expired"
+	err = repo.Create(Entity{
+		UUID:    uuid.NewUUIDv7(),
+		User:    user,
+		Name:    "expired",
+		Digest:  Digest(expired),
+		Scopes:  []auth.Scope{auth.Everything},
+		Role:    auth.User,
+		Expires: opt.Some(time.Now().Add(-time.Minute)),
+		Created: time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Tests struct {
+		secret string
+		title  string
+	}
+
+	tests := []Tests{
+		{secret, ""},
+		{expired, "token-invalid"},
+		{secret + "x", "token-invalid"},
+		{strings.TrimPrefix(secret, Prefix), "token-invalid"},
+		{"", "token-invalid"},
+	}
+
+	for _, test := range tests {
+		res, err := Resolve(repo, test.secret)
+		if title(err) != test.title || (test.title == "" && err != nil) {
+			t.Errorf("%q: expected %q, got %v", test.secret, test.title, err)
+			continue
+		}
+
+		if test.title != "" {
+			continue
+		}
+
+		if res.UUID != live.UUID {
+			t.Errorf("%q: expected the token %v, got %v", test.secret, live.UUID, res.UUID)
+		}
+
+		stored, _ := repo.Get(live.UUID)
+		if _, ok := stored.LastUsed.Unwrap(); !ok {
+			t.Errorf("%q: expected the use of the token to be recorded", test.secret)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	repo := tokenrepo.NewMap()
+	owner, other := uuid.NewUUIDv7(), uuid.NewUUIDv7()
+
+	res, secret, err := Create(repo, owner, auth.User, "ci", []string{"*"}, opt.None[time.Time]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Tests struct {
+		user  uuid.UUID
+		title string
+	}
+
+	tests := []Tests{
+		{other, "token-not-found"},
+		{owner, ""},
+		{owner, "token-not-found"},
+	}
+
+	for i, test := range tests {
+		if err := Delete(repo, test.user, res.UUID); title(err) != test.title || (test.title == "" && err != nil) {
+			t.Errorf("%d: expected %q, got %v", i, test.title, err)
+		}
+	}
+
+	if _, err := Resolve(repo, secret); title(err) != "token-invalid" {
+		t.Errorf("expected a revoked token to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+	"github.com/alan-b-lima/almodon/pkg/validate"
+)
+
+const (
+	// Prefix identifies the personal access tokens of the system, so
+	// that they are easily recognizable by secret scanners.
+	Prefix = "almpat_"
+
+	_MaxNameLength = 64
+	_MaxMaxAge     = 366 * 24 * time.Hour
+)
+
+type Token struct {
+	uuid    uuid.UUID
+	user    uuid.UUID
+	name    string
+	secret  string
+	scopes  []auth.Scope
+	role    auth.Role
+	expires opt.Opt[time.Time]
+	created time.Time
+}
+
+func New(user uuid.UUID, role auth.Role, name string, scopes []string, expires opt.Opt[time.Time]) (Token, error) {
+	t := Token{created: time.Now()}
+
+	err := errors.Join(
+		t.setUser(user),
+		t.setRole(role),
+		validate.At("/name", t.SetName(name)),
+		validate.At("/scopes", t.SetScopes(scopes)),
+		validate.At("/expires", t.SetExpires(expires)),
+	)
+	if err != nil {
+		return Token{}, xerrors.ErrTokenCreation.New(err)
+	}
+
+	var buf [32]byte
+	rand.Read(buf[:])
+
+	t.uuid = uuid.NewUUIDv7()
+	t.secret = Prefix + base64.RawURLEncoding.EncodeToString(buf[:])
+	return t, nil
+}
+
+func (t *Token) UUID() uuid.UUID             { return t.uuid }
+func (t *Token) User() uuid.UUID             { return t.user }
+func (t *Token) Name() string                { return t.name }
+func (t *Token) Secret() string              { return t.secret }
+func (t *Token) Digest() [32]byte            { return Digest(t.secret) }
+func (t *Token) Scopes() []auth.Scope        { return t.scopes }
+func (t *Token) Role() auth.Role             { return t.role }
+func (t *Token) Expires() opt.Opt[time.Time] { return t.expires }
+func (t *Token) Created() time.Time          { return t.created }
+
+func (t *Token) SetName(name string) error       { return entity.Set(&t.name, name, ProcessName) }
+func (t *Token) SetScopes(scopes []string) error { return entity.Set(&t.scopes, scopes, ProcessScopes) }
+
+func (t *Token) SetExpires(expires opt.Opt[time.Time]) error {
+	return entity.Set(&t.expires, expires, ProcessExpires)
+}
+
+func (t *Token) setUser(user uuid.UUID) error {
+	t.user = user
+	return nil
+}
+
+func (t *Token) setRole(role auth.Role) error {
+	if !role.IsValid() {
+		return xerrors.ErrUnauthenticatedUser.New(nil)
+	}
+
+	t.role = role
+	return nil
+}
+
+// Digest returns the digest under which a token is stored. Tokens
+// have enough entropy for a fast hash to be safe, and a fast hash is
+// needed, since tokens are checked on every request.
+func Digest(secret string) [32]byte {
+	return sha256.Sum256([]byte(secret))
+}
+
+func ProcessName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", xerrors.ErrNameEmpty
+	}
+
+	if utf8.RuneCountInString(name) > _MaxNameLength {
+		return "", xerrors.ErrTokenNameTooLong.New(_MaxNameLength)
+	}
+
+	return name, nil
+}
+
+func ProcessScopes(scopes []string) ([]auth.Scope, error) {
+	if len(scopes) == 0 {
+		return nil, xerrors.ErrTokenNoScopes
+	}
+
+	res := make([]auth.Scope, 0, len(scopes))
+	for _, str := range scopes {
+		scope, ok := auth.ParseScope(str)
+		if !ok {
+			return nil, xerrors.ErrScopeInvalid.New(str)
+		}
+
+		res = append(res, scope)
+	}
+
+	return res, nil
+}
+
+func ProcessExpires(expires opt.Opt[time.Time]) (opt.Opt[time.Time], error) {
+	val, ok := expires.Unwrap()
+	if !ok {
+		return expires, nil
+	}
+
+	if !val.After(time.Now()) {
+		return opt.None[time.Time](), xerrors.ErrTokenExpiresInPast
+	}
+
+	if time.Until(val) > _MaxMaxAge {
+		return opt.None[time.Time](), xerrors.ErrTokenTooLong.New(_MaxMaxAge)
+	}
+
+	return expires, nil
+}
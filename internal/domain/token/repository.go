@@ -0,0 +1,79 @@
+package token
+
+import (
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Repository interface {
+	ListerByUser
+	Getter
+	GetterByDigest
+	Creater
+	Toucher
+	Deleter
+}
+
+type (
+	ListerByUser interface {
+		ListByUser(user uuid.UUID, q listing.Query) (Entities, error)
+	}
+
+	Getter interface {
+		Get(uuid.UUID) (Entity, error)
+	}
+
+	GetterByDigest interface {
+		GetByDigest([32]byte) (Entity, error)
+	}
+
+	Creater interface {
+		Create(Entity) error
+	}
+
+	Toucher interface {
+		Touch(uuid.UUID, time.Time) error
+	}
+
+	Deleter interface {
+		Delete(uuid.UUID) error
+	}
+)
+
+type (
+	Entities = listing.Page[Entity]
+
+	Entity struct {
+		UUID     uuid.UUID
+		User     uuid.UUID
+		Name     string
+		Digest   [32]byte
+		Scopes   []auth.Scope
+		Role     auth.Role
+		Expires  opt.Opt[time.Time]
+		Created  time.Time
+		LastUsed opt.Opt[time.Time]
+	}
+)
+
+// Listing describes the fields tokens may be sorted by, tokens never
+// used are sorted as if last used at the Unix epoch.
+var Listing = listing.Schema[Entity]{
+	ID: func(e *Entity) uuid.UUID { return e.UUID },
+	Fields: map[string]func(*Entity) listing.Key{
+		"name":    func(e *Entity) listing.Key { return listing.String(e.Name) },
+		"created": func(e *Entity) listing.Key { return listing.Time(e.Created) },
+		"last_used": func(e *Entity) listing.Key {
+			used, ok := e.LastUsed.Unwrap()
+			if !ok {
+				return listing.Int(0)
+			}
+
+			return listing.Time(used)
+		},
+	},
+}
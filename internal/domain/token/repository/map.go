@@ -0,0 +1,228 @@
+package tokenrepo
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Map struct {
+	uuidIndex   map[uuid.UUID]int
+	digestIndex map[[32]byte]int
+
+	repo []token.Entity
+	mu   sync.RWMutex
+
+	datapath string
+}
+
+func NewMap() token.Repository {
+	repo := Map{
+		uuidIndex:   make(map[uuid.UUID]int),
+		digestIndex: make(map[[32]byte]int),
+	}
+
+	return &repo
+}
+
+func NewPersistantMap(datapath string) (token.Repository, error) {
+	repo := Map{
+		uuidIndex:   make(map[uuid.UUID]int),
+		digestIndex: make(map[[32]byte]int),
+		datapath:    datapath,
+	}
+
+	if err := repo.init(); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+func (m *Map) init() error {
+	f, err := os.Open(m.datapath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var repo []entity
+	if err := json.NewDecoder(f).Decode(&repo); err != nil {
+		return err
+	}
+
+	m.repo = make([]token.Entity, len(repo))
+	for i, record := range repo {
+		m.repo[i] = entity_from_json(record)
+		m.uuidIndex[m.repo[i].UUID] = i
+		m.digestIndex[m.repo[i].Digest] = i
+	}
+
+	return nil
+}
+
+func (m *Map) Close() error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	repo := make([]entity, len(m.repo))
+	for i, record := range m.repo {
+		repo[i] = json_for_entity(record)
+	}
+
+	return json.NewEncoder(f).Encode(repo)
+}
+
+// Ping reports whether the data file can still be written.
+func (m *Map) Ping() error {
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func (m *Map) ListByUser(user uuid.UUID, q listing.Query) (token.Entities, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	return token.Listing.List(m.repo, func(e *token.Entity) bool { return e.User == user }, q)
+}
+
+func (m *Map) Get(uuid uuid.UUID) (token.Entity, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	index, in := m.uuidIndex[uuid]
+	if !in {
+		return token.Entity{}, xerrors.ErrTokenNotFound
+	}
+
+	return m.repo[index], nil
+}
+
+func (m *Map) GetByDigest(digest [32]byte) (token.Entity, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	index, in := m.digestIndex[digest]
+	if !in {
+		return token.Entity{}, xerrors.ErrTokenNotFound
+	}
+
+	return m.repo[index], nil
+}
+
+func (m *Map) Create(t token.Entity) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	m.uuidIndex[t.UUID] = len(m.repo)
+	m.digestIndex[t.Digest] = len(m.repo)
+	m.repo = append(m.repo, t)
+
+	return nil
+}
+
+func (m *Map) Touch(uuid uuid.UUID, used time.Time) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.uuidIndex[uuid]
+	if !in {
+		return xerrors.ErrTokenNotFound
+	}
+
+	m.repo[index].LastUsed = opt.Some(used)
+	return nil
+}
+
+func (m *Map) Delete(uuid uuid.UUID) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.uuidIndex[uuid]
+	if !in {
+		return xerrors.ErrTokenNotFound
+	}
+
+	delete(m.uuidIndex, uuid)
+	delete(m.digestIndex, m.repo[index].Digest)
+
+	last := len(m.repo) - 1
+	if index != last {
+		m.repo[index] = m.repo[last]
+		m.uuidIndex[m.repo[index].UUID] = index
+		m.digestIndex[m.repo[index].Digest] = index
+	}
+	m.repo = m.repo[:last]
+
+	return nil
+}
+
+type entity struct {
+	UUID     uuid.UUID          `json:"uuid"`
+	User     uuid.UUID          `json:"user"`
+	Name     string             `json:"name"`
+	Digest   []byte             `json:"digest"`
+	Scopes   []auth.Scope       `json:"scopes"`
+	Role     string             `json:"role"`
+	Expires  opt.Opt[time.Time] `json:"expires"`
+	Created  time.Time          `json:"created"`
+	LastUsed opt.Opt[time.Time] `json:"last_used"`
+}
+
+func json_for_entity(e token.Entity) entity {
+	return entity{
+		UUID:     e.UUID,
+		User:     e.User,
+		Name:     e.Name,
+		Digest:   e.Digest[:],
+		Scopes:   e.Scopes,
+		Role:     e.Role.String(),
+		Expires:  e.Expires,
+		Created:  e.Created,
+		LastUsed: e.LastUsed,
+	}
+}
+
+func entity_from_json(e entity) token.Entity {
+	role, _ := auth.FromString(e.Role)
+
+	t := token.Entity{
+		UUID:     e.UUID,
+		User:     e.User,
+		Name:     e.Name,
+		Scopes:   e.Scopes,
+		Role:     role,
+		Expires:  e.Expires,
+		Created:  e.Created,
+		LastUsed: e.LastUsed,
+	}
+	copy(t.Digest[:], e.Digest)
+
+	return t
+}
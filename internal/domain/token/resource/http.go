@@ -0,0 +1,32 @@
+package tokens
+
+import (
+	"net/http"
+
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/support/resource"
+)
+
+type Resource struct {
+	http.ServeMux
+	Tokens     token.Service
+	Gatekeeper user.Gatekeeper
+}
+
+func New(tokens token.Service, gatekeeper user.Gatekeeper) http.Handler {
+	rc := Resource{Tokens: tokens, Gatekeeper: gatekeeper}
+
+	handlers := map[string]http.HandlerFunc{
+		"listTokens":  resource.Endpoint(gatekeeper, http.StatusOK, tokens.List),
+		"createToken": resource.Endpoint(gatekeeper, http.StatusCreated, tokens.Create),
+		"deleteToken": resource.Action(gatekeeper, tokens.Delete),
+	}
+
+	for _, route := range Routes {
+		rc.Handle(route.Pattern, resource.Routed(handlers[route.ID]))
+	}
+	rc.Handle("/", resource.Routed(http.HandlerFunc(resource.NotFound)))
+
+	return &rc
+}
@@ -0,0 +1,19 @@
+package tokens
+
+import (
+	"net/http"
+
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	"github.com/alan-b-lima/almodon/pkg/openapi"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// Routes describes the routes of the resource, under whose patterns
+// [New] registers the handlers of their operations, by ID.
+var Routes = []openapi.Route{
+	{Pattern: "GET /tokens/{$}", ID: "listTokens", Summary: "Lists the tokens of the logged in user", Tags: _Tags, Query: token.ListRequest{}, Response: token.ListResponse{}},
+	{Pattern: "POST /tokens/{$}", ID: "createToken", Summary: "Creates a token, whose secret is only ever shown here", Tags: _Tags, Body: token.CreateRequest{}, Status: http.StatusCreated, Response: token.CreateResponse{}},
+	{Pattern: "DELETE /tokens/{uuid}", ID: "deleteToken", Summary: "Revokes a token", Tags: _Tags, Params: map[string]any{"uuid": uuid.UUID{}}},
+}
+
+var _Tags = []string{"tokens"}
@@ -0,0 +1,9 @@
+package token
+
+import "github.com/alan-b-lima/almodon/internal/auth"
+
+type Service interface {
+	List(act auth.Actor, req ListRequest) (ListResponse, error)
+	Create(act auth.Actor, req CreateRequest) (CreateResponse, error)
+	Delete(act auth.Actor, req DeleteRequest) error
+}
@@ -0,0 +1,49 @@
+package tokenserve
+
+import (
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+)
+
+// AuditedService records the mutations performed through the service
+// it wraps. It is meant to be wrapped by an [AuthService], so that
+// only allowed actions are recorded. Failing to record an action fails
+// the request with the error, though the action has been performed by
+// then, so that it is not taken as fully done.
+type AuditedService struct {
+	token.Service
+	tokens   token.Getter
+	recorder audit.Recorder
+}
+
+func NewAudited(service token.Service, tokens token.Getter, recorder audit.Recorder) token.Service {
+	return &AuditedService{
+		Service:  service,
+		tokens:   tokens,
+		recorder: recorder,
+	}
+}
+
+func (s *AuditedService) Create(act auth.Actor, req token.CreateRequest) (token.CreateResponse, error) {
+	res, err := s.Service.Create(act, req)
+	if err != nil {
+		return res, err
+	}
+
+	// the secret is left out, only the token metadata is recorded
+	return res, s.recorder.Record(act, "tokens:create", res.UUID, nil, res.Response)
+}
+
+func (s *AuditedService) Delete(act auth.Actor, req token.DeleteRequest) error {
+	var before any
+	if res, err := s.tokens.Get(req.UUID); err == nil {
+		before = transform(&res)
+	}
+
+	if err := s.Service.Delete(act, req); err != nil {
+		return err
+	}
+
+	return s.recorder.Record(act, "tokens:delete", req.UUID, before, nil)
+}
@@ -0,0 +1,64 @@
+package tokenserve_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	tokenrepo "github.com/alan-b-lima/almodon/internal/domain/token/repository"
+	. "github.com/alan-b-lima/almodon/internal/domain/token/service"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// recorder fails to record anything once failing.
+type recorder struct {
+	failing bool
+	actions []string
+}
+
+func (r *recorder) Record(act auth.Actor, action string, target uuid.UUID, before, after any) error {
+	if r.failing {
+		return errors.New("disk full")
+	}
+
+	r.actions = append(r.actions, action)
+	return nil
+}
+
+func TestAuditedRecordError(t *testing.T) {
+	repo := tokenrepo.NewMap()
+
+	var rec recorder
+	tokens := NewAudited(NewService(repo), repo, &rec)
+
+	act := auth.NewLogged(uuid.NewUUIDv7(), auth.User)
+
+	type Tests struct {
+		failing bool
+		err     bool
+	}
+
+	tests := []Tests{
+		{false, false},
+		{true, true},
+	}
+
+	for i, test := range tests {
+		rec.failing = test.failing
+
+		res, err := tokens.Create(act, token.CreateRequest{Name: "ci", Scopes: []string{"*"}})
+		if (err != nil) != test.err {
+			t.Errorf("%d: create: expected an error to be %t, got %v", i, test.err, err)
+		}
+
+		err = tokens.Delete(act, token.DeleteRequest{UUID: res.UUID})
+		if (err != nil) != test.err {
+			t.Errorf("%d: delete: expected an error to be %t, got %v", i, test.err, err)
+		}
+	}
+
+	if len(rec.actions) != 2 {
+		t.Errorf("expected the create and delete to be recorded once, got %v", rec.actions)
+	}
+}
@@ -0,0 +1,64 @@
+package tokenserve
+
+import (
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	"github.com/alan-b-lima/almodon/internal/support/service"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+)
+
+type AuthService struct {
+	token.Service
+	policy *auth.Policy
+}
+
+func New(service token.Service, policy *auth.Policy) token.Service {
+	return &AuthService{
+		Service: service,
+		policy:  policy,
+	}
+}
+
+func (s *AuthService) List(act auth.Actor, req token.ListRequest) (token.ListResponse, error) {
+	if err := service.Authorize(s.policy, act, "tokens:list", auth.Resource{Subject: act.User()}); err != nil {
+		return token.ListResponse{}, err
+	}
+
+	return s.Service.List(act, req)
+}
+
+func (s *AuthService) Create(act auth.Actor, req token.CreateRequest) (token.CreateResponse, error) {
+	if err := service.Authorize(s.policy, act, "tokens:create", auth.Resource{Subject: act.User()}); err != nil {
+		return token.CreateResponse{}, err
+	}
+
+	// a token must not grant more than the actor creating it holds
+	for _, str := range req.Scopes {
+		scope, ok := auth.ParseScope(str)
+		if !ok {
+			return token.CreateResponse{}, xerrors.ErrScopeInvalid.New(str)
+		}
+
+		if !act.InScope(scope.String()) {
+			return token.CreateResponse{}, xerrors.ErrScopeNotHeld.New(str)
+		}
+	}
+
+	// nor outlive the token it is created through
+	if limit, ok := act.Expires(); ok {
+		if expires, ok := req.Expires.Unwrap(); !ok || expires.After(limit) {
+			req.Expires = opt.Some(limit)
+		}
+	}
+
+	return s.Service.Create(act, req)
+}
+
+func (s *AuthService) Delete(act auth.Actor, req token.DeleteRequest) error {
+	if err := service.Authorize(s.policy, act, "tokens:delete", auth.Resource{Subject: act.User()}); err != nil {
+		return err
+	}
+
+	return s.Service.Delete(act, req)
+}
@@ -0,0 +1,108 @@
+package tokenserve_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	tokenrepo "github.com/alan-b-lima/almodon/internal/domain/token/repository"
+	. "github.com/alan-b-lima/almodon/internal/domain/token/service"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+func TestCreateScopes(t *testing.T) {
+	policy := auth.NewPolicy().Allow("tokens:create", auth.Logged(), auth.IsSubject())
+	tokens := New(NewService(tokenrepo.NewMap()), policy)
+
+	user := uuid.NewUUIDv7()
+
+	var (
+		logged = auth.NewLogged(user, auth.User)
+		scoped = auth.NewScoped(user, auth.User, []auth.Scope{"tokens:*", "products:list"})
+		narrow = auth.NewScoped(user, auth.User, []auth.Scope{"tokens:create"})
+	)
+
+	type Tests struct {
+		act    auth.Actor
+		scopes []string
+		title  string
+	}
+
+	tests := []Tests{
+		{logged, []string{"*"}, ""},
+		{logged, []string{"users:delete", "products:*"}, ""},
+		{scoped, []string{"tokens:list", "products:list"}, ""},
+		{scoped, []string{"tokens:*"}, ""},
+		{scoped, []string{"products:create"}, "scope-not-held"},
+		{scoped, []string{"products:*"}, "scope-not-held"},
+		{scoped, []string{"*"}, "scope-not-held"},
+		{scoped, []string{"tokens:list", "users:get"}, "scope-not-held"},
+		{narrow, []string{"tokens:*"}, "scope-not-held"},
+		{narrow, []string{"tokens:create"}, ""},
+		{scoped, []string{"not a scope"}, "scope-invalid"},
+	}
+
+	for i, test := range tests {
+		_, err := tokens.Create(test.act, token.CreateRequest{Name: "ci", Scopes: test.scopes})
+
+		var got string
+		if err, ok := errors.AsType[*errors.Error](err); ok {
+			got = err.Title
+		} else if err != nil {
+			got = err.Error()
+		}
+
+		if got != test.title {
+			t.Errorf("%d: %v: expected %q, got %q", i, test.scopes, test.title, got)
+		}
+	}
+}
+
+func TestCreateExpiry(t *testing.T) {
+	policy := auth.NewPolicy().Allow("tokens:create", auth.Logged(), auth.IsSubject())
+	tokens := New(NewService(tokenrepo.NewMap()), policy)
+
+	user := uuid.NewUUIDv7()
+
+	var (
+		limit  = time.Now().Add(24 * time.Hour).Round(0)
+		before = limit.Add(-time.Hour)
+		after  = limit.Add(time.Hour)
+	)
+
+	var (
+		logged = auth.NewLogged(user, auth.User)
+		scoped = auth.NewScoped(user, auth.User, []auth.Scope{"tokens:create"}).WithExpiry(limit)
+	)
+
+	type Tests struct {
+		act      auth.Actor
+		expires  opt.Opt[time.Time]
+		expected opt.Opt[time.Time]
+	}
+
+	tests := []Tests{
+		{logged, opt.None[time.Time](), opt.None[time.Time]()},
+		{logged, opt.Some(after), opt.Some(after)},
+		{scoped, opt.None[time.Time](), opt.Some(limit)},
+		{scoped, opt.Some(after), opt.Some(limit)},
+		{scoped, opt.Some(before), opt.Some(before)},
+	}
+
+	for i, test := range tests {
+		res, err := tokens.Create(test.act, token.CreateRequest{Name: "ci", Scopes: []string{"tokens:create"}, Expires: test.expires})
+		if err != nil {
+			t.Errorf("%d: did not expect error, but got: %v", i, err)
+			continue
+		}
+
+		got, ok := res.Expires.Unwrap()
+		expected, want := test.expected.Unwrap()
+		if ok != want || !got.Equal(expected) {
+			t.Errorf("%d: expected the token to expire at %v, got %v", i, test.expected, res.Expires)
+		}
+	}
+}
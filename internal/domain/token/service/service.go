@@ -0,0 +1,78 @@
+package tokenserve
+
+import (
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+)
+
+type Service struct {
+	tokens token.Repository
+}
+
+func NewService(tokens token.Repository) token.Service {
+	return &Service{
+		tokens: tokens,
+	}
+}
+
+func (s *Service) List(act auth.Actor, req token.ListRequest) (token.ListResponse, error) {
+	if err := listing.CheckOffset(req.Offset); err != nil {
+		return token.ListResponse{}, err
+	}
+
+	sort, err := token.Listing.ParseSort(req.Sort...)
+	if err != nil {
+		return token.ListResponse{}, err
+	}
+
+	res, err := token.List(s.tokens, act.User(), listing.Query{Sort: sort, After: listing.Cursor(req.Cursor), Limit: req.Limit})
+	if err != nil {
+		return token.ListResponse{}, err
+	}
+
+	lres := token.ListResponse{
+		Length:       len(res.Records),
+		Records:      make([]token.Response, len(res.Records)),
+		TotalRecords: res.TotalRecords,
+		Next:         string(res.Next),
+	}
+	for i := range res.Records {
+		lres.Records[i] = transform(&res.Records[i])
+	}
+
+	return lres, nil
+}
+
+func (s *Service) Create(act auth.Actor, req token.CreateRequest) (token.CreateResponse, error) {
+	res, secret, err := token.Create(s.tokens, act.User(), act.Role(), req.Name, req.Scopes, req.Expires)
+	if err != nil {
+		return token.CreateResponse{}, err
+	}
+
+	return token.CreateResponse{
+		Response: transform(&res),
+		Token:    secret,
+	}, nil
+}
+
+func (s *Service) Delete(act auth.Actor, req token.DeleteRequest) error {
+	return token.Delete(s.tokens, act.User(), req.UUID)
+}
+
+func transform(e *token.Entity) token.Response {
+	scopes := make([]string, len(e.Scopes))
+	for i, scope := range e.Scopes {
+		scopes[i] = scope.String()
+	}
+
+	return token.Response{
+		UUID:     e.UUID,
+		Name:     e.Name,
+		Scopes:   scopes,
+		Role:     e.Role.String(),
+		Expires:  e.Expires,
+		Created:  e.Created,
+		LastUsed: e.LastUsed,
+	}
+}
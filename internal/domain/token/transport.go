@@ -0,0 +1,51 @@
+package token
+
+import (
+	"time"
+
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type (
+	ListRequest struct {
+		Sort   []string     `query:"sort"`
+		Cursor string       `query:"cursor"`
+		Offset opt.Opt[int] `query:"offset,deprecated"`
+		Limit  int          `query:"limit" default:"10"`
+	}
+
+	CreateRequest struct {
+		Name    string             `json:"name"`
+		Scopes  []string           `json:"scopes"`
+		Expires opt.Opt[time.Time] `json:"expires"`
+	}
+
+	DeleteRequest struct {
+		UUID uuid.UUID `json:"-" path:"uuid"`
+	}
+)
+
+type (
+	ListResponse struct {
+		Length       int        `json:"length"`
+		Records      []Response `json:"records" encode:"rows"`
+		TotalRecords int        `json:"total_records"`
+		Next         string     `json:"next,omitempty" header:"Next-Cursor"`
+	}
+
+	Response struct {
+		UUID     uuid.UUID          `json:"uuid"`
+		Name     string             `json:"name"`
+		Scopes   []string           `json:"scopes"`
+		Role     string             `json:"role"`
+		Expires  opt.Opt[time.Time] `json:"expires"`
+		Created  time.Time          `json:"created"`
+		LastUsed opt.Opt[time.Time] `json:"last_used"`
+	}
+
+	CreateResponse struct {
+		Response
+		Token string `json:"token"`
+	}
+)
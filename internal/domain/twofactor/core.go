@@ -0,0 +1,196 @@
+package twofactor
+
+import (
+	"slices"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/hash"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/qr"
+	"github.com/alan-b-lima/almodon/pkg/totp"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+const (
+	_Issuer  = "Almodon"
+	_Skew    = 1
+	_QRScale = 6
+
+	// _MaxFailures is how many codes in a row may fail to verify before
+	// the second factor is locked for _Lockout.
+	_MaxFailures = 10
+	_Lockout     = 15 * time.Minute
+)
+
+// Enabled returns whether the user has a confirmed second factor.
+func Enabled(repo GetterByUser, user uuid.UUID) (bool, error) {
+	res, err := repo.GetByUser(user)
+	if err, ok := errors.AsType[*errors.Error](err); ok && err.Kind == errors.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return res.Confirmed, nil
+}
+
+// Enroll creates a new, unconfirmed, second factor for the user,
+// replacing any other unconfirmed one. The account is the label
+// shown by authenticator applications.
+func Enroll(repo interface {
+	GetterByUser
+	Creater
+}, user uuid.UUID, account string) (Enrollment, error) {
+	enabled, err := Enabled(repo, user)
+	if err != nil {
+		return Enrollment{}, err
+	}
+	if enabled {
+		return Enrollment{}, xerrors.ErrTwoFactorEnabled
+	}
+
+	t, err := New(user)
+	if err != nil {
+		return Enrollment{}, err
+	}
+
+	uri := totp.URI(_Issuer, account, t.Secret())
+
+	code, err := qr.Encode([]byte(uri))
+	if err != nil {
+		return Enrollment{}, xerrors.ErrQRCode.New(err)
+	}
+
+	png, err := code.PNG(_QRScale)
+	if err != nil {
+		return Enrollment{}, xerrors.ErrQRCode.New(err)
+	}
+
+	if err := repo.Create(translate(&t)); err != nil {
+		return Enrollment{}, err
+	}
+
+	return Enrollment{
+		Secret: totp.EncodeSecret(t.Secret()),
+		URI:    uri,
+		QR:     png,
+	}, nil
+}
+
+// Confirm confirms the enrollment of the second factor, given a code
+// generated by it, and returns the recovery codes of the user.
+func Confirm(repo interface {
+	GetterByUser
+	Updater
+}, user uuid.UUID, code string) ([]string, error) {
+	res, err := repo.GetByUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Confirmed {
+		return nil, xerrors.ErrTwoFactorEnabled
+	}
+
+	step, ok := totp.Verify(res.Secret, code, time.Now(), _Skew)
+	if !ok {
+		return nil, xerrors.ErrTwoFactorCodeInvalid
+	}
+
+	codes, hashes, err := NewRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	pt := PartialEntity{
+		Confirmed:     opt.Some(true),
+		LastStep:      opt.Some(step),
+		RecoveryCodes: opt.Some(hashes),
+	}
+
+	return codes, repo.Update(user, pt)
+}
+
+// Verify verifies a code, either generated by the second factor of
+// the user, or one of their recovery codes. Codes cannot be used more
+// than once.
+//
+// Once too many codes in a row have failed, the second factor is
+// locked for a while, and no code is verified in the meantime, so
+// that codes cannot be guessed.
+func Verify(repo interface {
+	Attempter
+	Updater
+}, user uuid.UUID, code string) error {
+	now := time.Now()
+
+	res, err := repo.Attempt(user, now)
+	if err, ok := errors.AsType[*errors.Error](err); ok && err.Kind == errors.NotFound {
+		return xerrors.ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return err
+	}
+
+	if !res.Confirmed {
+		return xerrors.ErrTwoFactorNotEnabled
+	}
+
+	if now.Before(res.LockedUntil) {
+		return xerrors.ErrTwoFactorLocked
+	}
+
+	if res.Failures > _MaxFailures {
+		pt := PartialEntity{
+			Failures:    opt.Some(0),
+			LockedUntil: opt.Some(now.Add(_Lockout)),
+		}
+
+		if err := repo.Update(user, pt); err != nil {
+			return err
+		}
+
+		return xerrors.ErrTwoFactorLocked
+	}
+
+	if step, ok := totp.Verify(res.Secret, code, now, _Skew); ok {
+		if step <= res.LastStep {
+			return xerrors.ErrTwoFactorCodeReused
+		}
+
+		return repo.Update(user, PartialEntity{LastStep: opt.Some(step), Failures: opt.Some(0)})
+	}
+
+	// codes that cannot be recovery codes are not compared against them,
+	// as comparing hashes is slow
+	recovery := []byte(ProcessRecoveryCode(code))
+	if len(recovery) != _RecoveryLength {
+		return xerrors.ErrTwoFactorCodeInvalid
+	}
+
+	for i, h := range res.RecoveryCodes {
+		if !hash.Compare(h[:], recovery) {
+			continue
+		}
+
+		codes := slices.Delete(slices.Clone(res.RecoveryCodes), i, i+1)
+		return repo.Update(user, PartialEntity{RecoveryCodes: opt.Some(codes), Failures: opt.Some(0)})
+	}
+
+	return xerrors.ErrTwoFactorCodeInvalid
+}
+
+func Disable(repo Deleter, user uuid.UUID) error {
+	return repo.Delete(user)
+}
+
+func translate(t *TwoFactor) Entity {
+	return Entity{
+		UUID:   t.UUID(),
+		User:   t.User(),
+		Secret: t.Secret(),
+	}
+}
@@ -0,0 +1,206 @@
+package twofactor_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/internal/domain/twofactor"
+	twofactorrepo "github.com/alan-b-lima/almodon/internal/domain/twofactor/repository"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/totp"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// enable enrolls and confirms a second factor for the user, returning
+// its secret and recovery codes.
+func enable(t *testing.T, repo Repository, user uuid.UUID) ([]byte, []string) {
+	t.Helper()
+
+	enrollment, err := Enroll(repo, user, "alan")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := totp.DecodeSecret(enrollment.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes, err := Confirm(repo, user, totp.Code(secret, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return secret, codes
+}
+
+func title(err error) string {
+	if err, ok := errors.AsType[*errors.Error](err); ok {
+		return err.Title
+	}
+
+	return ""
+}
+
+func TestEnroll(t *testing.T) {
+	repo := twofactorrepo.NewMap()
+	user := uuid.NewUUIDv7()
+
+	enrollment, err := Enroll(repo, user, "alan")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/") || !bytes.HasPrefix(enrollment.QR, []byte("\x89PNG")) {
+		t.Errorf("expected an otpauth URI and a PNG, got %q and %q", enrollment.URI, enrollment.QR[:min(8, len(enrollment.QR))])
+	}
+
+	secret, err := totp.DecodeSecret(enrollment.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+
+	type Tests struct {
+		step  string
+		call  func() error
+		title string
+	}
+
+	tests := []Tests{
+		{"verify before confirmation", func() error { return Verify(repo, user, totp.Code(secret, now)) }, "two-factor-not-enabled"},
+		{"confirm with a wrong code", func() error { _, err := Confirm(repo, user, "000000x"); return err }, "two-factor-code-invalid"},
+		{"confirm", func() error { _, err := Confirm(repo, user, totp.Code(secret, now)); return err }, ""},
+		{"confirm again", func() error { _, err := Confirm(repo, user, totp.Code(secret, now)); return err }, "two-factor-enabled"},
+		{"enroll again", func() error { _, err := Enroll(repo, user, "alan"); return err }, "two-factor-enabled"},
+	}
+
+	for _, test := range tests {
+		if err := test.call(); title(err) != test.title || (test.title == "" && err != nil) {
+			t.Errorf("%s: expected %q, got %v", test.step, test.title, err)
+		}
+	}
+
+	if enabled, err := Enabled(repo, user); err != nil || !enabled {
+		t.Errorf("expected the second factor to be enabled, got %t, %v", enabled, err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	repo := twofactorrepo.NewMap()
+	user := uuid.NewUUIDv7()
+
+	secret, codes := enable(t, repo, user)
+
+	// codes are generated around the step the second factor was
+	// confirmed at, as the step may have changed since
+	res, err := repo.GetByUser(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(res.LastStep*int64(totp.Period/time.Second), 0)
+
+	type Tests struct {
+		step  string
+		code  string
+		title string
+	}
+
+	tests := []Tests{
+		{"code used to confirm", totp.Code(secret, now), "two-factor-code-reused"},
+		{"code of the next step", totp.Code(secret, now.Add(totp.Period)), ""},
+		{"code of the next step again", totp.Code(secret, now.Add(totp.Period)), "two-factor-code-reused"},
+		{"code of a past step", totp.Code(secret, now), "two-factor-code-reused"},
+		{"wrong code", "000000x", "two-factor-code-invalid"},
+		{"recovery code", codes[0], ""},
+		{"recovery code again", codes[0], "two-factor-code-invalid"},
+		{"recovery code, loosely typed", strings.ToUpper(strings.ReplaceAll(codes[1], "-", " ")), ""},
+		{"wrong recovery code", "aaaaa-aaaaa", "two-factor-code-invalid"},
+	}
+
+	for _, test := range tests {
+		if err := Verify(repo, user, test.code); title(err) != test.title || (test.title == "" && err != nil) {
+			t.Errorf("%s: expected %q, got %v", test.step, test.title, err)
+		}
+	}
+
+	res, err = repo.GetByUser(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.RecoveryCodes) != len(codes)-2 {
+		t.Errorf("expected the used recovery codes to be spent, %d are left of %d", len(res.RecoveryCodes), len(codes))
+	}
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, hashes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codes) != len(hashes) {
+		t.Fatalf("expected as many hashes as codes, got %d and %d", len(hashes), len(codes))
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		plain := ProcessRecoveryCode(code)
+		if len(code) != 11 || code[5] != '-' || len(plain) != 10 || strings.Trim(plain, "abcdefghjkmnpqrstuvwxyz23456789") != "" {
+			t.Errorf("expected a code as xxxxx-xxxxx of unambiguous characters, got %q", code)
+		}
+
+		if seen[code] {
+			t.Errorf("expected codes to be distinct, got %q twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestDisable(t *testing.T) {
+	repo := twofactorrepo.NewMap()
+	user := uuid.NewUUIDv7()
+
+	secret, _ := enable(t, repo, user)
+
+	if err := Disable(repo, user); err != nil {
+		t.Fatal(err)
+	}
+
+	if enabled, err := Enabled(repo, user); err != nil || enabled {
+		t.Errorf("expected the second factor to be disabled, got %t, %v", enabled, err)
+	}
+
+	if err := Verify(repo, user, totp.Code(secret, time.Now().Add(totp.Period))); title(err) != "two-factor-not-enabled" {
+		t.Errorf("expected codes not to verify once disabled, got %v", err)
+	}
+
+	if _, err := Enroll(repo, user, "alan"); err != nil {
+		t.Errorf("expected to enroll again once disabled, got %v", err)
+	}
+}
+
+func TestVerifyLockout(t *testing.T) {
+	repo := twofactorrepo.NewMap()
+	user := uuid.NewUUIDv7()
+
+	secret, _ := enable(t, repo, user)
+
+	for i := range 10 {
+		if err := Verify(repo, user, "wrong"); title(err) != "two-factor-code-invalid" {
+			t.Fatalf("attempt %d: expected the code to be invalid, got %v", i, err)
+		}
+	}
+
+	if err := Verify(repo, user, "wrong"); title(err) != "two-factor-locked" {
+		t.Fatalf("expected the second factor to be locked, got %v", err)
+	}
+
+	if err := Verify(repo, user, totp.Code(secret, time.Now())); title(err) != "two-factor-locked" {
+		t.Errorf("expected even correct codes to be refused while locked, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"strings"
+
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/hash"
+	"github.com/alan-b-lima/almodon/pkg/totp"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type TwoFactor struct {
+	uuid   uuid.UUID
+	user   uuid.UUID
+	secret []byte
+}
+
+func New(user uuid.UUID) (TwoFactor, error) {
+	t := TwoFactor{
+		uuid:   uuid.NewUUIDv7(),
+		user:   user,
+		secret: totp.NewSecret(),
+	}
+
+	return t, nil
+}
+
+func (t *TwoFactor) UUID() uuid.UUID { return t.uuid }
+func (t *TwoFactor) User() uuid.UUID { return t.user }
+func (t *TwoFactor) Secret() []byte  { return t.secret }
+
+const (
+	_RecoveryCodes    = 10
+	_RecoveryLength   = 10
+	_RecoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// NewRecoveryCodes generates a set of single use recovery codes,
+// returning them both in plain text, to be shown once to the user,
+// and hashed, to be stored.
+func NewRecoveryCodes() ([]string, [][60]byte, error) {
+	codes := make([]string, _RecoveryCodes)
+	hashes := make([][60]byte, _RecoveryCodes)
+
+	for i := range codes {
+		var buf [_RecoveryLength]byte
+		for j := range buf {
+			buf[j] = recoveryChar()
+		}
+
+		hash, err := hash.Hash(buf[:])
+		if err != nil {
+			return nil, nil, xerrors.ErrFailedToHashPassword.New(err)
+		}
+
+		codes[i] = string(buf[:5]) + "-" + string(buf[5:])
+		hashes[i] = hash
+	}
+
+	return codes, hashes, nil
+}
+
+// recoveryChar draws a character of the recovery alphabet uniformly,
+// bytes beyond the last whole multiple of its length are drawn again,
+// as they would favor its first characters.
+func recoveryChar() byte {
+	const limit = 256 - 256%len(_RecoveryAlphabet)
+
+	var b [1]byte
+	for {
+		rand.Read(b[:])
+		if int(b[0]) < limit {
+			return _RecoveryAlphabet[int(b[0])%len(_RecoveryAlphabet)]
+		}
+	}
+}
+
+func ProcessRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+
+	return code
+}
@@ -0,0 +1,68 @@
+package twofactor
+
+import (
+	"time"
+
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Repository interface {
+	GetterByUser
+	Creater
+	Updater
+	Attempter
+	Deleter
+}
+
+type (
+	GetterByUser interface {
+		GetByUser(uuid.UUID) (Entity, error)
+	}
+
+	Creater interface {
+		Create(Entity) error
+	}
+
+	Updater interface {
+		Update(uuid.UUID, PartialEntity) error
+	}
+
+	// Attempter counts an attempt at verifying a code of the second
+	// factor of the user, unless it is locked by now, returning it as
+	// of after the attempt.
+	Attempter interface {
+		Attempt(user uuid.UUID, now time.Time) (Entity, error)
+	}
+
+	Deleter interface {
+		Delete(uuid.UUID) error
+	}
+)
+
+type (
+	Entity struct {
+		UUID          uuid.UUID
+		User          uuid.UUID
+		Secret        []byte
+		Confirmed     bool
+		LastStep      int64
+		RecoveryCodes [][60]byte
+		Failures      int
+		LockedUntil   time.Time
+	}
+
+	PartialEntity struct {
+		Confirmed     opt.Opt[bool]
+		LastStep      opt.Opt[int64]
+		RecoveryCodes opt.Opt[[][60]byte]
+		Failures      opt.Opt[int]
+		LockedUntil   opt.Opt[time.Time]
+	}
+
+	Enrollment struct {
+		Secret string
+		URI    string
+		QR     []byte
+	}
+)
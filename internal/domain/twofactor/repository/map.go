@@ -0,0 +1,241 @@
+package twofactorrepo
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/domain/twofactor"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type Map struct {
+	userIndex map[uuid.UUID]int
+
+	repo []twofactor.Entity
+	mu   sync.RWMutex
+
+	datapath string
+}
+
+func NewMap() twofactor.Repository {
+	repo := Map{
+		userIndex: make(map[uuid.UUID]int),
+	}
+
+	return &repo
+}
+
+func NewPersistantMap(datapath string) (twofactor.Repository, error) {
+	repo := Map{
+		userIndex: make(map[uuid.UUID]int),
+		datapath:  datapath,
+	}
+
+	if err := repo.init(); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+func (m *Map) init() error {
+	f, err := os.Open(m.datapath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var repo []entity
+	if err := json.NewDecoder(f).Decode(&repo); err != nil {
+		return err
+	}
+
+	m.repo = make([]twofactor.Entity, len(repo))
+	for i, record := range repo {
+		m.repo[i] = entity_from_json(record)
+		m.userIndex[record.User] = i
+	}
+
+	return nil
+}
+
+func (m *Map) Close() error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	repo := make([]entity, len(m.repo))
+	for i, record := range m.repo {
+		repo[i] = json_for_entity(record)
+	}
+
+	return json.NewEncoder(f).Encode(repo)
+}
+
+// Ping reports whether the data file can still be written.
+func (m *Map) Ping() error {
+	if m.datapath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func (m *Map) GetByUser(user uuid.UUID) (twofactor.Entity, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	index, in := m.userIndex[user]
+	if !in {
+		return twofactor.Entity{}, xerrors.ErrTwoFactorNotFound
+	}
+
+	return m.repo[index], nil
+}
+
+func (m *Map) Create(tf twofactor.Entity) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	if index, in := m.userIndex[tf.User]; in {
+		m.repo[index] = tf
+		return nil
+	}
+
+	m.userIndex[tf.User] = len(m.repo)
+	m.repo = append(m.repo, tf)
+
+	return nil
+}
+
+func (m *Map) Update(user uuid.UUID, tf twofactor.PartialEntity) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.userIndex[user]
+	if !in {
+		return xerrors.ErrTwoFactorNotFound
+	}
+
+	e := &m.repo[index]
+
+	some_then(&e.Confirmed, tf.Confirmed)
+	some_then(&e.LastStep, tf.LastStep)
+	some_then(&e.RecoveryCodes, tf.RecoveryCodes)
+	some_then(&e.Failures, tf.Failures)
+	some_then(&e.LockedUntil, tf.LockedUntil)
+
+	return nil
+}
+
+func (m *Map) Attempt(user uuid.UUID, now time.Time) (twofactor.Entity, error) {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.userIndex[user]
+	if !in {
+		return twofactor.Entity{}, xerrors.ErrTwoFactorNotFound
+	}
+
+	e := &m.repo[index]
+	if !now.Before(e.LockedUntil) {
+		e.Failures++
+	}
+
+	return *e, nil
+}
+
+func (m *Map) Delete(user uuid.UUID) error {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+
+	index, in := m.userIndex[user]
+	if !in {
+		return nil
+	}
+
+	delete(m.userIndex, user)
+
+	last := len(m.repo) - 1
+	if index != last {
+		m.repo[index] = m.repo[last]
+		m.userIndex[m.repo[index].User] = index
+	}
+	m.repo = m.repo[:last]
+
+	return nil
+}
+
+func some_then[F any](dst *F, src opt.Opt[F]) {
+	val, ok := src.Unwrap()
+	if !ok {
+		return
+	}
+
+	*dst = val
+}
+
+type entity struct {
+	UUID          uuid.UUID `json:"uuid"`
+	User          uuid.UUID `json:"user"`
+	Secret        []byte    `json:"secret"`
+	Confirmed     bool      `json:"confirmed"`
+	LastStep      int64     `json:"last_step"`
+	RecoveryCodes []string  `json:"recovery_codes"`
+	Failures      int       `json:"failures,omitempty"`
+	LockedUntil   time.Time `json:"locked_until,omitzero"`
+}
+
+func json_for_entity(e twofactor.Entity) entity {
+	codes := make([]string, len(e.RecoveryCodes))
+	for i, code := range e.RecoveryCodes {
+		codes[i] = string(code[:])
+	}
+
+	return entity{
+		UUID:          e.UUID,
+		User:          e.User,
+		Secret:        e.Secret,
+		Confirmed:     e.Confirmed,
+		LastStep:      e.LastStep,
+		RecoveryCodes: codes,
+		Failures:      e.Failures,
+		LockedUntil:   e.LockedUntil,
+	}
+}
+
+func entity_from_json(e entity) twofactor.Entity {
+	codes := make([][60]byte, len(e.RecoveryCodes))
+	for i, code := range e.RecoveryCodes {
+		copy(codes[i][:], code)
+	}
+
+	return twofactor.Entity{
+		UUID:          e.UUID,
+		User:          e.User,
+		Secret:        e.Secret,
+		Confirmed:     e.Confirmed,
+		LastStep:      e.LastStep,
+		RecoveryCodes: codes,
+		Failures:      e.Failures,
+		LockedUntil:   e.LockedUntil,
+	}
+}
@@ -0,0 +1,33 @@
+package user
+
+// Authenticator verifies the credentials of a user, identified by
+// their SIAPE, against some source of truth, such as the local
+// repository or the institutional directory.
+type Authenticator interface {
+	Authenticate(siape int, password string) (Identity, error)
+}
+
+// IdentityProvider authenticates users on behalf of the system, to
+// which the user agent is redirected, as in single sign-on.
+type IdentityProvider interface {
+	// AuthCodeURL returns where the user agent must be redirected to,
+	// the state, nonce and verifier must be kept bound to it.
+	AuthCodeURL(state, nonce, verifier string) string
+
+	// Identify identifies the user through the code the provider
+	// redirected the user agent back with.
+	Identify(code, verifier, nonce string) (Identity, error)
+}
+
+// Identity is what an [Authenticator] or an [IdentityProvider] knows
+// about an authenticated user, used to provision the users unknown to
+// the system.
+type Identity struct {
+	SIAPE int
+	Name  string
+	Email string
+
+	// MultiFactor tells whether the user was authenticated with a
+	// second factor, which then is not asked for again.
+	MultiFactor bool
+}
@@ -0,0 +1,337 @@
+package userauth_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/session"
+	sessionrepo "github.com/alan-b-lima/almodon/internal/domain/session/repository"
+	twofactorrepo "github.com/alan-b-lima/almodon/internal/domain/twofactor/repository"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	. "github.com/alan-b-lima/almodon/internal/domain/user/authenticator"
+	userrepo "github.com/alan-b-lima/almodon/internal/domain/user/repository"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/ldap"
+	"github.com/alan-b-lima/almodon/pkg/ldap/ldaptest"
+	"github.com/alan-b-lima/almodon/pkg/oidc"
+	"github.com/alan-b-lima/almodon/pkg/oidc/oidctest"
+)
+
+var entries = []ldap.Entry{
+	{
+		DN: "uid=jdoe,ou=people,dc=ufla,dc=br",
+		Attributes: []ldap.Attribute{
+			{Name: "objectClass", Values: []string{"person"}},
+			{Name: "cn", Values: []string{"John Doe"}},
+			{Name: "mail", Values: []string{"jdoe@ufla.br"}},
+			{Name: "employeeNumber", Values: []string{"123456"}},
+			{Name: "userPassword", Values: []string{"directory-secret"}},
+		},
+	},
+	{
+		DN: "uid=twin1,ou=people,dc=ufla,dc=br",
+		Attributes: []ldap.Attribute{
+			{Name: "objectClass", Values: []string{"person"}},
+			{Name: "employeeNumber", Values: []string{"777777"}},
+			{Name: "userPassword", Values: []string{"twin-secret"}},
+		},
+	},
+	{
+		DN: "uid=twin2,ou=people,dc=ufla,dc=br",
+		Attributes: []ldap.Attribute{
+			{Name: "objectClass", Values: []string{"person"}},
+			{Name: "employeeNumber", Values: []string{"777777"}},
+			{Name: "userPassword", Values: []string{"twin-secret"}},
+		},
+	},
+	{
+		DN: "cn=almodon,ou=services,dc=ufla,dc=br",
+		Attributes: []ldap.Attribute{
+			{Name: "userPassword", Values: []string{"service-secret"}},
+		},
+	},
+}
+
+func newLDAP(t *testing.T, srv *ldaptest.Server, starttls bool) user.Authenticator {
+	authn, err := NewLDAP(LDAPConfig{
+		Addr:         srv.Addr,
+		StartTLS:     starttls,
+		TLSConfig:    srv.ClientTLSConfig(),
+		Timeout:      time.Second,
+		BindDN:       "cn=almodon,ou=services,dc=ufla,dc=br",
+		BindPassword: "service-secret",
+		BaseDN:       "ou=people,dc=ufla,dc=br",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return authn
+}
+
+func kind(err error) errors.Kind {
+	if err, ok := errors.AsType[*errors.Error](err); ok {
+		return err.Kind
+	}
+
+	return 0
+}
+
+func TestLDAP(t *testing.T) {
+	srv := ldaptest.NewServer(entries...)
+	defer srv.Close()
+
+	authn := newLDAP(t, srv, false)
+
+	type Tests struct {
+		siape    int
+		password string
+		kind     errors.Kind
+	}
+
+	tests := []Tests{
+		{123456, "directory-secret", 0},
+		{123456, "wrong", errors.Unauthorized},
+		{123456, "", errors.Unauthorized},
+		{111111, "directory-secret", errors.Unauthorized},
+		{777777, "twin-secret", errors.Internal},
+	}
+
+	for _, test := range tests {
+		id, err := authn.Authenticate(test.siape, test.password)
+		if kind(err) != test.kind {
+			t.Errorf("authenticating %d with %q should fail with %v, got %v", test.siape, test.password, test.kind, err)
+			continue
+		}
+
+		if err == nil && (id.Name != "John Doe" || id.Email != "jdoe@ufla.br" || id.SIAPE != test.siape) {
+			t.Errorf("unexpected identity %+v", id)
+		}
+	}
+}
+
+func TestLDAPStartTLS(t *testing.T) {
+	srv := ldaptest.NewUnstartedServer(entries...)
+	srv.StartTLS()
+	defer srv.Close()
+
+	if _, err := newLDAP(t, srv, true).Authenticate(123456, "directory-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	plain := ldaptest.NewServer(entries...)
+	defer plain.Close()
+
+	_, err := newLDAP(t, plain, true).Authenticate(123456, "directory-secret")
+	if kind(err) != errors.Unavailable {
+		t.Errorf("credentials must not be sent if StartTLS fails, got %v", err)
+	}
+
+	if binds := plain.Binds(); len(binds) != 0 {
+		t.Errorf("no bind should have happened, got %v", binds)
+	}
+}
+
+// TestLDAPStartTLSConfig checks that the server name is taken from the
+// address and the authorities from the CA file, when not given.
+func TestLDAPStartTLSConfig(t *testing.T) {
+	srv := ldaptest.NewUnstartedServer(entries...)
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	type Tests struct {
+		name      string
+		caFile    string
+		tlsConfig *tls.Config
+		kind      errors.Kind
+	}
+
+	tests := []Tests{
+		{"CA file", caFile, nil, 0},
+		{"authorities without server name", "", &tls.Config{RootCAs: pool}, 0},
+		{"system authorities", "", nil, errors.Unavailable},
+		{"wrong server name", caFile, &tls.Config{ServerName: "ldap.ufla.br"}, errors.Unavailable},
+	}
+
+	for _, test := range tests {
+		authn, err := NewLDAP(LDAPConfig{
+			Addr:         srv.Addr,
+			StartTLS:     true,
+			TLSConfig:    test.tlsConfig,
+			CAFile:       test.caFile,
+			Timeout:      time.Second,
+			BindDN:       "cn=almodon,ou=services,dc=ufla,dc=br",
+			BindPassword: "service-secret",
+			BaseDN:       "ou=people,dc=ufla,dc=br",
+		})
+		if err != nil {
+			t.Errorf("%s: did not expect error, but got: %v", test.name, err)
+			continue
+		}
+
+		if _, err := authn.Authenticate(123456, "directory-secret"); kind(err) != test.kind {
+			t.Errorf("%s: expected %v, got %v", test.name, test.kind, err)
+		}
+	}
+}
+
+func TestNewLDAP(t *testing.T) {
+	tests := []LDAPConfig{
+		{},
+		{Addr: "localhost:389", Filter: "(employeeNumber=123456)"},
+		{Addr: "localhost:389", Filter: "(employeeNumber={siape}"},
+		{Addr: "localhost", StartTLS: true},
+		{Addr: "localhost:389", StartTLS: true, CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+	}
+
+	for _, test := range tests {
+		if _, err := NewLDAP(test); err == nil {
+			t.Errorf("config %+v should be refused", test)
+		}
+	}
+}
+
+func TestChainProvisioning(t *testing.T) {
+	srv := ldaptest.NewServer(entries...)
+	defer srv.Close()
+
+	users := userrepo.NewMap()
+	sessions := sessionrepo.NewMap()
+	twofactors := twofactorrepo.NewMap()
+
+	if _, err := user.Create(users, 654321, "Local User", "local@ufla.br", "local-secret", auth.Admin); err != nil {
+		t.Fatal(err)
+	}
+
+	authn := NewChain(NewLocal(users), newLDAP(t, srv, false))
+
+	if _, err := user.Authenticate(users, authn, sessions, session.DefaultLifetimes, twofactors, 654321, "local-secret"); err != nil {
+		t.Errorf("local user should be authenticated, got %v", err)
+	}
+
+	if _, err := user.Authenticate(users, authn, sessions, session.DefaultLifetimes, twofactors, 123456, "directory-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := users.GetBySIAPE(123456)
+	if err != nil {
+		t.Fatalf("directory user should be provisioned, got %v", err)
+	}
+
+	if res.Name != "John Doe" || res.Email != "jdoe@ufla.br" || res.Role != auth.User {
+		t.Errorf("unexpected provisioned user %+v", res)
+	}
+
+	// the provisioned user has no local password, and keeps being
+	// authenticated by the directory
+	if _, err := user.Authenticate(users, authn, sessions, session.DefaultLifetimes, twofactors, 123456, "directory-secret"); err != nil {
+		t.Errorf("provisioned user should be authenticated again, got %v", err)
+	}
+
+	_, err = user.Authenticate(users, authn, sessions, session.DefaultLifetimes, twofactors, 123456, "wrong")
+	if err != xerrors.ErrIncorrectPassword {
+		t.Errorf("wrong password should be refused, got %v", err)
+	}
+
+	srv.Close()
+
+	_, err = user.Authenticate(users, authn, sessions, session.DefaultLifetimes, twofactors, 123456, "directory-secret")
+	if kind(err) != errors.Unavailable {
+		t.Errorf("unreachable directory should be reported, got %v", err)
+	}
+
+	if _, err := user.Authenticate(users, authn, sessions, session.DefaultLifetimes, twofactors, 654321, "local-secret"); err != nil {
+		t.Errorf("local user should be authenticated without the directory, got %v", err)
+	}
+}
+
+func TestOIDC(t *testing.T) {
+	srv := oidctest.NewServer("almodon", "client-secret")
+	defer srv.Close()
+
+	rp, err := oidc.New(context.Background(), oidc.Config{
+		Issuer:       srv.Issuer(),
+		ClientID:     srv.ClientID,
+		ClientSecret: srv.ClientSecret,
+		RedirectURL:  "https://almodon.example/api/v1/users/auth/sso/callback/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewOIDC(rp, OIDCConfig{})
+
+	users := userrepo.NewMap()
+	sessions := sessionrepo.NewMap()
+	twofactors := twofactorrepo.NewMap()
+
+	login := func(claims map[string]any) (user.AuthEntity, error) {
+		srv.Login(claims)
+
+		state, nonce, verifier := oidc.NewRandom(), oidc.NewRandom(), oidc.NewRandom()
+		client := &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+
+		res, err := client.Get(provider.AuthCodeURL(state, nonce, verifier))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		location, err := url.Parse(res.Header.Get("Location"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return user.AuthenticateSingleSignOn(users, provider, sessions, session.DefaultLifetimes, twofactors, location.Query().Get("code"), verifier, nonce)
+	}
+
+	res, err := login(map[string]any{"sub": "abc", "siape": "123456", "name": "John Doe", "email": "jdoe@ufla.br", "amr": []string{"pwd", "mfa"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ures, err := users.GetBySIAPE(123456)
+	if err != nil || ures.UUID != res.User || ures.Name != "John Doe" || ures.Role != auth.User {
+		t.Errorf("user should be provisioned from the claims, got %+v and %v", ures, err)
+	}
+
+	sres, err := sessions.Get(res.UUID)
+	if err != nil || sres.Level != session.MultiFactor {
+		t.Errorf("session should be multi-factor, got %+v and %v", sres, err)
+	}
+
+	res, err = login(map[string]any{"sub": "abc", "siape": 123456, "amr": []string{"pwd"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sres, _ := sessions.Get(res.UUID); sres.Level != session.SingleFactor {
+		t.Errorf("session should be single-factor, got %+v", sres)
+	}
+
+	_, err = login(map[string]any{"sub": "abc"})
+	if kind(err) != errors.Forbidden {
+		t.Errorf("login without a siape should be refused, got %v", err)
+	}
+}
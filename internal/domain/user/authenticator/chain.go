@@ -0,0 +1,37 @@
+package userauth
+
+import (
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+)
+
+type Chain []user.Authenticator
+
+// NewChain creates an authenticator that tries each of the given
+// ones, in order, until one of them succeeds. Rejections are not
+// reported if a later authenticator succeeds, but failures to
+// authenticate at all, such as an unreachable directory, are
+// reported if none succeeds.
+func NewChain(authenticators ...user.Authenticator) user.Authenticator {
+	return Chain(authenticators)
+}
+
+func (c Chain) Authenticate(siape int, password string) (user.Identity, error) {
+	failure := xerrors.ErrIncorrectPassword
+
+	for _, authn := range c {
+		id, err := authn.Authenticate(siape, password)
+		if err == nil {
+			return id, nil
+		}
+
+		if err, ok := errors.AsType[*errors.Error](err); ok && err.Kind.IsClient() {
+			continue
+		}
+
+		failure = err
+	}
+
+	return user.Identity{}, failure
+}
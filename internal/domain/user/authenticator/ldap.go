@@ -0,0 +1,205 @@
+package userauth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/ldap"
+)
+
+// SIAPEPlaceholder is replaced by the SIAPE of the user being
+// authenticated in [LDAPConfig.Filter].
+const SIAPEPlaceholder = "{siape}"
+
+type LDAPConfig struct {
+	// Addr is the address of the directory server, in the form
+	// host:port.
+	Addr string
+
+	// StartTLS secures the connection through the StartTLS extended
+	// operation, with TLSConfig, before any credential is sent. The
+	// server name of TLSConfig is the host of Addr, if not given.
+	StartTLS  bool
+	TLSConfig *tls.Config
+
+	// CAFile is a PEM file of the certificate authorities trusted to
+	// issue the certificate of the directory, rather than those of the
+	// system. It is ignored if TLSConfig has its own.
+	CAFile string
+
+	Timeout time.Duration
+
+	// BindDN and BindPassword are the credentials of the service
+	// account used to search for users, an anonymous bind is used if
+	// BindDN is empty.
+	BindDN       string
+	BindPassword string
+
+	// BaseDN is where users are searched from, through the whole
+	// subtree, with Filter, which must contain [SIAPEPlaceholder].
+	BaseDN string
+	Filter string
+
+	// NameAttribute and EmailAttribute map the attributes of the
+	// entry of a user to their name and e-mail.
+	NameAttribute  string
+	EmailAttribute string
+}
+
+const (
+	_DefaultFilter         = "(&(objectClass=person)(employeeNumber=" + SIAPEPlaceholder + "))"
+	_DefaultNameAttribute  = "cn"
+	_DefaultEmailAttribute = "mail"
+	_DefaultTimeout        = 5 * time.Second
+)
+
+type LDAP struct {
+	config LDAPConfig
+}
+
+// NewLDAP creates an authenticator that binds to the directory as
+// the user, found by their SIAPE.
+func NewLDAP(config LDAPConfig) (user.Authenticator, error) {
+	if config.Filter == "" {
+		config.Filter = _DefaultFilter
+	}
+
+	if config.NameAttribute == "" {
+		config.NameAttribute = _DefaultNameAttribute
+	}
+
+	if config.EmailAttribute == "" {
+		config.EmailAttribute = _DefaultEmailAttribute
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = _DefaultTimeout
+	}
+
+	if config.Addr == "" {
+		return nil, xerrors.ErrDirectoryConfig.New("address must not be empty")
+	}
+
+	if !strings.Contains(config.Filter, SIAPEPlaceholder) {
+		return nil, xerrors.ErrDirectoryConfig.New("filter must contain " + SIAPEPlaceholder)
+	}
+
+	if _, err := ldap.ParseFilter(filter(config.Filter, 0)); err != nil {
+		return nil, xerrors.ErrDirectoryConfig.New(err.Error())
+	}
+
+	if config.StartTLS {
+		tlsConfig, err := tlsConfig(&config)
+		if err != nil {
+			return nil, xerrors.ErrDirectoryConfig.New(err.Error())
+		}
+
+		config.TLSConfig = tlsConfig
+	}
+
+	return &LDAP{config: config}, nil
+}
+
+// tlsConfig completes the TLS config of the directory with the server
+// name and the certificate authorities it is verified against.
+func tlsConfig(config *LDAPConfig) (*tls.Config, error) {
+	cfg := &tls.Config{}
+	if config.TLSConfig != nil {
+		cfg = config.TLSConfig.Clone()
+	}
+
+	if cfg.ServerName == "" {
+		host, _, err := net.SplitHostPort(config.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("address must be of the form host:port: %w", err)
+		}
+
+		cfg.ServerName = host
+	}
+
+	if config.CAFile != "" && cfg.RootCAs == nil {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", config.CAFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
+func (a *LDAP) Authenticate(siape int, password string) (user.Identity, error) {
+	// an empty password would make an unauthenticated bind, which
+	// directories accept without checking anything
+	if password == "" {
+		return user.Identity{}, xerrors.ErrIncorrectPassword
+	}
+
+	conn, err := ldap.Dial(a.config.Addr, a.config.Timeout)
+	if err != nil {
+		return user.Identity{}, xerrors.ErrDirectoryUnavailable.New(err)
+	}
+	defer conn.Close()
+
+	if a.config.StartTLS {
+		if err := conn.StartTLS(a.config.TLSConfig); err != nil {
+			return user.Identity{}, xerrors.ErrDirectoryUnavailable.New(err)
+		}
+	}
+
+	if a.config.BindDN != "" {
+		if err := conn.Bind(a.config.BindDN, a.config.BindPassword); err != nil {
+			return user.Identity{}, xerrors.ErrDirectoryFailure.New(err)
+		}
+	}
+
+	res, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:     a.config.BaseDN,
+		Scope:      ldap.WholeSubtree,
+		SizeLimit:  2,
+		TimeLimit:  a.config.Timeout,
+		Filter:     filter(a.config.Filter, siape),
+		Attributes: []string{a.config.NameAttribute, a.config.EmailAttribute},
+	})
+	if ldap.IsCode(err, ldap.SizeLimitExceeded) || len(res) > 1 {
+		return user.Identity{}, xerrors.ErrDirectoryFailure.New(fmt.Errorf("more than one entry matches siape %d", siape))
+	}
+	if err != nil {
+		return user.Identity{}, xerrors.ErrDirectoryFailure.New(err)
+	}
+
+	if len(res) == 0 {
+		return user.Identity{}, xerrors.ErrIncorrectPassword
+	}
+	entry := res[0]
+
+	if err := conn.Bind(entry.DN, password); ldap.IsCode(err, ldap.InvalidCredentials) {
+		return user.Identity{}, xerrors.ErrIncorrectPassword
+	} else if err != nil {
+		return user.Identity{}, xerrors.ErrDirectoryFailure.New(err)
+	}
+
+	return user.Identity{
+		SIAPE: siape,
+		Name:  entry.Value(a.config.NameAttribute),
+		Email: entry.Value(a.config.EmailAttribute),
+	}, nil
+}
+
+func filter(template string, siape int) string {
+	return strings.ReplaceAll(template, SIAPEPlaceholder, ldap.EscapeFilter(strconv.Itoa(siape)))
+}
@@ -0,0 +1,34 @@
+package userauth
+
+import (
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/hash"
+)
+
+type Local struct {
+	users user.GetterBySIAPE
+}
+
+// NewLocal creates an authenticator that checks the passwords stored
+// in the user repository.
+func NewLocal(users user.GetterBySIAPE) user.Authenticator {
+	return &Local{users: users}
+}
+
+func (a *Local) Authenticate(siape int, password string) (user.Identity, error) {
+	res, err := a.users.GetBySIAPE(siape)
+	if err != nil {
+		return user.Identity{}, err
+	}
+
+	if !hash.Compare(res.Password[:], []byte(password)) {
+		return user.Identity{}, xerrors.ErrIncorrectPassword
+	}
+
+	return user.Identity{
+		SIAPE: res.SIAPE,
+		Name:  res.Name,
+		Email: res.Email,
+	}, nil
+}
@@ -0,0 +1,137 @@
+package userauth
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/oidc"
+)
+
+type OIDCConfig struct {
+	// SIAPEClaim is the claim of the ID token holding the SIAPE of
+	// the user, either as a number or a string, "sub" may be used if
+	// the provider identifies users by their SIAPE.
+	SIAPEClaim string
+
+	// NameClaim and EmailClaim map the claims of the ID token to the
+	// name and e-mail of the user.
+	NameClaim  string
+	EmailClaim string
+
+	// MultiFactorMethods are the authentication methods, as given in
+	// the amr claim, that count as a second factor.
+	MultiFactorMethods []string
+
+	// Timeout bounds the exchange of the code and the verification of
+	// the ID token.
+	Timeout time.Duration
+}
+
+const (
+	_DefaultSIAPEClaim  = "siape"
+	_DefaultNameClaim   = "name"
+	_DefaultEmailClaim  = "email"
+	_DefaultOIDCTimeout = 10 * time.Second
+)
+
+// see RFC 8176 section 2
+var _DefaultMultiFactorMethods = []string{"mfa", "otp", "hwk", "sms"}
+
+type OIDC struct {
+	rp     *oidc.RelyingParty
+	config OIDCConfig
+}
+
+// NewOIDC creates an identity provider out of an OpenID Connect
+// relying party.
+func NewOIDC(rp *oidc.RelyingParty, config OIDCConfig) user.IdentityProvider {
+	if config.SIAPEClaim == "" {
+		config.SIAPEClaim = _DefaultSIAPEClaim
+	}
+
+	if config.NameClaim == "" {
+		config.NameClaim = _DefaultNameClaim
+	}
+
+	if config.EmailClaim == "" {
+		config.EmailClaim = _DefaultEmailClaim
+	}
+
+	if config.MultiFactorMethods == nil {
+		config.MultiFactorMethods = _DefaultMultiFactorMethods
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = _DefaultOIDCTimeout
+	}
+
+	return &OIDC{rp: rp, config: config}
+}
+
+func (p *OIDC) AuthCodeURL(state, nonce, verifier string) string {
+	return p.rp.AuthCodeURL(state, nonce, verifier)
+}
+
+func (p *OIDC) Identify(code, verifier, nonce string) (user.Identity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+
+	token, err := p.rp.Exchange(ctx, code, verifier)
+	if err != nil {
+		return user.Identity{}, xerrors.ErrSingleSignOnFailed.New(err)
+	}
+
+	id, err := p.rp.Verify(ctx, token.IDToken, nonce)
+	if err != nil {
+		return user.Identity{}, xerrors.ErrSingleSignOnFailed.New(err)
+	}
+
+	var claims map[string]json.RawMessage
+	if err := id.Claims(&claims); err != nil {
+		return user.Identity{}, xerrors.ErrSingleSignOnFailed.New(err)
+	}
+
+	siape, ok := siapeClaim(claims[p.config.SIAPEClaim])
+	if !ok {
+		return user.Identity{}, xerrors.ErrSingleSignOnNoSIAPE.New(p.config.SIAPEClaim)
+	}
+
+	var name, email string
+	json.Unmarshal(claims[p.config.NameClaim], &name)
+	json.Unmarshal(claims[p.config.EmailClaim], &email)
+
+	mfa := slices.ContainsFunc(id.AMR, func(method string) bool {
+		return slices.Contains(p.config.MultiFactorMethods, method)
+	})
+
+	return user.Identity{
+		SIAPE:       siape,
+		Name:        name,
+		Email:       email,
+		MultiFactor: mfa,
+	}, nil
+}
+
+func siapeClaim(raw json.RawMessage) (int, bool) {
+	var number int
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number, number > 0
+	}
+
+	var str string
+	if err := json.Unmarshal(raw, &str); err != nil {
+		return 0, false
+	}
+
+	number, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, false
+	}
+
+	return number, number > 0
+}
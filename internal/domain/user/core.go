@@ -4,15 +4,19 @@ import (
 	"github.com/alan-b-lima/almodon/internal/auth"
 	"github.com/alan-b-lima/almodon/internal/domain/promotion"
 	sessionpkg "github.com/alan-b-lima/almodon/internal/domain/session"
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	"github.com/alan-b-lima/almodon/internal/domain/twofactor"
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
 	"github.com/alan-b-lima/almodon/internal/xerrors"
 	"github.com/alan-b-lima/almodon/pkg/errors"
-	"github.com/alan-b-lima/almodon/pkg/hash"
 	"github.com/alan-b-lima/almodon/pkg/opt"
 	"github.com/alan-b-lima/almodon/pkg/uuid"
+	"github.com/alan-b-lima/almodon/pkg/validate"
 )
 
-func List(users Lister, offset, limit int) (Entities, error) {
-	return users.List(offset, limit)
+func List(users Lister, filter Filter, q listing.Query) (Entities, error) {
+	return users.List(filter, q)
 }
 
 func Get(users Getter, uuid uuid.UUID) (Entity, error) {
@@ -32,44 +36,97 @@ func Create(users Creater, siape int, name, email, password string, role auth.Ro
 	return u.UUID(), users.Create(translate(&u))
 }
 
-func Patch(users Patcher, uuid uuid.UUID, name, email, password opt.Opt[string], role opt.Opt[auth.Role]) error {
+func Patch(users Patcher, uuid uuid.UUID, match entity.Precondition, name, email, password opt.Opt[string], role opt.Opt[auth.Role]) error {
 	var pu PartialEntity
 
 	err := errors.Join(
-		some_then(&pu.Name, name, ProcessName),
-		some_then(&pu.Email, email, ProcessEmail),
-		some_then(&pu.Password, password, ProcessPassword),
-		some_then(&pu.Role, role, ProcessRole),
+		validate.At("/name", some_then(&pu.Name, name, ProcessName)),
+		validate.At("/email", some_then(&pu.Email, email, ProcessEmail)),
+		validate.At("/password", some_then(&pu.Password, password, ProcessPassword)),
+		validate.At("/role", some_then(&pu.Role, role, ProcessRole)),
 	)
 	if err != nil {
 		return xerrors.ErrUserUpdate.New(err)
 	}
 
-	return users.Patch(uuid, pu)
+	return users.Patch(uuid, match, pu)
 }
 
-func Delete(users Deleter, uuid uuid.UUID) error {
-	return users.Delete(uuid)
+func Delete(users Deleter, uuid uuid.UUID, match entity.Precondition) error {
+	return users.Delete(uuid, match)
 }
 
-func Authenticate(users GetterBySIAPE, sessions sessionpkg.Creater, siape int, password string) (AuthEntity, error) {
-	res, err := users.GetBySIAPE(siape)
+// Authenticate authenticates the user through the authenticator.
+// Users unknown to the system, but known to the authenticator, are
+// provisioned with the [auth.User] role. If the user has a second
+// factor enabled, the created session is pending, and must be
+// completed through [AuthenticateSecondFactor].
+func Authenticate(users interface {
+	GetterBySIAPE
+	Creater
+}, authn Authenticator, sessions sessionpkg.Creater, lifetimes sessionpkg.Lifetimes, twofactors twofactor.GetterByUser, siape int, password string) (AuthEntity, error) {
+	id, err := authn.Authenticate(siape, password)
 	if err != nil {
 		return AuthEntity{}, err
 	}
 
-	if !hash.Compare(res.Password[:], []byte(password)) {
-		return AuthEntity{}, xerrors.ErrIncorrectPassword
+	return login(users, sessions, lifetimes, twofactors, id)
+}
+
+// AuthenticateSingleSignOn authenticates the user through the code
+// the identity provider redirected them back with, in the same way
+// as [Authenticate]. Users authenticated by the provider with a
+// second factor are not asked for their own.
+func AuthenticateSingleSignOn(users interface {
+	GetterBySIAPE
+	Creater
+}, provider IdentityProvider, sessions sessionpkg.Creater, lifetimes sessionpkg.Lifetimes, twofactors twofactor.GetterByUser, code, verifier, nonce string) (AuthEntity, error) {
+	id, err := provider.Identify(code, verifier, nonce)
+	if err != nil {
+		return AuthEntity{}, err
+	}
+
+	return login(users, sessions, lifetimes, twofactors, id)
+}
+
+// AuthenticateSecondFactor completes a pending session with a code
+// of the second factor of its user, or one of their recovery codes,
+// replacing it with a multi-factor session. Pending sessions failing
+// too many times are deleted, see [sessionpkg.Fail].
+func AuthenticateSecondFactor(sessions interface {
+	sessionpkg.Getter
+	sessionpkg.Creater
+	sessionpkg.Failer
+	sessionpkg.Deleter
+}, lifetimes sessionpkg.Lifetimes, twofactors interface {
+	twofactor.Attempter
+	twofactor.Updater
+}, session uuid.UUID, code string) (AuthEntity, error) {
+	res, err := sessionpkg.Get(sessions, session)
+	if err != nil {
+		return AuthEntity{}, xerrors.ErrUnauthenticatedUser.New(err)
+	}
+
+	if res.Level != sessionpkg.Pending {
+		return AuthEntity{}, xerrors.ErrSessionNotPending
 	}
 
-	sres, err := sessionpkg.CreateAndGet(sessions, res.UUID)
+	if err := twofactor.Verify(twofactors, res.User, code); err != nil {
+		if err := sessionpkg.Fail(sessions, session); err != nil {
+			return AuthEntity{}, err
+		}
+
+		return AuthEntity{}, err
+	}
+
+	sres, err := sessionpkg.CreateAndGet(sessions, lifetimes, res.User, sessionpkg.MultiFactor)
 	if err != nil {
 		return AuthEntity{}, err
 	}
 
 	ares := AuthEntity{
 		UUID:    sres.UUID,
-		User:    res.UUID,
+		User:    sres.User,
 		Expires: sres.Expires,
 	}
 	return ares, nil
@@ -81,11 +138,62 @@ func Actor(users Getter, sessions sessionpkg.Getter, promotions promotion.Getter
 		return auth.NewUnlogged(), xerrors.ErrUnauthenticatedUser.New(err)
 	}
 
-	ures, err := users.Get(res.User)
+	if res.Level == sessionpkg.Pending {
+		return auth.NewUnlogged(), xerrors.ErrUnauthenticatedUser.New(xerrors.ErrSecondFactorPending)
+	}
+
+	role, err := actorRole(users, promotions, res.User)
 	if err != nil {
 		return auth.NewUnlogged(), xerrors.ErrUnauthenticatedUser.New(err)
 	}
 
+	if res.Level != sessionpkg.MultiFactor {
+		role = auth.SingleFactorRole(role)
+	}
+
+	return auth.NewLogged(
+		res.User,
+		role,
+	), nil
+}
+
+// TokenActor resolves a personal access token to the actor of its
+// user, restricted to the scopes of the token and with a role no
+// stronger than the one the token was created with, expiring along
+// with the token.
+func TokenActor(users Getter, tokens interface {
+	token.GetterByDigest
+	token.Toucher
+}, promotions promotion.GetterByUser, secret string) (auth.Actor, error) {
+	res, err := token.Resolve(tokens, secret)
+	if err != nil {
+		return auth.NewUnlogged(), err
+	}
+
+	role, err := actorRole(users, promotions, res.User)
+	if err != nil {
+		return auth.NewUnlogged(), xerrors.ErrTokenInvalid
+	}
+
+	act := auth.NewScoped(
+		res.User,
+		auth.Weaker(auth.DefaultHierarchy, role, res.Role),
+		res.Scopes,
+	)
+
+	if expires, ok := res.Expires.Unwrap(); ok {
+		act = act.WithExpiry(expires)
+	}
+
+	return act, nil
+}
+
+func actorRole(users Getter, promotions promotion.GetterByUser, user uuid.UUID) (auth.Role, error) {
+	ures, err := users.Get(user)
+	if err != nil {
+		return auth.Unlogged, err
+	}
+
 	role := ures.Role
 	if ures.Role == auth.Admin {
 		_, err := promotions.GetByUser(ures.UUID)
@@ -94,10 +202,56 @@ func Actor(users Getter, sessions sessionpkg.Getter, promotions promotion.Getter
 		}
 	}
 
-	return auth.NewLogged(
-		ures.UUID,
-		role,
-	), nil
+	return role, nil
+}
+
+func login(users interface {
+	GetterBySIAPE
+	Creater
+}, sessions sessionpkg.Creater, lifetimes sessionpkg.Lifetimes, twofactors twofactor.GetterByUser, id Identity) (AuthEntity, error) {
+	res, err := users.GetBySIAPE(id.SIAPE)
+	if uerr, ok := errors.AsType[*errors.Error](err); ok && uerr.Kind == errors.NotFound {
+		res, err = provision(users, id)
+	}
+	if err != nil {
+		return AuthEntity{}, err
+	}
+
+	enabled, err := twofactor.Enabled(twofactors, res.UUID)
+	if err != nil {
+		return AuthEntity{}, err
+	}
+
+	level := sessionpkg.SingleFactor
+	switch {
+	case id.MultiFactor:
+		level = sessionpkg.MultiFactor
+	case enabled:
+		level = sessionpkg.Pending
+	}
+
+	sres, err := sessionpkg.CreateAndGet(sessions, lifetimes, res.UUID, level)
+	if err != nil {
+		return AuthEntity{}, err
+	}
+
+	ares := AuthEntity{
+		UUID:              sres.UUID,
+		User:              res.UUID,
+		Expires:           sres.Expires,
+		TwoFactorRequired: level == sessionpkg.Pending,
+	}
+	return ares, nil
+}
+
+func provision(users Creater, id Identity) (Entity, error) {
+	u, err := NewExternal(id.SIAPE, id.Name, id.Email, auth.User)
+	if err != nil {
+		return Entity{}, err
+	}
+
+	res := translate(&u)
+	return res, users.Create(res)
 }
 
 func translate(u *User) Entity {
@@ -2,7 +2,6 @@ package user
 
 import (
 	"regexp"
-	"slices"
 	"unicode/utf8"
 
 	"github.com/alan-b-lima/almodon/internal/auth"
@@ -10,6 +9,7 @@ import (
 	"github.com/alan-b-lima/almodon/pkg/errors"
 	"github.com/alan-b-lima/almodon/pkg/hash"
 	"github.com/alan-b-lima/almodon/pkg/uuid"
+	"github.com/alan-b-lima/almodon/pkg/validate"
 )
 
 type User struct {
@@ -30,11 +30,31 @@ func New(siape int, name, email, password string, role auth.Role) (User, error)
 	}
 
 	err := errors.Join(
-		u.SetSIAPE(siape),
-		u.SetName(name),
-		u.SetEmail(email),
-		errpwd,
-		u.SetRole(role),
+		validate.At("/siape", u.SetSIAPE(siape)),
+		validate.At("/name", u.SetName(name)),
+		validate.At("/email", u.SetEmail(email)),
+		validate.At("/password", errpwd),
+		validate.At("/role", u.SetRole(role)),
+	)
+	if err != nil {
+		return User{}, xerrors.ErrUserCreation.New(err)
+	}
+
+	u.uuid = uuid.NewUUIDv7()
+	return u, nil
+}
+
+// NewExternal creates a user authenticated elsewhere, such as by the
+// institutional directory, who has no local password and thus cannot
+// authenticate locally.
+func NewExternal(siape int, name, email string, role auth.Role) (User, error) {
+	var u User
+
+	err := errors.Join(
+		validate.At("/siape", u.SetSIAPE(siape)),
+		validate.At("/name", u.SetName(name)),
+		validate.At("/email", u.SetEmail(email)),
+		validate.At("/role", u.SetRole(role)),
 	)
 	if err != nil {
 		return User{}, xerrors.ErrUserCreation.New(err)
@@ -61,9 +81,13 @@ func ProcessSiape(siape int) (int, error) {
 	return siape, nil
 }
 
+var nameRules = []validate.Rule[string]{
+	validate.Required[string](xerrors.ErrNameEmpty),
+}
+
 func ProcessName(name string) (string, error) {
-	if name == "" {
-		return "", xerrors.ErrNameEmpty
+	if err := validate.Value(name, nameRules...); err != nil {
+		return "", err
 	}
 
 	return name, nil
@@ -71,37 +95,40 @@ func ProcessName(name string) (string, error) {
 
 var reEmail = regexp.MustCompile(`^[0-9A-Za-z_%+-]+(\.[0-9A-Za-z_%+-]+)*@[0-9A-Za-z-]+(\.[0-9A-Za-zA-Z-]+)*\.[A-Za-z]{2,}$`)
 
+var emailRules = []validate.Rule[string]{
+	validate.Match(reEmail, xerrors.ErrEmailInvalid),
+}
+
 func ProcessEmail(email string) (string, error) {
-	if !reEmail.MatchString(email) {
-		return "", xerrors.ErrEmailInvalid
+	if err := validate.Value(email, emailRules...); err != nil {
+		return "", err
 	}
 
 	return email, nil
 }
 
-func ProcessPassword(password string) ([60]byte, error) {
-	if len(password) < 8 {
-		return [60]byte{}, xerrors.ErrPasswordTooShort
-	}
-
-	if len(password) > 64 {
-		return [60]byte{}, xerrors.ErrPasswordTooLong
-	}
+// passwords are limited in bytes, as they are hashed with bcrypt,
+// which takes at most 72 of them
+var passwordRules = []validate.Rule[string]{
+	validate.MinLength(8, xerrors.ErrPasswordTooShort),
+	validate.MaxLength(64, xerrors.ErrPasswordTooLong),
+	validate.Match(rePasswordEdges, xerrors.ErrPasswordLeadOrTrailWhitespace),
+	func(password string) error {
+		for _, rune := range password {
+			if rune < ' ' || !utf8.ValidRune(rune) {
+				return xerrors.ErrPasswordIllegalCharacters
+			}
+		}
 
-	switch password[0] {
-	case ' ', '\t', '\n', '\r':
-		return [60]byte{}, xerrors.ErrPasswordLeadOrTrailWhitespace
-	}
+		return nil
+	},
+}
 
-	switch password[len(password)-1] {
-	case ' ', '\t', '\n', '\r':
-		return [60]byte{}, xerrors.ErrPasswordLeadOrTrailWhitespace
-	}
+var rePasswordEdges = regexp.MustCompile(`(?s)^[^ \t\n\r](.*[^ \t\n\r])?$`)
 
-	for _, rune := range password {
-		if rune < ' ' || !utf8.ValidRune(rune) {
-			return [60]byte{}, xerrors.ErrPasswordIllegalCharacters
-		}
+func ProcessPassword(password string) ([60]byte, error) {
+	if err := validate.Value(password, passwordRules...); err != nil {
+		return [60]byte{}, err
 	}
 
 	hash, err := hash.Hash([]byte(password))
@@ -114,9 +141,13 @@ func ProcessPassword(password string) ([60]byte, error) {
 
 var acceptRoles = [...]auth.Role{auth.User, auth.Admin, auth.Chief}
 
+var roleRules = []validate.Rule[auth.Role]{
+	validate.OneOf(acceptRoles[:], xerrors.ErrRoleInvalid.New(acceptRoles)),
+}
+
 func ProcessRole(role auth.Role) (auth.Role, error) {
-	if !slices.Contains(acceptRoles[:], role) {
-		return 0, xerrors.ErrRoleInvalid.New(acceptRoles)
+	if err := validate.Value(role, roleRules...); err != nil {
+		return 0, err
 	}
 
 	return role, nil
@@ -1,9 +1,12 @@
 package user
 
 import (
+	"strings"
 	"time"
 
 	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
 	"github.com/alan-b-lima/almodon/pkg/opt"
 	"github.com/alan-b-lima/almodon/pkg/uuid"
 )
@@ -19,7 +22,7 @@ type Repository interface {
 
 type (
 	Lister interface {
-		List(offset, limit int) (Entities, error)
+		List(filter Filter, q listing.Query) (Entities, error)
 	}
 
 	Getter interface {
@@ -34,23 +37,27 @@ type (
 		Create(Entity) error
 	}
 
+	// Patcher patches users, provided their version matches the
+	// precondition, incrementing it.
 	Patcher interface {
-		Patch(uuid.UUID, PartialEntity) error
+		Patch(uuid.UUID, entity.Precondition, PartialEntity) error
 	}
 
+	// Deleter deletes users, provided their version matches the
+	// precondition.
 	Deleter interface {
-		Delete(uuid.UUID) error
+		Delete(uuid.UUID, entity.Precondition) error
 	}
 )
 
 type (
-	Entities struct {
-		Offset       int
-		Length       int
-		Records      []Entity
-		TotalRecords int
+	Filter struct {
+		Role opt.Opt[auth.Role]
+		Name opt.Opt[string]
 	}
 
+	Entities = listing.Page[Entity]
+
 	Entity struct {
 		UUID     uuid.UUID
 		SIAPE    int
@@ -58,6 +65,7 @@ type (
 		Email    string
 		Password [60]byte
 		Role     auth.Role
+		Version  entity.Version
 	}
 
 	PartialEntity struct {
@@ -69,8 +77,35 @@ type (
 	}
 
 	AuthEntity struct {
-		UUID    uuid.UUID
-		User    uuid.UUID
-		Expires time.Time
+		UUID              uuid.UUID
+		User              uuid.UUID
+		Expires           time.Time
+		TwoFactorRequired bool
 	}
 )
+
+// Listing describes the fields users may be sorted by.
+var Listing = listing.Schema[Entity]{
+	ID: func(e *Entity) uuid.UUID { return e.UUID },
+	Fields: map[string]func(*Entity) listing.Key{
+		"siape": func(e *Entity) listing.Key { return listing.Int(int64(e.SIAPE)) },
+		"name":  func(e *Entity) listing.Key { return listing.String(strings.ToLower(e.Name)) },
+		"email": func(e *Entity) listing.Key { return listing.String(e.Email) },
+		"role":  func(e *Entity) listing.Key { return listing.Int(int64(e.Role)) },
+	},
+}
+
+// Matches returns whether the user satisfies all the criteria set in
+// the filter, names are matched by containing the given one,
+// regardless of case.
+func (f *Filter) Matches(e *Entity) bool {
+	if role, ok := f.Role.Unwrap(); ok && e.Role != role {
+		return false
+	}
+
+	if name, ok := f.Name.Unwrap(); ok && !strings.Contains(strings.ToLower(e.Name), strings.ToLower(name)) {
+		return false
+	}
+
+	return true
+}
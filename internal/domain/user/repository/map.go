@@ -1,15 +1,17 @@
 package userrepo
 
 import (
-	"cmp"
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"sync"
 	"unsafe"
 
 	"github.com/alan-b-lima/almodon/internal/auth"
 	"github.com/alan-b-lima/almodon/internal/domain/user"
+	entitypkg "github.com/alan-b-lima/almodon/internal/support/entity"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
 	"github.com/alan-b-lima/almodon/internal/xerrors"
 	"github.com/alan-b-lima/almodon/pkg/opt"
 	"github.com/alan-b-lima/almodon/pkg/uuid"
@@ -64,6 +66,12 @@ func (m *Map) init() error {
 	for i, record := range m.repo {
 		m.uuidIndex[record.UUID] = i
 		m.siapeIndex[record.SIAPE] = i
+
+		// records persisted before versions were kept are taken as
+		// just created
+		if record.Version == 0 {
+			m.repo[i].Version = 1
+		}
 	}
 
 	return nil
@@ -77,7 +85,7 @@ func (m *Map) Close() error {
 		return nil
 	}
 
-	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY, 0o666)
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY, 0o600)
 	if err != nil {
 		return err
 	}
@@ -95,29 +103,25 @@ func (m *Map) Close() error {
 	return nil
 }
 
-func (m *Map) List(offset, limit int) (user.Entities, error) {
-	defer m.mu.RUnlock()
-	m.mu.RLock()
-
-	lo := clamp(0, offset, len(m.repo))
-	hi := clamp(0, offset+limit, len(m.repo))
+// Ping reports whether the data file can still be written.
+func (m *Map) Ping() error {
+	if m.datapath == "" {
+		return nil
+	}
 
-	if lo >= hi {
-		return user.Entities{
-			Records:      []user.Entity{},
-			TotalRecords: len(m.repo),
-		}, nil
+	f, err := os.OpenFile(m.datapath, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
 	}
 
-	res := make([]user.Entity, hi-lo)
-	copy(res, m.repo[lo:hi])
+	return f.Close()
+}
 
-	return user.Entities{
-		Offset:       lo,
-		Length:       len(res),
-		Records:      res,
-		TotalRecords: len(m.repo),
-	}, nil
+func (m *Map) List(filter user.Filter, q listing.Query) (user.Entities, error) {
+	defer m.mu.RUnlock()
+	m.mu.RLock()
+
+	return user.Listing.List(m.repo, filter.Matches, q)
 }
 
 func (m *Map) Get(uuid uuid.UUID) (user.Entity, error) {
@@ -152,6 +156,8 @@ func (m *Map) Create(user user.Entity) error {
 		return xerrors.ErrSiapeTaken
 	}
 
+	user.Version = 1
+
 	m.uuidIndex[user.UUID] = len(m.repo)
 	m.siapeIndex[user.SIAPE] = len(m.repo)
 	m.repo = append(m.repo, user)
@@ -159,7 +165,7 @@ func (m *Map) Create(user user.Entity) error {
 	return nil
 }
 
-func (m *Map) Patch(uuid uuid.UUID, user user.PartialEntity) error {
+func (m *Map) Patch(uuid uuid.UUID, match entitypkg.Precondition, user user.PartialEntity) error {
 	defer m.mu.Unlock()
 	m.mu.Lock()
 
@@ -169,31 +175,45 @@ func (m *Map) Patch(uuid uuid.UUID, user user.PartialEntity) error {
 	}
 
 	u := &m.repo[index]
+	if !match.Matches(u.Version) {
+		return xerrors.ErrVersionMismatch
+	}
+
+	if role, ok := user.Role.Unwrap(); ok && role != u.Role {
+		if u.Role == auth.Chief && !enough_chiefs(m) {
+			return xerrors.ErrNotEnoughChiefs
+		}
 
-	role, ok := user.Role.Unwrap()
-	if ok && role != u.Role && u.Role == auth.Chief && !enough_chiefs(m) {
-		return xerrors.ErrNotEnoughChiefs
-	} else {
 		u.Role = role
 	}
 
 	some_then(&u.Name, user.Name)
 	some_then(&u.Email, user.Email)
 	some_then(&u.Password, user.Password)
+	u.Version++
 
 	return nil
 }
 
-func (m *Map) Delete(uuid uuid.UUID) error {
+// Delete deletes the user, if there. Users not there match no given
+// precondition, as they have no version at all.
+func (m *Map) Delete(uuid uuid.UUID, match entitypkg.Precondition) error {
 	defer m.mu.Unlock()
 	m.mu.Lock()
 
 	index, in := m.uuidIndex[uuid]
 	if !in {
+		if match.Given() {
+			return xerrors.ErrVersionMismatch
+		}
 		return nil
 	}
 
 	u := &m.repo[index]
+	if !match.Matches(u.Version) {
+		return xerrors.ErrVersionMismatch
+	}
+
 	if u.Role == auth.Chief && !enough_chiefs(m) {
 		return xerrors.ErrNotEnoughChiefs
 	}
@@ -201,8 +221,11 @@ func (m *Map) Delete(uuid uuid.UUID) error {
 	delete(m.uuidIndex, u.UUID)
 	delete(m.siapeIndex, u.SIAPE)
 
-	m.repo[index] = m.repo[len(m.repo)-1]
-	m.repo = m.repo[:len(m.repo)-1]
+	m.repo = slices.Delete(m.repo, index, index+1)
+	for i := index; i < len(m.repo); i++ {
+		m.uuidIndex[m.repo[i].UUID] = i
+		m.siapeIndex[m.repo[i].SIAPE] = i
+	}
 
 	return nil
 }
@@ -231,17 +254,14 @@ func some_then[F any](dst *F, src opt.Opt[F]) {
 	*dst = val
 }
 
-func clamp[T cmp.Ordered](mn, val, mx T) T {
-	return min(max(mn, val), mx)
-}
-
 type entity struct {
-	UUID     uuid.UUID `json:"uuid"`
-	SIAPE    int       `json:"siape"`
-	Name     string    `json:"name"`
-	Email    string    `json:"email"`
-	Password pwd       `json:"password"`
-	Role     role      `json:"role"`
+	UUID     uuid.UUID         `json:"uuid"`
+	SIAPE    int               `json:"siape"`
+	Name     string            `json:"name"`
+	Email    string            `json:"email"`
+	Password pwd               `json:"password"`
+	Role     role              `json:"role"`
+	Version  entitypkg.Version `json:"version"`
 }
 
 type (
@@ -1,213 +1,169 @@
 package users
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
+	"strings"
 
 	"github.com/alan-b-lima/almodon/internal/domain/user"
 	"github.com/alan-b-lima/almodon/internal/support/resource"
 	"github.com/alan-b-lima/almodon/internal/xerrors"
-	"github.com/alan-b-lima/almodon/pkg/uuid"
 )
 
 type Resource struct {
 	http.ServeMux
-	Users user.Service
+	Users   user.Service
+	Cookies resource.CookiePolicy
 }
 
-func New(users user.Service) http.Handler {
-	rc := Resource{Users: users}
+func New(users user.Service, cookies resource.CookiePolicy) http.Handler {
+	rc := Resource{Users: users, Cookies: cookies}
 
-	routes := map[string]http.HandlerFunc{
-		"GET /users/{$}":           rc.List,
-		"GET /users/{uuid}":        rc.Get,
-		"GET /users/siape/{siape}": rc.GetBySIAPE,
-		"POST /users/{$}":          rc.Create,
-		"PATCH /users/{uuid}":      rc.Patch,
-		"DELETE /users/{uuid}":     rc.Delete,
-		"POST /users/auth/{$}":     rc.Authenticate,
-		"GET /users/me/{$}":        rc.Me,
-		"/":                        resource.NotFound,
-	}
-
-	for route, handler := range routes {
-		rc.Handle(route, handler)
-	}
-
-	return &rc
-}
-
-func (rc *Resource) List(w http.ResponseWriter, r *http.Request) {
-	act, err := resource.Session(rc.Users, r)
-	if err != nil {
-		resource.WriteJsonError(w, err)
-		return
-	}
+	handlers := map[string]http.HandlerFunc{
+		"listUsers":                resource.Endpoint(users, http.StatusOK, users.List),
+		"getUser":                  resource.Endpoint(users, http.StatusOK, users.Get),
+		"getUserBySIAPE":           resource.Endpoint(users, http.StatusOK, users.GetBySIAPE),
+		"createUser":               resource.Endpoint(users, http.StatusCreated, users.Create),
+		"patchUser":                resource.Action(users, users.Patch),
+		"deleteUser":               resource.Action(users, users.Delete),
+		"authenticate":             rc.Authenticate,
+		"authenticateSecondFactor": rc.AuthenticateSecondFactor,
+		"me":                       rc.Me,
 
-	req := user.ListRequest{Offset: 0, Limit: 10}
-	if err := resource.QueryParams(r.URL.Query(), &req); err != nil {
-		resource.WriteJsonError(w, err)
-		return
-	}
-
-	res, err := rc.Users.List(act, req)
-	if err != nil {
-		resource.WriteJsonError(w, err)
-		return
-	}
-
-	if err := resource.EncodeJSON(&res, http.StatusOK, w, r); err != nil {
-		resource.WriteJsonError(w, err)
-		return
-	}
-}
+		"updateUserPassword": resource.Action(users, users.UpdatePassword),
+		"updateUserRole":     resource.Action(users, users.UpdateRole),
 
-func (rc *Resource) Get(w http.ResponseWriter, r *http.Request) {
-	act, err := resource.Session(rc.Users, r)
-	if err != nil {
-		resource.WriteJsonError(w, err)
-		return
-	}
+		"beginSingleSignOn":    rc.BeginSingleSignOn,
+		"completeSingleSignOn": rc.CompleteSingleSignOn,
 
-	uuid, err := uuid.FromString(r.PathValue("uuid"))
-	if err != nil {
-		resource.WriteJsonError(w, xerrors.ErrBadUUID)
-		return
+		"enrollTwoFactor":  resource.Endpoint(users, http.StatusCreated, users.EnrollTwoFactor),
+		"confirmTwoFactor": resource.Endpoint(users, http.StatusOK, users.ConfirmTwoFactor),
+		"disableTwoFactor": resource.Action(users, users.DisableTwoFactor),
 	}
-	req := user.GetRequest{UUID: uuid}
 
-	res, err := rc.Users.Get(act, req)
-	if err != nil {
-		resource.WriteJsonError(w, err)
-		return
+	for _, route := range Routes {
+		rc.Handle(route.Pattern, resource.Routed(handlers[route.ID]))
 	}
+	rc.Handle("/", resource.Routed(http.HandlerFunc(resource.NotFound)))
 
-	if err := resource.EncodeJSON(&res, http.StatusOK, w, r); err != nil {
-		resource.WriteJsonError(w, err)
-		return
-	}
+	return &rc
 }
 
-func (rc *Resource) GetBySIAPE(w http.ResponseWriter, r *http.Request) {
-	act, err := resource.Session(rc.Users, r)
-	if err != nil {
+func (rc *Resource) Authenticate(w http.ResponseWriter, r *http.Request) {
+	var req user.AuthRequest
+	if err := resource.DecodeJSON(&req, r); err != nil {
 		resource.WriteJsonError(w, err)
 		return
 	}
 
-	siape, err := strconv.Atoi(r.PathValue("siape"))
+	res, err := rc.Users.Authenticate(req)
 	if err != nil {
 		resource.WriteJsonError(w, err)
 		return
 	}
-	req := user.GetBySIAPERequest{SIAPE: siape}
 
-	res, err := rc.Users.GetBySIAPE(act, req)
-	if err != nil {
-		resource.WriteJsonError(w, err)
-		return
-	}
+	resource.SetSession(w, rc.Cookies, res.UUID, res.Expires)
 
-	if err := resource.EncodeJSON(&res, http.StatusOK, w, r); err != nil {
+	if err := resource.EncodeJSON(&res, http.StatusCreated, w, r); err != nil {
 		resource.WriteJsonError(w, err)
 		return
 	}
 }
 
-func (rc *Resource) Create(w http.ResponseWriter, r *http.Request) {
-	act, err := resource.Session(rc.Users, r)
+func (rc *Resource) AuthenticateSecondFactor(w http.ResponseWriter, r *http.Request) {
+	session, err := resource.SessionID(r)
 	if err != nil {
 		resource.WriteJsonError(w, err)
 		return
 	}
 
-	var req user.CreateRequest
-
+	req := user.SecondFactorRequest{Session: session}
 	if err := resource.DecodeJSON(&req, r); err != nil {
 		resource.WriteJsonError(w, err)
 		return
 	}
 
-	uuid, err := rc.Users.Create(act, req)
+	res, err := rc.Users.AuthenticateSecondFactor(req)
 	if err != nil {
 		resource.WriteJsonError(w, err)
 		return
 	}
 
-	if err := resource.EncodeJSON(&uuid, http.StatusCreated, w, r); err != nil {
+	resource.SetSession(w, rc.Cookies, res.UUID, res.Expires)
+
+	if err := resource.EncodeJSON(&res, http.StatusCreated, w, r); err != nil {
 		resource.WriteJsonError(w, err)
 		return
 	}
 }
 
-func (rc *Resource) Patch(w http.ResponseWriter, r *http.Request) {
-	act, err := resource.Session(rc.Users, r)
-	if err != nil {
-		resource.WriteJsonError(w, err)
-		return
-	}
+// SingleSignOnCookie holds the state, nonce and verifier of a single
+// sign-on in progress, binding it to the user agent that began it.
+const SingleSignOnCookie = "sso"
 
-	uuid, err := uuid.FromString(r.PathValue("uuid"))
-	if err != nil {
-		resource.WriteJsonError(w, xerrors.ErrBadUUID)
-		return
-	}
-	req := user.PatchRequest{UUID: uuid}
+const _SingleSignOnMaxAge = 10 * 60
 
-	if err := resource.DecodeJSON(&req, r); err != nil {
+func (rc *Resource) BeginSingleSignOn(w http.ResponseWriter, r *http.Request) {
+	res, err := rc.Users.BeginSingleSignOn()
+	if err != nil {
 		resource.WriteJsonError(w, err)
 		return
 	}
 
-	if err := rc.Users.Patch(act, req); err != nil {
-		resource.WriteJsonError(w, err)
-		return
-	}
+	// the provider redirects back cross-site, so the cookie must be
+	// sent on top-level navigations, whatever the policy
+	http.SetCookie(w, rc.Cookies.Apply(&http.Cookie{
+		Name:     SingleSignOnCookie,
+		Value:    strings.Join([]string{res.State, res.Nonce, res.Verifier}, "."),
+		MaxAge:   _SingleSignOnMaxAge,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}))
 
-	w.WriteHeader(http.StatusNoContent)
+	http.Redirect(w, r, res.URL, http.StatusFound)
 }
 
-func (rc *Resource) Delete(w http.ResponseWriter, r *http.Request) {
-	act, err := resource.Session(rc.Users, r)
-	if err != nil {
-		resource.WriteJsonError(w, err)
+func (rc *Resource) CompleteSingleSignOn(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if code := query.Get("error"); code != "" {
+		resource.WriteJsonError(w, xerrors.ErrSingleSignOnFailed.New(errors.New(code+": "+query.Get("error_description"))))
 		return
 	}
 
-	uuid, err := uuid.FromString(r.PathValue("uuid"))
-	if err != nil {
-		resource.WriteJsonError(w, xerrors.ErrBadUUID)
-		return
-	}
-	req := user.DeleteRequest{UUID: uuid}
-
-	if err := rc.Users.Delete(act, req); err != nil {
+	var req user.SingleSignOnCallbackRequest
+	if err := resource.QueryParams(query, &req); err != nil {
 		resource.WriteJsonError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
-}
-
-func (rc *Resource) Authenticate(w http.ResponseWriter, r *http.Request) {
-	var req user.AuthRequest
-	if err := resource.DecodeJSON(&req, r); err != nil {
-		resource.WriteJsonError(w, err)
-		return
+	if c, err := r.Cookie(SingleSignOnCookie); err == nil {
+		parts := strings.Split(c.Value, ".")
+		if len(parts) == 3 {
+			req.ExpectedState, req.Nonce, req.Verifier = parts[0], parts[1], parts[2]
+		}
 	}
 
-	res, err := rc.Users.Authenticate(req)
+	// the state is single use, whatever the outcome
+	http.SetCookie(w, rc.Cookies.Apply(&http.Cookie{
+		Name:     SingleSignOnCookie,
+		MaxAge:   -1,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}))
+
+	res, err := rc.Users.CompleteSingleSignOn(req)
 	if err != nil {
 		resource.WriteJsonError(w, err)
 		return
 	}
 
-	resource.SetSession(w, res.UUID, res.Expires)
+	resource.SetSession(w, rc.Cookies, res.UUID, res.Expires)
 
-	if err := resource.EncodeJSON(&res, http.StatusCreated, w, r); err != nil {
-		resource.WriteJsonError(w, err)
-		return
-	}
+	// the user agent arrives here through a redirection of the
+	// provider, so it is sent back to the application
+	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
 func (rc *Resource) Me(w http.ResponseWriter, r *http.Request) {
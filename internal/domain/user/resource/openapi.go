@@ -0,0 +1,37 @@
+package users
+
+import (
+	"net/http"
+
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/pkg/openapi"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// Routes describes the routes of the resource, under whose patterns
+// [New] registers the handlers of their operations, by ID.
+var Routes = []openapi.Route{
+	{Pattern: "GET /users/{$}", ID: "listUsers", Summary: "Lists users", Tags: _Tags, Query: user.ListRequest{}, Response: user.ListResponse{}},
+	{Pattern: "GET /users/{uuid}", ID: "getUser", Summary: "Gets a user", Tags: _Tags, Params: _UUID, Response: user.Response{}},
+	{Pattern: "GET /users/siape/{siape}", ID: "getUserBySIAPE", Summary: "Gets a user by SIAPE", Tags: _Tags, Params: map[string]any{"siape": 0}, Response: user.Response{}},
+	{Pattern: "POST /users/{$}", ID: "createUser", Summary: "Creates a user", Tags: _Tags, Body: user.CreateRequest{}, Status: http.StatusCreated, Response: uuid.UUID{}},
+	{Pattern: "PATCH /users/{uuid}", ID: "patchUser", Summary: "Patches a user", Tags: _Tags, Params: _UUID, Headers: user.PatchRequest{}, Body: user.PatchRequest{}},
+	{Pattern: "PUT /users/{uuid}/password/{$}", ID: "updateUserPassword", Summary: "Updates the password of a user", Tags: _Tags, Params: _UUID, Headers: user.UpdatePasswordRequest{}, Body: user.UpdatePasswordRequest{}},
+	{Pattern: "PUT /users/{uuid}/role/{$}", ID: "updateUserRole", Summary: "Updates the role of a user", Tags: _Tags, Params: _UUID, Headers: user.UpdateRoleRequest{}, Body: user.UpdateRoleRequest{}},
+	{Pattern: "DELETE /users/{uuid}", ID: "deleteUser", Summary: "Deletes a user", Tags: _Tags, Params: _UUID, Headers: user.DeleteRequest{}},
+	{Pattern: "POST /users/auth/{$}", ID: "authenticate", Summary: "Logs in, setting the session cookie", Tags: _Tags, Body: user.AuthRequest{}, Status: http.StatusCreated, Response: user.AuthResponse{}},
+	{Pattern: "POST /users/auth/2fa/{$}", ID: "authenticateSecondFactor", Summary: "Completes a login with a second factor", Tags: _Tags, Body: user.SecondFactorRequest{}, Status: http.StatusCreated, Response: user.AuthResponse{}},
+	{Pattern: "GET /users/me/{$}", ID: "me", Summary: "Gets the logged in user", Tags: _Tags, Response: user.Response{}},
+
+	{Pattern: "GET /users/auth/sso/{$}", ID: "beginSingleSignOn", Summary: "Redirects to the identity provider", Tags: _Tags, Status: http.StatusFound},
+	{Pattern: "GET /users/auth/sso/callback/{$}", ID: "completeSingleSignOn", Summary: "Completes a login through the identity provider", Tags: _Tags, Query: user.SingleSignOnCallbackRequest{}, Status: http.StatusSeeOther},
+
+	{Pattern: "POST /users/{uuid}/2fa/{$}", ID: "enrollTwoFactor", Summary: "Enrolls a second factor", Tags: _Tags, Params: _UUID, Status: http.StatusCreated, Response: user.EnrollTwoFactorResponse{}},
+	{Pattern: "POST /users/{uuid}/2fa/confirm/{$}", ID: "confirmTwoFactor", Summary: "Confirms an enrolled second factor", Tags: _Tags, Params: _UUID, Body: user.ConfirmTwoFactorRequest{}, Response: user.ConfirmTwoFactorResponse{}},
+	{Pattern: "DELETE /users/{uuid}/2fa/{$}", ID: "disableTwoFactor", Summary: "Disables the second factor", Tags: _Tags, Params: _UUID, Body: user.DisableTwoFactorRequest{}},
+}
+
+var (
+	_Tags = []string{"users"}
+	_UUID = map[string]any{"uuid": uuid.UUID{}}
+)
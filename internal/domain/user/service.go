@@ -19,10 +19,18 @@ type Service interface {
 
 	Delete(act auth.Actor, req DeleteRequest) error
 
+	EnrollTwoFactor(act auth.Actor, req EnrollTwoFactorRequest) (EnrollTwoFactorResponse, error)
+	ConfirmTwoFactor(act auth.Actor, req ConfirmTwoFactorRequest) (ConfirmTwoFactorResponse, error)
+	DisableTwoFactor(act auth.Actor, req DisableTwoFactorRequest) error
+
 	Authenticate(req AuthRequest) (AuthResponse, error)
+	AuthenticateSecondFactor(req SecondFactorRequest) (AuthResponse, error)
+	BeginSingleSignOn() (SingleSignOnResponse, error)
+	CompleteSingleSignOn(req SingleSignOnCallbackRequest) (AuthResponse, error)
 	Gatekeeper
 }
 
 type Gatekeeper interface {
 	Actor(session uuid.UUID) (auth.Actor, error)
+	TokenActor(token string) (auth.Actor, error)
 }
@@ -0,0 +1,98 @@
+package userserve
+
+import (
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// AuditedService records the mutations performed through the service
+// it wraps. It is meant to be wrapped by an [AuthService], so that
+// only allowed actions are recorded. Failing to record an action fails
+// the request with the error, though the action has been performed by
+// then, so that it is not taken as fully done.
+type AuditedService struct {
+	user.Service
+	recorder audit.Recorder
+}
+
+func NewAudited(service user.Service, recorder audit.Recorder) user.Service {
+	return &AuditedService{
+		Service:  service,
+		recorder: recorder,
+	}
+}
+
+func (s *AuditedService) Create(act auth.Actor, req user.CreateRequest) (uuid.UUID, error) {
+	uuid, err := s.Service.Create(act, req)
+	if err != nil {
+		return uuid, err
+	}
+
+	return uuid, s.recorder.Record(act, "users:create", uuid, nil, s.state(act, uuid))
+}
+
+func (s *AuditedService) Patch(act auth.Actor, req user.PatchRequest) error {
+	before := s.state(act, req.UUID)
+	if err := s.Service.Patch(act, req); err != nil {
+		return err
+	}
+
+	return s.recorder.Record(act, "users:patch", req.UUID, before, s.state(act, req.UUID))
+}
+
+func (s *AuditedService) UpdatePassword(act auth.Actor, req user.UpdatePasswordRequest) error {
+	if err := s.Service.UpdatePassword(act, req); err != nil {
+		return err
+	}
+
+	// passwords, even hashed, are kept out of the records
+	return s.recorder.Record(act, "users:update-password", req.UUID, nil, nil)
+}
+
+func (s *AuditedService) UpdateRole(act auth.Actor, req user.UpdateRoleRequest) error {
+	before := s.state(act, req.UUID)
+	if err := s.Service.UpdateRole(act, req); err != nil {
+		return err
+	}
+
+	return s.recorder.Record(act, "users:update-role", req.UUID, before, s.state(act, req.UUID))
+}
+
+func (s *AuditedService) Delete(act auth.Actor, req user.DeleteRequest) error {
+	before := s.state(act, req.UUID)
+	if err := s.Service.Delete(act, req); err != nil {
+		return err
+	}
+
+	return s.recorder.Record(act, "users:delete", req.UUID, before, nil)
+}
+
+func (s *AuditedService) ConfirmTwoFactor(act auth.Actor, req user.ConfirmTwoFactorRequest) (user.ConfirmTwoFactorResponse, error) {
+	res, err := s.Service.ConfirmTwoFactor(act, req)
+	if err != nil {
+		return res, err
+	}
+
+	return res, s.recorder.Record(act, "users:confirm-two-factor", req.UUID, nil, nil)
+}
+
+func (s *AuditedService) DisableTwoFactor(act auth.Actor, req user.DisableTwoFactorRequest) error {
+	if err := s.Service.DisableTwoFactor(act, req); err != nil {
+		return err
+	}
+
+	return s.recorder.Record(act, "users:disable-two-factor", req.UUID, nil, nil)
+}
+
+// state returns the user as seen through the service, nil if it
+// cannot be seen.
+func (s *AuditedService) state(act auth.Actor, uuid uuid.UUID) any {
+	res, err := s.Service.Get(act, user.GetRequest{UUID: uuid})
+	if err != nil {
+		return nil
+	}
+
+	return res
+}
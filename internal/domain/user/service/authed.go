@@ -9,18 +9,18 @@ import (
 
 type AuthService struct {
 	user.Service
+	policy *auth.Policy
 }
 
-func New(service user.Service) user.Service {
+func New(service user.Service, policy *auth.Policy) user.Service {
 	return &AuthService{
 		Service: service,
+		policy:  policy,
 	}
 }
 
-var permChief = auth.Permit(auth.Chief)
-
 func (s *AuthService) List(act auth.Actor, req user.ListRequest) (user.ListResponse, error) {
-	if err := service.Authorize(permChief, act); err != nil {
+	if err := service.Authorize(s.policy, act, "users:list", auth.Resource{}); err != nil {
 		return user.ListResponse{}, err
 	}
 
@@ -28,15 +28,10 @@ func (s *AuthService) List(act auth.Actor, req user.ListRequest) (user.ListRespo
 }
 
 func (s *AuthService) Get(act auth.Actor, req user.GetRequest) (user.Response, error) {
-	if act.User() == req.UUID {
-		goto Do
-	}
-
-	if err := service.Authorize(permChief, act); err != nil {
+	if err := service.Authorize(s.policy, act, "users:get", auth.Resource{Subject: req.UUID}); err != nil {
 		return user.Response{}, err
 	}
 
-Do:
 	return s.Service.Get(act, req)
 }
 
@@ -46,20 +41,15 @@ func (s *AuthService) GetBySIAPE(act auth.Actor, req user.GetBySIAPERequest) (us
 		return user.Response{}, err
 	}
 
-	if act.User() == res.UUID {
-		goto Do
-	}
-
-	if err := service.Authorize(permChief, act); err != nil {
+	if err := service.Authorize(s.policy, act, "users:get", auth.Resource{Subject: res.UUID}); err != nil {
 		return user.Response{}, err
 	}
 
-Do:
 	return res, nil
 }
 
 func (s *AuthService) Create(act auth.Actor, req user.CreateRequest) (uuid.UUID, error) {
-	if err := service.Authorize(permChief, act); err != nil {
+	if err := service.Authorize(s.policy, act, "users:create", auth.Resource{}); err != nil {
 		return uuid.UUID{}, err
 	}
 
@@ -67,33 +57,23 @@ func (s *AuthService) Create(act auth.Actor, req user.CreateRequest) (uuid.UUID,
 }
 
 func (s *AuthService) Patch(act auth.Actor, req user.PatchRequest) error {
-	if act.User() == req.UUID {
-		goto Do
-	}
-
-	if err := service.Authorize(permChief, act); err != nil {
+	if err := service.Authorize(s.policy, act, "users:patch", auth.Resource{Subject: req.UUID}); err != nil {
 		return err
 	}
 
-Do:
 	return s.Service.Patch(act, req)
 }
 
 func (s *AuthService) UpdatePassword(act auth.Actor, req user.UpdatePasswordRequest) error {
-	if act.User() == req.UUID {
-		goto Do
-	}
-
-	if err := service.Authorize(permChief, act); err != nil {
+	if err := service.Authorize(s.policy, act, "users:update-password", auth.Resource{Subject: req.UUID}); err != nil {
 		return err
 	}
 
-Do:
 	return s.Service.UpdatePassword(act, req)
 }
 
 func (s *AuthService) UpdateRole(act auth.Actor, req user.UpdateRoleRequest) error {
-	if err := service.Authorize(permChief, act); err != nil {
+	if err := service.Authorize(s.policy, act, "users:update-role", auth.Resource{Subject: req.UUID}); err != nil {
 		return err
 	}
 
@@ -101,14 +81,33 @@ func (s *AuthService) UpdateRole(act auth.Actor, req user.UpdateRoleRequest) err
 }
 
 func (s *AuthService) Delete(act auth.Actor, req user.DeleteRequest) error {
-	if act.User() == req.UUID {
-		goto Do
+	if err := service.Authorize(s.policy, act, "users:delete", auth.Resource{Subject: req.UUID}); err != nil {
+		return err
+	}
+
+	return s.Service.Delete(act, req)
+}
+
+func (s *AuthService) EnrollTwoFactor(act auth.Actor, req user.EnrollTwoFactorRequest) (user.EnrollTwoFactorResponse, error) {
+	if err := service.Authorize(s.policy, act, "users:enroll-two-factor", auth.Resource{Subject: req.UUID}); err != nil {
+		return user.EnrollTwoFactorResponse{}, err
 	}
 
-	if err := service.Authorize(permChief, act); err != nil {
+	return s.Service.EnrollTwoFactor(act, req)
+}
+
+func (s *AuthService) ConfirmTwoFactor(act auth.Actor, req user.ConfirmTwoFactorRequest) (user.ConfirmTwoFactorResponse, error) {
+	if err := service.Authorize(s.policy, act, "users:confirm-two-factor", auth.Resource{Subject: req.UUID}); err != nil {
+		return user.ConfirmTwoFactorResponse{}, err
+	}
+
+	return s.Service.ConfirmTwoFactor(act, req)
+}
+
+func (s *AuthService) DisableTwoFactor(act auth.Actor, req user.DisableTwoFactorRequest) error {
+	if err := service.Authorize(s.policy, act, "users:disable-two-factor", auth.Resource{Subject: req.UUID}); err != nil {
 		return err
 	}
 
-Do:
-	return s.Service.Delete(act, req)
+	return s.Service.DisableTwoFactor(act, req)
 }
@@ -0,0 +1,53 @@
+package userserve
+
+import (
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/metrics"
+)
+
+// InstrumentedService counts the failed logins through the service it
+// wraps, by how they were attempted and why they failed.
+type InstrumentedService struct {
+	user.Service
+	failures *metrics.Counter
+}
+
+func NewInstrumented(service user.Service, registry *metrics.Registry) user.Service {
+	return &InstrumentedService{
+		Service:  service,
+		failures: registry.Counter("almodon_login_failures_total", "Failed logins, by method and reason.", "method", "reason"),
+	}
+}
+
+func (s *InstrumentedService) Authenticate(req user.AuthRequest) (user.AuthResponse, error) {
+	res, err := s.Service.Authenticate(req)
+	s.count("password", err)
+	return res, err
+}
+
+func (s *InstrumentedService) AuthenticateSecondFactor(req user.SecondFactorRequest) (user.AuthResponse, error) {
+	res, err := s.Service.AuthenticateSecondFactor(req)
+	s.count("second-factor", err)
+	return res, err
+}
+
+func (s *InstrumentedService) CompleteSingleSignOn(req user.SingleSignOnCallbackRequest) (user.AuthResponse, error) {
+	res, err := s.Service.CompleteSingleSignOn(req)
+	s.count("single-sign-on", err)
+	return res, err
+}
+
+func (s *InstrumentedService) count(method string, err error) {
+	if err == nil {
+		return
+	}
+
+	// titles are a closed set, unlike messages, keeping the series few
+	reason := "internal"
+	if err, ok := errors.AsType[*errors.Error](err); ok {
+		reason = err.Title
+	}
+
+	s.failures.Inc(method, reason)
+}
@@ -1,40 +1,84 @@
 package userserve
 
 import (
+	"crypto/subtle"
+	"encoding/base64"
+	"strconv"
+
 	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/domain/outbox"
 	"github.com/alan-b-lima/almodon/internal/domain/promotion"
 	"github.com/alan-b-lima/almodon/internal/domain/session"
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	"github.com/alan-b-lima/almodon/internal/domain/twofactor"
 	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/support/listing"
 	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/oidc"
 	"github.com/alan-b-lima/almodon/pkg/opt"
 	"github.com/alan-b-lima/almodon/pkg/uuid"
+	"github.com/alan-b-lima/almodon/pkg/validate"
 )
 
 type Service struct {
 	users      user.Repository
 	sessions   session.Repository
+	lifetimes  session.Lifetimes
 	promotions promotion.Repository
+	twofactors twofactor.Repository
+	tokens     token.Repository
+	authn      user.Authenticator
+	sso        user.IdentityProvider
+	mailer     outbox.Enqueuer
 }
 
-func NewService(users user.Repository, sessions session.Repository, promotions promotion.Repository) user.Service {
+func NewService(users user.Repository, sessions session.Repository, lifetimes session.Lifetimes, promotions promotion.Repository, twofactors twofactor.Repository, tokens token.Repository, authn user.Authenticator, sso user.IdentityProvider, mailer outbox.Enqueuer) user.Service {
 	return &Service{
 		users:      users,
 		sessions:   sessions,
+		lifetimes:  lifetimes,
 		promotions: promotions,
+		twofactors: twofactors,
+		tokens:     tokens,
+		authn:      authn,
+		sso:        sso,
+		mailer:     mailer,
 	}
 }
 
 func (s *Service) List(act auth.Actor, req user.ListRequest) (user.ListResponse, error) {
-	res, err := user.List(s.users, req.Offset, req.Limit)
+	if err := listing.CheckOffset(req.Offset); err != nil {
+		return user.ListResponse{}, err
+	}
+
+	var filter user.Filter
+	if req.Role != "" {
+		role, ok := auth.FromString(req.Role)
+		if !ok {
+			return user.ListResponse{}, xerrors.ErrBadRole
+		}
+		filter.Role = opt.Some(role)
+	}
+
+	if req.Name != "" {
+		filter.Name = opt.Some(req.Name)
+	}
+
+	sort, err := user.Listing.ParseSort(req.Sort...)
+	if err != nil {
+		return user.ListResponse{}, err
+	}
+
+	res, err := user.List(s.users, filter, listing.Query{Sort: sort, After: listing.Cursor(req.Cursor), Limit: req.Limit})
 	if err != nil {
 		return user.ListResponse{}, err
 	}
 
 	lres := user.ListResponse{
-		Offset:       res.Offset,
-		Length:       res.Length,
-		Records:      make([]user.Response, res.Length),
+		Length:       len(res.Records),
+		Records:      make([]user.Response, len(res.Records)),
 		TotalRecords: res.TotalRecords,
+		Next:         string(res.Next),
 	}
 	for i := range res.Records {
 		transformP(&lres.Records[i], &res.Records[i])
@@ -64,7 +108,7 @@ func (s *Service) GetBySIAPE(act auth.Actor, req user.GetBySIAPERequest) (user.R
 func (s *Service) Create(act auth.Actor, req user.CreateRequest) (uuid.UUID, error) {
 	role, ok := auth.FromString(req.Role)
 	if !ok {
-		return uuid.UUID{}, xerrors.ErrBadRole
+		return uuid.UUID{}, validate.At("/role", xerrors.ErrBadRole)
 	}
 
 	rcs, err := user.Create(s.users, req.SIAPE, req.Name, req.Email, req.Password, role)
@@ -72,6 +116,14 @@ func (s *Service) Create(act auth.Actor, req user.CreateRequest) (uuid.UUID, err
 		return uuid.UUID{}, err
 	}
 
+	// the user exists regardless of the notification, so failing to
+	// enqueue it must not fail the creation
+	s.mailer.Enqueue(outbox.AccountCreated, []string{req.Email}, outbox.AccountCreatedData{
+		Name:  req.Name,
+		SIAPE: req.SIAPE,
+		Role:  roleNames[role],
+	})
+
 	return rcs, nil
 }
 
@@ -79,28 +131,117 @@ func (s *Service) Patch(act auth.Actor, req user.PatchRequest) error {
 	var string opt.Opt[string]
 	var role opt.Opt[auth.Role]
 
-	return user.Patch(s.users, req.UUID, req.Name, req.Email, string, role)
+	return user.Patch(s.users, req.UUID, req.IfMatch, req.Name, req.Email, string, role)
 }
 
 func (s *Service) UpdatePassword(act auth.Actor, req user.UpdatePasswordRequest) error {
 	var string opt.Opt[string]
 	var role opt.Opt[auth.Role]
 
-	return user.Patch(s.users, req.UUID, string, string, opt.Some(req.Password), role)
+	return user.Patch(s.users, req.UUID, req.IfMatch, string, string, opt.Some(req.Password), role)
 }
 
 func (s *Service) UpdateRole(act auth.Actor, req user.UpdateRoleRequest) error {
+	role, ok := auth.FromString(req.Role)
+	if !ok {
+		return validate.At("/role", xerrors.ErrBadRole)
+	}
+
 	var string opt.Opt[string]
 
-	return user.Patch(s.users, req.UUID, string, string, string, opt.Some(req.Role))
+	return user.Patch(s.users, req.UUID, req.IfMatch, string, string, string, opt.Some(role))
 }
 
 func (s *Service) Delete(act auth.Actor, req user.DeleteRequest) error {
-	return user.Delete(s.users, req.UUID)
+	if err := user.Delete(s.users, req.UUID, req.IfMatch); err != nil {
+		return err
+	}
+
+	return twofactor.Disable(s.twofactors, req.UUID)
+}
+
+func (s *Service) EnrollTwoFactor(act auth.Actor, req user.EnrollTwoFactorRequest) (user.EnrollTwoFactorResponse, error) {
+	ures, err := user.Get(s.users, req.UUID)
+	if err != nil {
+		return user.EnrollTwoFactorResponse{}, err
+	}
+
+	res, err := twofactor.Enroll(s.twofactors, ures.UUID, strconv.Itoa(ures.SIAPE))
+	if err != nil {
+		return user.EnrollTwoFactorResponse{}, err
+	}
+
+	return user.EnrollTwoFactorResponse{
+		Secret: res.Secret,
+		URI:    res.URI,
+		QR:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(res.QR),
+	}, nil
+}
+
+func (s *Service) ConfirmTwoFactor(act auth.Actor, req user.ConfirmTwoFactorRequest) (user.ConfirmTwoFactorResponse, error) {
+	codes, err := twofactor.Confirm(s.twofactors, req.UUID, req.Code)
+	if err != nil {
+		return user.ConfirmTwoFactorResponse{}, err
+	}
+
+	return user.ConfirmTwoFactorResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *Service) DisableTwoFactor(act auth.Actor, req user.DisableTwoFactorRequest) error {
+	// users disabling their own second factor must prove possession of
+	// it, others are expected to have been authorized to do so
+	if act.User() == req.UUID {
+		if err := twofactor.Verify(s.twofactors, req.UUID, req.Code); err != nil {
+			return err
+		}
+	}
+
+	return twofactor.Disable(s.twofactors, req.UUID)
 }
 
 func (s *Service) Authenticate(req user.AuthRequest) (user.AuthResponse, error) {
-	res, err := user.Authenticate(s.users, s.sessions, req.SIAPE, req.Password)
+	res, err := user.Authenticate(s.users, s.authn, s.sessions, s.lifetimes, s.twofactors, req.SIAPE, req.Password)
+	if err != nil {
+		return user.AuthResponse{}, err
+	}
+
+	return user.AuthResponse(res), nil
+}
+
+func (s *Service) AuthenticateSecondFactor(req user.SecondFactorRequest) (user.AuthResponse, error) {
+	res, err := user.AuthenticateSecondFactor(s.sessions, s.lifetimes, s.twofactors, req.Session, req.Code)
+	if err != nil {
+		return user.AuthResponse{}, err
+	}
+
+	return user.AuthResponse(res), nil
+}
+
+func (s *Service) BeginSingleSignOn() (user.SingleSignOnResponse, error) {
+	if s.sso == nil {
+		return user.SingleSignOnResponse{}, xerrors.ErrSingleSignOnDisabled
+	}
+
+	res := user.SingleSignOnResponse{
+		State:    oidc.NewRandom(),
+		Nonce:    oidc.NewRandom(),
+		Verifier: oidc.NewRandom(),
+	}
+	res.URL = s.sso.AuthCodeURL(res.State, res.Nonce, res.Verifier)
+
+	return res, nil
+}
+
+func (s *Service) CompleteSingleSignOn(req user.SingleSignOnCallbackRequest) (user.AuthResponse, error) {
+	if s.sso == nil {
+		return user.AuthResponse{}, xerrors.ErrSingleSignOnDisabled
+	}
+
+	if req.ExpectedState == "" || subtle.ConstantTimeCompare([]byte(req.State), []byte(req.ExpectedState)) != 1 {
+		return user.AuthResponse{}, xerrors.ErrSingleSignOnState
+	}
+
+	res, err := user.AuthenticateSingleSignOn(s.users, s.sso, s.sessions, s.lifetimes, s.twofactors, req.Code, req.Verifier, req.Nonce)
 	if err != nil {
 		return user.AuthResponse{}, err
 	}
@@ -112,13 +253,25 @@ func (s *Service) Actor(session uuid.UUID) (auth.Actor, error) {
 	return user.Actor(s.users, s.sessions, s.promotions, session)
 }
 
+func (s *Service) TokenActor(secret string) (auth.Actor, error) {
+	return user.TokenActor(s.users, s.tokens, s.promotions, secret)
+}
+
+var roleNames = map[auth.Role]string{
+	auth.Chief:    "Chefia",
+	auth.Promoted: "Técnico administrativo promovido",
+	auth.Admin:    "Técnico administrativo",
+	auth.User:     "Usuário",
+}
+
 func transform(e *user.Entity) user.Response {
 	return user.Response{
-		UUID:  e.UUID,
-		SIAPE: e.SIAPE,
-		Name:  e.Name,
-		Email: e.Email,
-		Role:  e.Role.String(),
+		UUID:    e.UUID,
+		SIAPE:   e.SIAPE,
+		Name:    e.Name,
+		Email:   e.Email,
+		Role:    e.Role.String(),
+		Version: e.Version,
 	}
 }
 
@@ -128,4 +281,5 @@ func transformP(r *user.Response, e *user.Entity) {
 	r.Name = e.Name
 	r.Email = e.Email
 	r.Role = e.Role.String()
+	r.Version = e.Version
 }
@@ -3,23 +3,27 @@ package user
 import (
 	"time"
 
-	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/support/entity"
 	"github.com/alan-b-lima/almodon/pkg/opt"
 	"github.com/alan-b-lima/almodon/pkg/uuid"
 )
 
 type (
 	ListRequest struct {
-		Offset int `query:"offset"`
-		Limit  int `query:"limit"`
+		Role   string       `query:"role"`
+		Name   string       `query:"name"`
+		Sort   []string     `query:"sort"`
+		Cursor string       `query:"cursor"`
+		Offset opt.Opt[int] `query:"offset,deprecated"`
+		Limit  int          `query:"limit" default:"10"`
 	}
 
 	GetRequest struct {
-		UUID uuid.UUID `json:"-"`
+		UUID uuid.UUID `json:"-" path:"uuid"`
 	}
 
 	GetBySIAPERequest struct {
-		SIAPE int `json:"-"`
+		SIAPE int `json:"-" path:"siape"`
 	}
 
 	CreateRequest struct {
@@ -31,50 +35,100 @@ type (
 	}
 
 	PatchRequest struct {
-		UUID  uuid.UUID       `json:"-"`
-		Name  opt.Opt[string] `json:"name"`
-		Email opt.Opt[string] `json:"email"`
+		UUID    uuid.UUID           `json:"-" path:"uuid"`
+		IfMatch entity.Precondition `json:"-" header:"If-Match,required"`
+		Name    opt.Opt[string]     `json:"name"`
+		Email   opt.Opt[string]     `json:"email"`
 	}
 
 	UpdatePasswordRequest struct {
-		UUID     uuid.UUID `json:"-"`
-		Password string    `json:"password"`
+		UUID     uuid.UUID           `json:"-" path:"uuid"`
+		IfMatch  entity.Precondition `json:"-" header:"If-Match,required"`
+		Password string              `json:"password"`
 	}
 
 	UpdateRoleRequest struct {
-		UUID uuid.UUID `json:"-"`
-		Role auth.Role `json:"role"`
+		UUID    uuid.UUID           `json:"-" path:"uuid"`
+		IfMatch entity.Precondition `json:"-" header:"If-Match,required"`
+		Role    string              `json:"role"`
 	}
 
 	DeleteRequest struct {
-		UUID uuid.UUID `json:"-"`
+		UUID    uuid.UUID           `json:"-" path:"uuid"`
+		IfMatch entity.Precondition `json:"-" header:"If-Match,required"`
 	}
 
 	AuthRequest struct {
 		SIAPE    int    `json:"siape"`
 		Password string `json:"password"`
 	}
+
+	SingleSignOnCallbackRequest struct {
+		Code          string `query:"code"`
+		State         string `query:"state"`
+		ExpectedState string `json:"-"`
+		Nonce         string `json:"-"`
+		Verifier      string `json:"-"`
+	}
+
+	SecondFactorRequest struct {
+		Session uuid.UUID `json:"-"`
+		Code    string    `json:"code"`
+	}
+
+	EnrollTwoFactorRequest struct {
+		UUID uuid.UUID `json:"-" path:"uuid"`
+	}
+
+	ConfirmTwoFactorRequest struct {
+		UUID uuid.UUID `json:"-" path:"uuid"`
+		Code string    `json:"code"`
+	}
+
+	DisableTwoFactorRequest struct {
+		UUID uuid.UUID `json:"-" path:"uuid"`
+		Code string    `json:"code"`
+	}
 )
 
 type (
 	ListResponse struct {
-		Offset       int        `json:"offset"`
 		Length       int        `json:"length"`
-		Records      []Response `json:"records"`
+		Records      []Response `json:"records" encode:"rows"`
 		TotalRecords int        `json:"total_records"`
+		Next         string     `json:"next,omitempty" header:"Next-Cursor"`
 	}
 
 	Response struct {
-		UUID  uuid.UUID `json:"uuid"`
-		SIAPE int       `json:"siape"`
-		Name  string    `json:"name"`
-		Email string    `json:"email"`
-		Role  string    `json:"role"`
+		UUID    uuid.UUID      `json:"uuid"`
+		SIAPE   int            `json:"siape"`
+		Name    string         `json:"name"`
+		Email   string         `json:"email"`
+		Role    string         `json:"role"`
+		Version entity.Version `json:"-" header:"ETag"`
 	}
 
 	AuthResponse struct {
-		UUID    uuid.UUID `json:"uuid"`
-		User    uuid.UUID `json:"user"`
-		Expires time.Time `json:"expires"`
+		UUID              uuid.UUID `json:"uuid"`
+		User              uuid.UUID `json:"user"`
+		Expires           time.Time `json:"expires"`
+		TwoFactorRequired bool      `json:"two_factor_required"`
+	}
+
+	SingleSignOnResponse struct {
+		URL      string `json:"url"`
+		State    string `json:"-"`
+		Nonce    string `json:"-"`
+		Verifier string `json:"-"`
+	}
+
+	EnrollTwoFactorResponse struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+		QR     string `json:"qr"`
+	}
+
+	ConfirmTwoFactorResponse struct {
+		RecoveryCodes []string `json:"recovery_codes"`
 	}
 )
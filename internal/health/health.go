@@ -0,0 +1,91 @@
+package health
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Check reports whether a dependency of the server is available.
+type Check func() error
+
+// Handler serves the liveness of the server, at /healthz, and its
+// readiness, at /readyz. The server is ready while every check passes
+// and it is not draining.
+//
+// Probes are not authenticated, so checks are reported only as "ok" or
+// "failed", and why they failed is logged instead.
+type Handler struct {
+	http.ServeMux
+	checks   map[string]Check
+	log      *slog.Logger
+	draining atomic.Bool
+}
+
+func New(checks map[string]Check, log *slog.Logger) *Handler {
+	h := Handler{checks: checks, log: log}
+
+	h.HandleFunc("GET /healthz", h.Live)
+	h.HandleFunc("GET /readyz", h.Ready)
+
+	return &h
+}
+
+// Drain marks the server as not ready for good, so that it is taken
+// out of rotation while the requests in flight are served. It then
+// waits for grace, in which the server should keep listening, so that
+// probes have the time to notice it.
+func (h *Handler) Drain(grace time.Duration) {
+	h.draining.Store(true)
+	time.Sleep(grace)
+}
+
+type Response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func (h *Handler) Live(w http.ResponseWriter, r *http.Request) {
+	write(w, http.StatusOK, &Response{Status: "ok"})
+}
+
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	res := Response{Status: "ready", Checks: make(map[string]string, len(h.checks))}
+	status := http.StatusOK
+
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := h.checks[name](); err != nil {
+			h.log.Warn("Readiness check failed", "check", name, "error", err)
+			res.Checks[name] = "failed"
+			res.Status, status = "not-ready", http.StatusServiceUnavailable
+		} else {
+			res.Checks[name] = "ok"
+		}
+	}
+
+	if h.draining.Load() {
+		res.Status, status = "draining", http.StatusServiceUnavailable
+	}
+
+	write(w, status, &res)
+}
+
+// write writes the response regardless of the Accept header, unlike
+// resource.EncodeJSON, as probes seldom send one, and are only
+// interested in the status anyway.
+func write(w http.ResponseWriter, status int, res *Response) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,111 @@
+package health_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/internal/health"
+)
+
+func TestReady(t *testing.T) {
+	var storage error
+	h := New(map[string]Check{
+		"storage": func() error { return storage },
+		"api":     func() error { return nil },
+	}, slog.New(slog.DiscardHandler))
+
+	type Tests struct {
+		storage  error
+		drain    bool
+		status   int
+		response string
+	}
+
+	tests := []Tests{
+		{nil, false, http.StatusOK, "ready"},
+		{errors.New("storage: data: no such file or directory"), false, http.StatusServiceUnavailable, "not-ready"},
+		{nil, true, http.StatusServiceUnavailable, "draining"},
+	}
+
+	for _, test := range tests {
+		storage = test.storage
+		if test.drain {
+			h.Drain(0)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var res Response
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+
+		if w.Code != test.status || res.Status != test.response {
+			t.Errorf("expected %d %s, got %d %s", test.status, test.response, w.Code, res.Status)
+		}
+
+		if test.storage != nil && res.Checks["storage"] != "failed" {
+			t.Errorf("expected the failed check to be reported without its error, got %q", res.Checks["storage"])
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected a draining server to be alive, got %d", w.Code)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	h := New(map[string]Check{"storage": func() error { return nil }}, slog.New(slog.DiscardHandler))
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ready := func() int {
+		res, err := http.Get(srv.URL + "/readyz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		return res.StatusCode
+	}
+
+	if status := ready(); status != http.StatusOK {
+		t.Fatalf("expected %d before draining, got %d", http.StatusOK, status)
+	}
+
+	const grace = 200 * time.Millisecond
+
+	drained := make(chan struct{})
+	start := time.Now()
+	go func() {
+		h.Drain(grace)
+		close(drained)
+	}()
+
+	time.Sleep(grace / 4)
+
+	select {
+	case <-drained:
+		t.Fatal("expected Drain to wait for its grace period")
+	default:
+	}
+
+	if status := ready(); status != http.StatusServiceUnavailable {
+		t.Errorf("expected %d while draining, got %d", http.StatusServiceUnavailable, status)
+	}
+
+	<-drained
+	if elapsed := time.Since(start); elapsed < grace {
+		t.Errorf("expected Drain to wait %s, returned after %s", grace, elapsed)
+	}
+}
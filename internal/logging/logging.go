@@ -0,0 +1,113 @@
+// Package logging builds the loggers of the server, on top of
+// log/slog, and carries the identity of requests through their
+// contexts, so that every record logged while serving a request is
+// tagged with it.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// New creates a logger writing to w in the given format, either
+// "json" or "text", whose records are logged from the given level on.
+func New(w io.Writer, format string, level slog.Leveler) *slog.Logger {
+	var handler slog.Handler
+
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	default:
+		handler = NewTextHandler(w, level)
+	}
+
+	return slog.New(&contextHandler{handler})
+}
+
+type key struct{}
+
+// request is the identity of a request, the actor and the route are
+// set once they are resolved, while the request is served.
+type request struct {
+	id    uuid.UUID
+	actor uuid.UUID
+	route string
+}
+
+// WithRequest returns a context carrying the ID of a request.
+func WithRequest(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, key{}, &request{id: id})
+}
+
+// RequestID returns the ID of the request the context carries.
+func RequestID(ctx context.Context) (uuid.UUID, bool) {
+	req, ok := ctx.Value(key{}).(*request)
+	if !ok {
+		return uuid.UUID{}, false
+	}
+
+	return req.id, true
+}
+
+// SetActor sets the user acting in the request the context carries,
+// if any.
+func SetActor(ctx context.Context, actor uuid.UUID) {
+	if req, ok := ctx.Value(key{}).(*request); ok {
+		req.actor = actor
+	}
+}
+
+// Actor returns the user acting in the request the context carries,
+// if they were set.
+func Actor(ctx context.Context) (uuid.UUID, bool) {
+	req, ok := ctx.Value(key{}).(*request)
+	if !ok || req.actor.IsNil() {
+		return uuid.UUID{}, false
+	}
+
+	return req.actor, true
+}
+
+// SetRoute sets the pattern of the route of the request the context
+// carries, if any.
+func SetRoute(ctx context.Context, route string) {
+	if req, ok := ctx.Value(key{}).(*request); ok {
+		req.route = route
+	}
+}
+
+// Route returns the pattern of the route of the request the context
+// carries, if it was set.
+func Route(ctx context.Context) (string, bool) {
+	req, ok := ctx.Value(key{}).(*request)
+	if !ok || req.route == "" {
+		return "", false
+	}
+
+	return req.route, true
+}
+
+// contextHandler tags records with the ID of the request the context
+// they are logged with carries.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id, ok := RequestID(ctx); ok {
+		r.AddAttrs(slog.String("request_id", id.String()))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,62 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/internal/logging"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+func TestText(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, "text", slog.LevelInfo)
+
+	log.Debug("hidden")
+	log.With("component", "api").WithGroup("req").Info("request", "status", 404, "path", "/api/v1/users/", "agent", "curl 8.0")
+
+	line := buf.String()
+	if strings.Contains(line, "hidden") {
+		t.Errorf("records below the level should not be logged")
+	}
+
+	for _, exp := range []string{"INFO  request", "component=api", "req.status= 404 ", "req.path=/api/v1/users/", `req.agent="curl 8.0"`} {
+		if !strings.Contains(line, exp) {
+			t.Errorf("expected %q in %q", exp, line)
+		}
+	}
+}
+
+func TestRequest(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, "json", slog.LevelInfo)
+
+	id, actor := uuid.NewUUIDv7(), uuid.NewUUIDv7()
+	ctx := WithRequest(context.Background(), id)
+
+	if _, ok := Actor(ctx); ok {
+		t.Errorf("actor should not be set before SetActor")
+	}
+
+	SetActor(ctx, actor)
+	if got, ok := Actor(ctx); !ok || got != actor {
+		t.Errorf("expected actor %v, got %v", actor, got)
+	}
+
+	log.InfoContext(ctx, "request")
+
+	var record struct {
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record.RequestID != id.String() {
+		t.Errorf("expected request_id %v, got %q", id, record.RequestID)
+	}
+}
@@ -0,0 +1,254 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/alan-b-lima/ansi-escape-sequences"
+)
+
+// TextHandler writes records as lines of key=value pairs, meant to be
+// read by people. On terminals, levels and HTTP statuses, given as the
+// "status" attribute, are colored, and URLs, given as "url", are
+// links.
+type TextHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Leveler
+	style *Style
+
+	attrs  []byte
+	prefix string
+}
+
+func NewTextHandler(w io.Writer, level slog.Leveler) *TextHandler {
+	style := Styles(w)
+
+	return &TextHandler{
+		mu:    new(sync.Mutex),
+		w:     w,
+		level: level,
+		style: &style,
+	}
+}
+
+func (h *TextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *TextHandler) Handle(ctx context.Context, r slog.Record) error {
+	buf := make([]byte, 0, 256)
+
+	if !r.Time.IsZero() {
+		buf = r.Time.AppendFormat(buf, "2006/01/02 15:04:05 ")
+	}
+
+	buf = paint(buf, h.style.LevelPen(r.Level), fmt.Sprintf("%-5s", r.Level))
+	buf = append(buf, ' ')
+	buf = append(buf, r.Message...)
+	buf = append(buf, h.attrs...)
+
+	r.Attrs(func(a slog.Attr) bool {
+		buf = h.appendAttr(buf, h.prefix, a)
+		return true
+	})
+	buf = append(buf, '\n')
+
+	defer h.mu.Unlock()
+	h.mu.Lock()
+
+	_, err := h.w.Write(buf)
+	return err
+}
+
+func (h *TextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = c.attrs[:len(c.attrs):len(c.attrs)]
+
+	for _, a := range attrs {
+		c.attrs = h.appendAttr(c.attrs, h.prefix, a)
+	}
+
+	return &c
+}
+
+func (h *TextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	c := *h
+	c.prefix += name + "."
+
+	return &c
+}
+
+func (h *TextHandler) appendAttr(buf []byte, prefix string, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return buf
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+
+		for _, a := range a.Value.Group() {
+			buf = h.appendAttr(buf, prefix, a)
+		}
+
+		return buf
+	}
+
+	buf = append(buf, ' ')
+	buf = append(buf, prefix...)
+	buf = append(buf, a.Key...)
+	buf = append(buf, '=')
+
+	switch {
+	case a.Key == "status" && a.Value.Kind() == slog.KindInt64:
+		status := int(a.Value.Int64())
+		return paint(buf, h.style.StatusCodePen(status), fmt.Sprintf(" %03d ", status))
+
+	case a.Key == "url" && a.Value.Kind() == slog.KindString:
+		return append(buf, h.style.HyperLink(a.Value.String())...)
+
+	case a.Value.Kind() == slog.KindTime:
+		return a.Value.Time().AppendFormat(buf, time.RFC3339Nano)
+	}
+
+	return appendString(buf, a.Value.String())
+}
+
+// paint appends the string written with the pen, which, unlike
+// [ansi.Pen.Sprint], leaves it plain if the pen is disabled.
+func paint(buf []byte, pen ansi.Pen, s string) []byte {
+	b := bytes.NewBuffer(buf)
+	pen.Fprint(b, s)
+
+	return b.Bytes()
+}
+
+// appendString appends the string, quoted if it would not be read
+// back as a single value otherwise.
+func appendString(buf []byte, s string) []byte {
+	quote := s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r)
+	}) >= 0
+
+	if quote {
+		return strconv.AppendQuote(buf, s)
+	}
+
+	return append(buf, s...)
+}
+
+type Style struct {
+	HyperLink func(string) string
+
+	Pens      map[int]ansi.Pen
+	LevelPens map[slog.Level]ansi.Pen
+	NoStyle   ansi.Pen
+
+	Enabled bool
+}
+
+// Styles returns the style of the writer, which is plain unless it
+// is a terminal supporting escape sequences.
+func Styles(w io.Writer) (s Style) {
+	s.Enabled = enableAnsi(w)
+	s.NoStyle.SetStyle(false)
+
+	if s.Enabled {
+		var Success ansi.Pen
+		var Redirect ansi.Pen
+		var ClientError ansi.Pen
+		var ServerError ansi.Pen
+
+		Success.BGColor(ansi.RGBFromHex(0x0ed145))
+		Success.FGColor(ansi.RGBFromHex(0xffffff))
+
+		Redirect.BGColor(ansi.RGBFromHex(0x4b53cc))
+		Redirect.FGColor(ansi.RGBFromHex(0xffffff))
+
+		ClientError.BGColor(ansi.RGBFromHex(0xea1d1d))
+		ClientError.FGColor(ansi.RGBFromHex(0xffffff))
+
+		ServerError.BGColor(ansi.RGBFromHex(0x88001b))
+		ServerError.FGColor(ansi.RGBFromHex(0xffffff))
+
+		s.Pens = map[int]ansi.Pen{
+			2: Success,
+			3: Redirect,
+			4: ClientError,
+			5: ServerError,
+		}
+
+		var Debug ansi.Pen
+		var Warn ansi.Pen
+		var Error ansi.Pen
+
+		Debug.FGColor(ansi.RGBFromHex(0x8a8a8a))
+		Warn.FGColor(ansi.RGBFromHex(0xe5c07b))
+		Error.FGColor(ansi.RGBFromHex(0xea1d1d))
+
+		s.LevelPens = map[slog.Level]ansi.Pen{
+			slog.LevelDebug: Debug,
+			slog.LevelWarn:  Warn,
+			slog.LevelError: Error,
+		}
+		s.HyperLink = hyperlink
+
+		return s
+	}
+
+	s.HyperLink = func(s string) string { return s }
+	s.Pens = map[int]ansi.Pen{}
+	s.LevelPens = map[slog.Level]ansi.Pen{}
+
+	return s
+}
+
+func (s *Style) StatusCodePen(status int) ansi.Pen {
+	pen, in := s.Pens[status/100]
+	if !in {
+		return s.NoStyle
+	}
+
+	return pen
+}
+
+func (s *Style) LevelPen(level slog.Level) ansi.Pen {
+	pen, in := s.LevelPens[level]
+	if !in {
+		return s.NoStyle
+	}
+
+	return pen
+}
+
+func hyperlink(link string) string {
+	var pen ansi.Pen
+	pen.FGColor(ansi.RGBFromHex(0x4e8597))
+
+	return pen.Sprint(ansi.HyperLinkP(link))
+}
+
+func enableAnsi(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+
+	err := ansi.EnableVirtualTerminal(f.Fd())
+	return err == nil
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/alan-b-lima/almodon/internal/support/resource"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+)
+
+const (
+	CSRFCookie = "csrf"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// CSRF protects cookie-authenticated state-changing requests against
+// cross-site request forgery.
+//
+// Every non-safe request is first checked by [http.CrossOriginProtection],
+// that rejects cross-origin browser requests through the Sec-Fetch-Site
+// and Origin headers. Then, if the request carries a session cookie,
+// it must also carry, in the X-CSRF-Token header, the same token as
+// the one in the csrf cookie (double-submit), issued by [CSRF.Token].
+type CSRF struct {
+	origin  *http.CrossOriginProtection
+	cookies resource.CookiePolicy
+}
+
+func NewCSRF(cookies resource.CookiePolicy) *CSRF {
+	return &CSRF{
+		origin:  http.NewCrossOriginProtection(),
+		cookies: cookies,
+	}
+}
+
+// AddTrustedOrigin allows cross-origin requests from the given origin,
+// see [http.CrossOriginProtection.AddTrustedOrigin].
+func (c *CSRF) AddTrustedOrigin(origin string) error {
+	return c.origin.AddTrustedOrigin(origin)
+}
+
+func (c *CSRF) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := c.Check(r); err != nil {
+			resource.WriteJsonError(w, err)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Check returns an error if the request should be rejected.
+func (c *CSRF) Check(r *http.Request) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+
+	if err := c.origin.Check(r); err != nil {
+		return xerrors.ErrCrossOriginRequest
+	}
+
+	if _, err := r.Cookie(resource.SessionCookie); err != nil {
+		return nil
+	}
+
+	cookie, err := r.Cookie(CSRFCookie)
+	if err != nil || cookie.Value == "" {
+		return xerrors.ErrCSRFTokenMissing
+	}
+
+	header := r.Header.Get(CSRFHeader)
+	if header == "" {
+		return xerrors.ErrCSRFTokenMissing
+	}
+
+	if subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) != 1 {
+		return xerrors.ErrCSRFTokenMismatch
+	}
+
+	return nil
+}
+
+type tokenResponse struct {
+	Token string `json:"token"`
+}
+
+// Token issues the token, in a cookie and in the response body, to be
+// sent back in the X-CSRF-Token header. A token already issued to the
+// client is reused, so that concurrent pages do not invalidate each
+// other.
+func (c *CSRF) Token(w http.ResponseWriter, r *http.Request) {
+	var token string
+	if cookie, err := r.Cookie(CSRFCookie); err == nil && len(cookie.Value) == base64.RawURLEncoding.EncodedLen(32) {
+		token = cookie.Value
+	} else {
+		var buf [32]byte
+		rand.Read(buf[:])
+		token = base64.RawURLEncoding.EncodeToString(buf[:])
+	}
+
+	cookie := &http.Cookie{
+		Name:     CSRFCookie,
+		Value:    token,
+		Path:     "/",
+		SameSite: http.SameSiteStrictMode,
+	}
+	http.SetCookie(w, c.cookies.Apply(cookie))
+
+	res := tokenResponse{Token: token}
+	if err := resource.EncodeJSON(&res, http.StatusOK, w, r); err != nil {
+		resource.WriteJsonError(w, err)
+		return
+	}
+}
@@ -0,0 +1,143 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/internal/middleware"
+	"github.com/alan-b-lima/almodon/internal/support/resource"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+)
+
+func TestCSRFToken(t *testing.T) {
+	csrf := NewCSRF(resource.CookiePolicy{})
+
+	token := func(cookie string) (string, string) {
+		r := httptest.NewRequest(http.MethodGet, "/csrf/", nil)
+		r.Header.Set("Accept", "application/json")
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: CSRFCookie, Value: cookie})
+		}
+
+		w := httptest.NewRecorder()
+		csrf.Token(w, r)
+
+		var res struct {
+			Token string `json:"token"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("expected a token, got %d: %s", w.Code, w.Body)
+		}
+
+		for _, c := range w.Result().Cookies() {
+			if c.Name == CSRFCookie {
+				return res.Token, c.Value
+			}
+		}
+
+		t.Fatalf("expected the %s cookie, got %v", CSRFCookie, w.Result().Cookies())
+		return "", ""
+	}
+
+	issued, cookie := token("")
+	if issued == "" || issued != cookie {
+		t.Fatalf("expected the same token in the body and the cookie, got %q and %q", issued, cookie)
+	}
+
+	type Tests struct {
+		cookie string
+		reused bool
+	}
+
+	tests := []Tests{
+		{issued, true},
+		{"short", false},
+	}
+
+	for _, test := range tests {
+		got, _ := token(test.cookie)
+		if (got == test.cookie) != test.reused {
+			t.Errorf("%q: expected the token to be reused to be %t, got %q", test.cookie, test.reused, got)
+		}
+	}
+}
+
+func TestCSRFCheck(t *testing.T) {
+	const token = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQ"
+
+	calls := 0
+	handler := NewCSRF(resource.CookiePolicy{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	type Tests struct {
+		method  string
+		session bool
+		cookie  string
+		header  string
+		bearer  bool
+		site    string
+
+		status int
+		title  string
+	}
+
+	tests := []Tests{
+		{http.MethodGet, true, "", "", false, "", http.StatusNoContent, ""},
+		{http.MethodHead, true, "", "", false, "", http.StatusNoContent, ""},
+		{http.MethodOptions, true, "", "", false, "cross-site", http.StatusNoContent, ""},
+		{http.MethodPost, true, token, token, false, "same-origin", http.StatusNoContent, ""},
+		{http.MethodPatch, true, token, token, false, "", http.StatusNoContent, ""},
+		{http.MethodPost, true, "", "", false, "", http.StatusForbidden, "csrf-token-missing"},
+		{http.MethodPost, true, token, "", false, "", http.StatusForbidden, "csrf-token-missing"},
+		{http.MethodPatch, true, "", token, false, "", http.StatusForbidden, "csrf-token-missing"},
+		{http.MethodPatch, true, token, token + "x", false, "", http.StatusForbidden, "csrf-token-mismatch"},
+		{http.MethodDelete, true, token, "other", false, "", http.StatusForbidden, "csrf-token-mismatch"},
+		{http.MethodPost, true, token, token, false, "cross-site", http.StatusForbidden, "cross-origin-request"},
+		{http.MethodPost, false, "", "", true, "", http.StatusNoContent, ""},
+		{http.MethodPatch, false, "", "", true, "", http.StatusNoContent, ""},
+	}
+
+	for i, test := range tests {
+		r := httptest.NewRequest(test.method, "/things/", nil)
+		if test.session {
+			r.AddCookie(&http.Cookie{Name: resource.SessionCookie, Value: "session"})
+		}
+		if test.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: CSRFCookie, Value: test.cookie})
+		}
+		if test.header != "" {
+			r.Header.Set(CSRFHeader, test.header)
+		}
+		if test.bearer {
+			r.Header.Set("Authorization", "Bearer almpat_token")
+		}
+		if test.site != "" {
+			r.Header.Set("Sec-Fetch-Site", test.site)
+		}
+
+		before := calls
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("%d: %s: expected %d, got %d: %s", i, test.method, test.status, w.Code, w.Body)
+			continue
+		}
+
+		if passed := calls > before; passed != (test.title == "") {
+			t.Errorf("%d: %s: expected the request to reach the handler to be %t", i, test.method, test.title == "")
+		}
+
+		if test.title != "" {
+			var err errors.Error
+			if json.Unmarshal(w.Body.Bytes(), &err) != nil || err.Title != test.title {
+				t.Errorf("%d: %s: expected %q, got %s", i, test.method, test.title, w.Body)
+			}
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/support/resource"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/heap"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+const (
+	IdempotencyHeader = "Idempotency-Key"
+	ReplayedHeader    = "Idempotent-Replayed"
+)
+
+const (
+	_MaxIdempotencyKey = 255
+
+	// _MaxIdempotentBody bounds the bodies of requests carrying a key,
+	// which are read whole to be compared with those of their retries.
+	_MaxIdempotentBody = 1 << 20
+
+	// _MaxIdempotentEntries and _MaxIdempotentBytes bound the keys
+	// kept for each actor and the size of their responses, so that no
+	// actor holds an unbounded share of memory for the window.
+	_MaxIdempotentEntries = 1000
+	_MaxIdempotentBytes   = 8 << 20
+)
+
+// Idempotency makes POST requests carrying an Idempotency-Key header
+// safe to retry. The first response to a key, per actor, is kept for
+// the window and replayed to retries of the same request, marked by
+// the Idempotent-Replayed header, rather than served again.
+//
+// Reusing a key for a request of another method, path or body is
+// rejected as a conflict, as is retrying a request still in flight.
+// Server errors are not kept, so that the request may be retried, nor
+// are requests of unlogged actors served idempotently, as they have no
+// actor to scope the key to.
+//
+// Actors may hold a bounded number of keys, and responses, at a time.
+// Keys past the bound are refused, while responses past it are served
+// but not kept, so their retries are served again.
+type Idempotency struct {
+	gk     gatekeeper
+	window time.Duration
+
+	entries map[idempotencyKey]*idempotent
+	usages  map[uuid.UUID]*usage
+	expires heap.Heap[expiring]
+	mu      sync.Mutex
+}
+
+type gatekeeper interface {
+	Actor(session uuid.UUID) (auth.Actor, error)
+	TokenActor(token string) (auth.Actor, error)
+}
+
+type idempotencyKey struct {
+	user uuid.UUID
+	key  string
+}
+
+type idempotent struct {
+	digest  [sha256.Size]byte
+	expires time.Time
+	done    bool
+
+	status int
+	header http.Header
+	body   []byte
+}
+
+// usage is what is kept for an actor.
+type usage struct {
+	entries int
+	bytes   int
+}
+
+type expiring struct {
+	key     idempotencyKey
+	expires time.Time
+}
+
+func (e0 expiring) Less(e1 expiring) bool { return e0.expires.Before(e1.expires) }
+
+// NewIdempotency creates the middleware, resolving actors through the
+// gatekeeper and keeping responses for the window.
+func NewIdempotency(gk gatekeeper, window time.Duration) *Idempotency {
+	return &Idempotency{
+		gk:      gk,
+		window:  window,
+		entries: make(map[idempotencyKey]*idempotent),
+		usages:  make(map[uuid.UUID]*usage),
+	}
+}
+
+func (i *Idempotency) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > _MaxIdempotencyKey {
+			resource.WriteJsonError(w, xerrors.ErrBadIdempotencyKey)
+			return
+		}
+
+		act, err := resource.Session(i.gk, r)
+		if err != nil || !act.Role().IsValid() {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, _MaxIdempotentBody))
+		if _, ok := errors.AsType[*http.MaxBytesError](err); ok {
+			resource.WriteJsonError(w, xerrors.ErrIdempotentBodyTooLarge.New(_MaxIdempotentBody))
+			return
+		}
+		if err != nil {
+			resource.WriteJsonError(w, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		k := idempotencyKey{user: act.User(), key: key}
+		digest := digest(r, body)
+
+		entry, claimed, err := i.begin(k, digest)
+		if err != nil {
+			resource.WriteJsonError(w, err)
+			return
+		}
+
+		if !claimed {
+			replay(w, entry)
+			return
+		}
+
+		// the key is released if the handler panics, so that it is not
+		// held in flight for good
+		rec := &recorder{w: w, status: http.StatusInternalServerError}
+		defer i.end(k, entry, rec)
+
+		handler.ServeHTTP(rec, r)
+		if rec.header == nil {
+			rec.WriteHeader(http.StatusOK)
+		}
+	})
+}
+
+// begin claims the key for the request, or else returns the response
+// kept for it.
+func (i *Idempotency) begin(k idempotencyKey, digest [sha256.Size]byte) (*idempotent, bool, error) {
+	defer i.mu.Unlock()
+	i.mu.Lock()
+
+	i.expire(time.Now())
+
+	entry, ok := i.entries[k]
+	if !ok {
+		u := i.usages[k.user]
+		if u == nil {
+			u = &usage{}
+			i.usages[k.user] = u
+		}
+
+		if u.entries >= _MaxIdempotentEntries {
+			return nil, false, xerrors.ErrIdempotencyLimit
+		}
+		u.entries++
+
+		// the window starts once the response is kept, so that entries
+		// in flight are not expired
+		entry = &idempotent{digest: digest}
+		i.entries[k] = entry
+		return entry, true, nil
+	}
+
+	if entry.digest != digest {
+		return nil, false, xerrors.ErrIdempotencyKeyReused
+	}
+
+	if !entry.done {
+		return nil, false, xerrors.ErrIdempotencyInFlight
+	}
+
+	return entry, false, nil
+}
+
+// end keeps the response to the request that claimed the entry, or
+// releases the key, if it failed on the server or the response is too
+// large to be kept.
+func (i *Idempotency) end(k idempotencyKey, entry *idempotent, rec *recorder) {
+	defer i.mu.Unlock()
+	i.mu.Lock()
+
+	if i.entries[k] != entry {
+		return
+	}
+
+	u := i.usages[k.user]
+	if rec.status >= http.StatusInternalServerError || rec.overflow || u.bytes+rec.body.Len() > _MaxIdempotentBytes {
+		i.drop(k, entry)
+		return
+	}
+
+	entry.done = true
+	entry.expires = time.Now().Add(i.window)
+	entry.status = rec.status
+	entry.header = rec.header
+	entry.body = rec.body.Bytes()
+
+	u.bytes += len(entry.body)
+	i.expires.Push(expiring{key: k, expires: entry.expires})
+}
+
+// expire drops the entries whose window is over by now.
+func (i *Idempotency) expire(now time.Time) {
+	for i.expires.Len() > 0 && !i.expires.Peek().expires.After(now) {
+		e := i.expires.Pop()
+
+		// keys released early may have been claimed again since
+		if entry, ok := i.entries[e.key]; ok && entry.done && entry.expires.Equal(e.expires) {
+			i.drop(e.key, entry)
+		}
+	}
+}
+
+// drop forgets the entry, and what it took of its actor's usage.
+func (i *Idempotency) drop(k idempotencyKey, entry *idempotent) {
+	delete(i.entries, k)
+
+	u := i.usages[k.user]
+	u.entries--
+	u.bytes -= len(entry.body)
+
+	if u.entries == 0 {
+		delete(i.usages, k.user)
+	}
+}
+
+// digest identifies the request a key was first used for.
+func digest(r *http.Request, body []byte) [sha256.Size]byte {
+	h := sha256.New()
+	io.WriteString(h, r.Method)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.RequestURI())
+	h.Write([]byte{0})
+	h.Write(body)
+
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// replay writes the kept response, headers already set, as by outer
+// middlewares, are left as they are.
+func replay(w http.ResponseWriter, entry *idempotent) {
+	for name, vals := range entry.header {
+		if _, ok := w.Header()[name]; !ok {
+			w.Header()[name] = vals
+		}
+	}
+
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(entry.status)
+	w.Write(entry.body)
+}
+
+// recorder writes the response through, keeping a copy of it, unless
+// it overflows what an actor may keep.
+type recorder struct {
+	w        http.ResponseWriter
+	status   int
+	header   http.Header
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (rec *recorder) Header() http.Header { return rec.w.Header() }
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.header == nil {
+		rec.status = status
+		rec.header = rec.w.Header().Clone()
+	}
+
+	rec.w.WriteHeader(status)
+}
+
+func (rec *recorder) Write(buf []byte) (int, error) {
+	if rec.header == nil {
+		rec.WriteHeader(http.StatusOK)
+	}
+
+	if !rec.overflow && rec.body.Len()+len(buf) > _MaxIdempotentBytes {
+		rec.overflow = true
+		rec.body = bytes.Buffer{}
+	}
+
+	if !rec.overflow {
+		rec.body.Write(buf)
+	}
+	return rec.w.Write(buf)
+}
+
+func (rec *recorder) Unwrap() http.ResponseWriter { return rec.w }
@@ -0,0 +1,243 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	. "github.com/alan-b-lima/almodon/internal/middleware"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+var _Users = map[string]uuid.UUID{
+	"alice": uuid.NewUUIDv7(),
+	"bob":   uuid.NewUUIDv7(),
+}
+
+type gatekeeper struct{}
+
+func (gatekeeper) Actor(uuid.UUID) (auth.Actor, error) { return auth.NewUnlogged(), nil }
+
+func (gatekeeper) TokenActor(token string) (auth.Actor, error) {
+	return auth.NewLogged(_Users[token], auth.User), nil
+}
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("/things/%d", calls))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, calls)
+	})
+
+	idem := NewIdempotency(gatekeeper{}, time.Hour).Handler(handler)
+
+	type Tests struct {
+		actor, key, path, body string
+
+		status   int
+		calls    int
+		replayed bool
+		title    string
+	}
+
+	tests := []Tests{
+		{"alice", "k1", "/things", `{"n":1}`, http.StatusCreated, 1, false, ""},
+		{"alice", "k1", "/things", `{"n":1}`, http.StatusCreated, 1, true, ""},
+		{"alice", "k1", "/things", `{"n":2}`, http.StatusConflict, 1, false, "idempotency-key-reused"},
+		{"alice", "k1", "/others", `{"n":1}`, http.StatusConflict, 1, false, "idempotency-key-reused"},
+		{"bob", "k1", "/things", `{"n":1}`, http.StatusCreated, 2, false, ""},
+		{"alice", "", "/things", `{"n":1}`, http.StatusCreated, 3, false, ""},
+		{"", "k1", "/things", `{"n":1}`, http.StatusCreated, 4, false, ""},
+		{"alice", "k2", "/fail", `{}`, http.StatusInternalServerError, 5, false, ""},
+		{"alice", "k2", "/fail", `{}`, http.StatusInternalServerError, 6, false, ""},
+		{"alice", strings.Repeat("k", 256), "/things", `{}`, http.StatusBadRequest, 6, false, "bad-idempotency-key"},
+		{"alice", "k3", "/things", strings.Repeat("x", 1<<20+1), http.StatusBadRequest, 6, false, "idempotent-body-too-large"},
+	}
+
+	for i, test := range tests {
+		r := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+		if test.actor != "" {
+			r.Header.Set("Authorization", "Bearer "+test.actor)
+		}
+		if test.key != "" {
+			r.Header.Set(IdempotencyHeader, test.key)
+		}
+
+		w := httptest.NewRecorder()
+		idem.ServeHTTP(w, r)
+
+		if w.Code != test.status || calls != test.calls {
+			t.Errorf("%d: expected %d after %d calls, got %d after %d: %s", i, test.status, test.calls, w.Code, calls, w.Body)
+			continue
+		}
+
+		if replayed := w.Header().Get(ReplayedHeader) == "true"; replayed != test.replayed {
+			t.Errorf("%d: expected replayed to be %t", i, test.replayed)
+		}
+
+		if test.replayed && (w.Body.String() != "1" || w.Header().Get("Location") != "/things/1") {
+			t.Errorf("%d: expected the first response, got %v: %s", i, w.Header(), w.Body)
+		}
+
+		if test.title != "" {
+			var err errors.Error
+			if json.Unmarshal(w.Body.Bytes(), &err) != nil || err.Title != test.title {
+				t.Errorf("%d: expected %q, got %s", i, test.title, w.Body)
+			}
+		}
+	}
+}
+
+func TestIdempotencyWindow(t *testing.T) {
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	idem := NewIdempotency(gatekeeper{}, time.Millisecond).Handler(handler)
+
+	for range 2 {
+		r := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{}`))
+		r.Header.Set("Authorization", "Bearer alice")
+		r.Header.Set(IdempotencyHeader, "k")
+
+		idem.ServeHTTP(httptest.NewRecorder(), r)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected the key to be forgotten after the window, got %d calls", calls)
+	}
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			close(entered)
+			<-release
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	idem := NewIdempotency(gatekeeper{}, time.Millisecond).Handler(handler)
+
+	request := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{}`))
+		r.Header.Set("Authorization", "Bearer alice")
+		r.Header.Set(IdempotencyHeader, "k")
+
+		w := httptest.NewRecorder()
+		idem.ServeHTTP(w, r)
+		return w
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- request() }()
+	<-entered
+
+	// the window passes while the first request is in flight, which
+	// must not expire its key
+	time.Sleep(2 * time.Millisecond)
+
+	if w := request(); w.Code != http.StatusConflict {
+		t.Errorf("expected the retry to conflict with the request in flight, got %d: %s", w.Code, w.Body)
+	}
+
+	close(release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Errorf("expected the first request to be served, got %d: %s", w.Code, w.Body)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected the handler to be called once, got %d calls", calls)
+	}
+}
+
+func TestIdempotencyLimit(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	idem := NewIdempotency(gatekeeper{}, time.Hour).Handler(handler)
+
+	request := func(actor, key string) int {
+		r := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{}`))
+		r.Header.Set("Authorization", "Bearer "+actor)
+		r.Header.Set(IdempotencyHeader, key)
+
+		w := httptest.NewRecorder()
+		idem.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	for i := range 1000 {
+		if status := request("alice", fmt.Sprint(i)); status != http.StatusCreated {
+			t.Fatalf("%d: expected the key to be kept, got %d", i, status)
+		}
+	}
+
+	type Tests struct {
+		actor, key string
+		status     int
+	}
+
+	tests := []Tests{
+		{"alice", "another", http.StatusConflict},
+		{"alice", "0", http.StatusCreated},
+		{"bob", "another", http.StatusCreated},
+	}
+
+	for _, test := range tests {
+		if status := request(test.actor, test.key); status != test.status {
+			t.Errorf("%s, %s: expected %d, got %d", test.actor, test.key, test.status, status)
+		}
+	}
+}
+
+func TestIdempotencyLargeResponse(t *testing.T) {
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+		w.Write(make([]byte, 8<<20+1))
+	})
+
+	idem := NewIdempotency(gatekeeper{}, time.Hour).Handler(handler)
+
+	for i := range 2 {
+		r := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{}`))
+		r.Header.Set("Authorization", "Bearer alice")
+		r.Header.Set(IdempotencyHeader, "k")
+
+		w := httptest.NewRecorder()
+		idem.ServeHTTP(w, r)
+
+		if w.Code != http.StatusCreated || w.Body.Len() != 8<<20+1 {
+			t.Errorf("%d: expected the whole response, got %d with %d bytes", i, w.Code, w.Body.Len())
+		}
+	}
+
+	// responses too large to be kept are served again
+	if calls != 2 {
+		t.Errorf("expected the response not to be kept, got %d calls", calls)
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/logging"
+	"github.com/alan-b-lima/almodon/pkg/metrics"
+)
+
+// Metrics counts requests by method, route and status, and observes
+// their latency by method and route. Routes are the patterns reported
+// by [resource.Routed], requests not routed by one are counted under
+// the "other" route. It is meant to be wrapped by [RequestID].
+func Metrics(registry *metrics.Registry, handler http.Handler) http.Handler {
+	requests := registry.Counter("almodon_http_requests_total", "Requests served, by method, route and status.", "method", "route", "status")
+	latency := registry.Histogram("almodon_http_request_duration_seconds", "Latency of requests, by method and route.", metrics.DefaultBuckets, "method", "route")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := NewW(w)
+
+		handler.ServeHTTP(rw, r)
+
+		route, ok := logging.Route(r.Context())
+		if !ok {
+			route = "other"
+		}
+
+		requests.Inc(r.Method, route, strconv.Itoa(rw.StatusCode()))
+		latency.Observe(time.Since(start).Seconds(), r.Method, route)
+	})
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/logging"
+	"github.com/alan-b-lima/almodon/internal/support/resource"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID identifies every request, carrying its ID in the context
+// and in the X-Request-ID header of the response. An ID given by the
+// client, as by a proxy, in the same header is kept, if it is a UUID.
+func RequestID(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.FromString(r.Header.Get(RequestIDHeader))
+		if err != nil {
+			id = uuid.NewUUIDv7()
+		}
+
+		w.Header().Set(RequestIDHeader, id.String())
+		handler.ServeHTTP(w, r.WithContext(logging.WithRequest(r.Context(), id)))
+	})
+}
+
+// AccessLog logs every request once it is served, along with its
+// status, latency, the bytes written and the actor, if any. It is
+// meant to be wrapped by [RequestID].
+func AccessLog(log *slog.Logger, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := NewW(w)
+
+		handler.ServeHTTP(rw, r)
+
+		attrs := []slog.Attr{
+			slog.Int("status", rw.StatusCode()),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.RequestURI()),
+			slog.String("address", resource.Address(r)),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int64("bytes", rw.Written()),
+		}
+
+		if route, ok := logging.Route(r.Context()); ok {
+			attrs = append(attrs, slog.String("route", route))
+		}
+
+		if actor, ok := logging.Actor(r.Context()); ok {
+			attrs = append(attrs, slog.String("actor", actor.String()))
+		}
+
+		log.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
+	})
+}
@@ -6,8 +6,9 @@ import (
 )
 
 type ResponseWriter struct {
-	w     http.ResponseWriter
-	status int
+	w       http.ResponseWriter
+	status  int
+	written int64
 }
 
 func NewW(w http.ResponseWriter) *ResponseWriter {
@@ -19,9 +20,16 @@ func (rw *ResponseWriter) WriteHeader(status int) {
 	rw.status = status
 }
 
-func (rw *ResponseWriter) Header() http.Header           { return rw.w.Header() }
-func (rw *ResponseWriter) Write(buf []byte) (int, error) { return rw.w.Write(buf) }
-func (rw *ResponseWriter) StatusCode() int               { return rw.status }
+func (rw *ResponseWriter) Write(buf []byte) (int, error) {
+	n, err := rw.w.Write(buf)
+	rw.written += int64(n)
+	return n, err
+}
+
+func (rw *ResponseWriter) Header() http.Header         { return rw.w.Header() }
+func (rw *ResponseWriter) StatusCode() int             { return rw.status }
+func (rw *ResponseWriter) Written() int64              { return rw.written }
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter { return rw.w }
 
 type ResponseWriterFlusher struct {
 	wf interface {
@@ -45,7 +53,6 @@ func NewWF(w http.ResponseWriter) (*ResponseWriterFlusher, error) {
 	return &ResponseWriterFlusher{wf: wf, status: 200}, nil
 }
 
-
 func (rw *ResponseWriterFlusher) WriteHeader(status int) {
 	rw.wf.WriteHeader(status)
 	rw.status = status
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrLocked is returned by [Open] when the directory of the json
+// backend is held by another process, such as a running server.
+var ErrLocked = errors.New("storage: directory is locked by another process")
+
+// lock is an exclusive lock on a directory, held through a file in it
+// for as long as the repositories are open.
+type lock struct {
+	file *os.File
+}
@@ -0,0 +1,34 @@
+//go:build !unix
+
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// acquire takes the lock by creating the file, which must not exist.
+// If the process dies while holding it, the file must be removed by
+// hand.
+func acquire(name string) (*lock, error) {
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &lock{file: file}, nil
+}
+
+// unlock releases the lock by removing its file.
+func (l *lock) unlock() error {
+	name := l.file.Name()
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// acquire takes the lock of the file, which the system releases if
+// the process dies while holding it.
+func acquire(name string) (*lock, error) {
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrLocked
+		}
+		return nil, err
+	}
+
+	return &lock{file: file}, nil
+}
+
+// unlock releases the lock. The file is left in place, as removing it
+// would let another process lock a file no longer in the directory.
+func (l *lock) unlock() error {
+	return l.file.Close()
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/alan-b-lima/almodon/internal/config"
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	auditrepo "github.com/alan-b-lima/almodon/internal/domain/audit/repository"
+	"github.com/alan-b-lima/almodon/internal/domain/outbox"
+	outboxrepo "github.com/alan-b-lima/almodon/internal/domain/outbox/repository"
+	"github.com/alan-b-lima/almodon/internal/domain/product"
+	productrepo "github.com/alan-b-lima/almodon/internal/domain/product/repository"
+	"github.com/alan-b-lima/almodon/internal/domain/promotion"
+	promotionrepo "github.com/alan-b-lima/almodon/internal/domain/promotion/repository"
+	"github.com/alan-b-lima/almodon/internal/domain/session"
+	sessionrepo "github.com/alan-b-lima/almodon/internal/domain/session/repository"
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	tokenrepo "github.com/alan-b-lima/almodon/internal/domain/token/repository"
+	"github.com/alan-b-lima/almodon/internal/domain/twofactor"
+	twofactorrepo "github.com/alan-b-lima/almodon/internal/domain/twofactor/repository"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	userrepo "github.com/alan-b-lima/almodon/internal/domain/user/repository"
+)
+
+// Repositories are the repositories of every domain, opened over the
+// same backend.
+type Repositories struct {
+	Audit      audit.Repository
+	Messages   outbox.Repository
+	Products   product.Repository
+	Promotions promotion.Repository
+	Sessions   session.Repository
+	Tokens     token.Repository
+	TwoFactors twofactor.Repository
+	Users      user.Repository
+
+	path string
+	lock *lock
+}
+
+// Open opens the repositories over the configured backend.
+// Promotions are always kept in memory.
+//
+// The json backend locks its directory until the repositories are
+// closed, failing with [ErrLocked] if another process holds it, as
+// each would overwrite the records of the other.
+func Open(cfg config.Storage) (*Repositories, error) {
+	repos := Repositories{
+		Promotions: promotionrepo.NewMap(),
+	}
+
+	switch cfg.Backend {
+	case "memory":
+		repos.Audit = auditrepo.NewMap()
+		repos.Messages = outboxrepo.NewMap()
+		repos.Products = productrepo.NewMap()
+		repos.Sessions = sessionrepo.NewMap()
+		repos.Tokens = tokenrepo.NewMap()
+		repos.TwoFactors = twofactorrepo.NewMap()
+		repos.Users = userrepo.NewMap()
+
+	case "json":
+		if err := os.MkdirAll(cfg.Path, 0o700); err != nil {
+			return nil, fmt.Errorf("storage: %w", err)
+		}
+
+		lock, err := acquire(filepath.Join(cfg.Path, ".lock"))
+		if errors.Is(err, ErrLocked) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("storage: %w", err)
+		}
+
+		repos.path, repos.lock = cfg.Path, lock
+		path := func(name string) string { return filepath.Join(cfg.Path, name+".json") }
+
+		var errs [7]error
+		repos.Audit, errs[0] = auditrepo.NewPersistantMap(path("audit"))
+		repos.Messages, errs[1] = outboxrepo.NewPersistantMap(path("outbox"))
+		repos.Products, errs[2] = productrepo.NewPersistantMap(path("products"))
+		repos.Sessions, errs[3] = sessionrepo.NewPersistantMap(path("sessions"))
+		repos.Tokens, errs[4] = tokenrepo.NewPersistantMap(path("tokens"))
+		repos.TwoFactors, errs[5] = twofactorrepo.NewPersistantMap(path("twofactors"))
+		repos.Users, errs[6] = userrepo.NewPersistantMap(path("users"))
+
+		if err := errors.Join(errs[:]...); err != nil {
+			return nil, fmt.Errorf("storage: %w", errors.Join(err, lock.unlock()))
+		}
+
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q", cfg.Backend)
+	}
+
+	return &repos, nil
+}
+
+// Close closes every repository that needs closing, which, for the
+// json backend, writes their records to disk.
+func (r *Repositories) Close() error {
+	var errs []error
+	for _, repo := range r.all() {
+		if closer, ok := repo.(interface{ Close() error }); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+
+	// the lock is released only once every record is written
+	if r.lock != nil {
+		errs = append(errs, r.lock.unlock())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Ping reports whether the repositories are available. For the json
+// backend, whose records are written only when closed, that is whether
+// the directory still exists and every data file can be written.
+func (r *Repositories) Ping() error {
+	var errs []error
+
+	if r.path != "" {
+		if info, err := os.Stat(r.path); err != nil {
+			errs = append(errs, fmt.Errorf("storage: %w", err))
+		} else if !info.IsDir() {
+			errs = append(errs, fmt.Errorf("storage: %s is not a directory", r.path))
+		}
+	}
+
+	for _, repo := range r.all() {
+		if pinger, ok := repo.(interface{ Ping() error }); ok {
+			errs = append(errs, pinger.Ping())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Repositories) all() []any {
+	return []any{r.Audit, r.Messages, r.Products, r.Promotions, r.Sessions, r.Tokens, r.TwoFactors, r.Users}
+}
@@ -0,0 +1,65 @@
+package storage_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alan-b-lima/almodon/internal/config"
+	. "github.com/alan-b-lima/almodon/internal/storage"
+)
+
+func TestOpenLocks(t *testing.T) {
+	cfg := config.Storage{Backend: "json", Path: t.TempDir()}
+
+	repos, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(cfg); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected the directory to be locked, got %v", err)
+	}
+
+	if err := repos.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err = Open(cfg)
+	if err != nil {
+		t.Fatalf("expected the directory to be unlocked once closed, got %v", err)
+	}
+
+	if err := repos.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPing(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Storage{Backend: "json", Path: dir}
+
+	repos, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repos.Ping(); err != nil {
+		t.Errorf("expected the storage to be available, got %v", err)
+	}
+
+	// a directory in place of a data file cannot be written, not even
+	// by root
+	users := filepath.Join(dir, "users.json")
+	if err := os.Remove(users); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(users, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repos.Ping(); err == nil {
+		t.Errorf("expected the storage to be unavailable once a data file cannot be written")
+	}
+}
@@ -0,0 +1,151 @@
+package entity
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Version counts the changes made to an entity, it is 1 once created
+// and is incremented by every change thereafter. Versions are exposed
+// to clients as entity tags, as `"1"`, so that changes may be made
+// conditional to the version they were based on, while in JSON they
+// are plain numbers.
+type Version uint64
+
+// MarshalJSON implements the [json.Marshaler] interface on the type.
+func (v Version) MarshalJSON() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(v), 10), nil
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface on the
+// type.
+func (v *Version) UnmarshalJSON(buf []byte) error {
+	n, err := strconv.ParseUint(string(buf), 10, 64)
+	if err != nil {
+		return errors.New("version must be a non-negative integer")
+	}
+
+	*v = Version(n)
+	return nil
+}
+
+// MarshalText implements the [encoding.TextMarshaler] interface on the
+// type, encoding the version as a strong entity tag.
+func (v Version) MarshalText() ([]byte, error) {
+	return strconv.AppendQuote(nil, strconv.FormatUint(uint64(v), 10)), nil
+}
+
+// UnmarshalText implements the [encoding.TextUnmarshaler] interface on
+// the type, decoding the version from a strong entity tag.
+func (v *Version) UnmarshalText(buf []byte) error {
+	unquoted, err := strconv.Unquote(string(buf))
+	if err != nil || !strings.HasPrefix(string(buf), `"`) {
+		return errors.New("entity tag must be a quoted version")
+	}
+
+	n, err := strconv.ParseUint(unquoted, 10, 64)
+	if err != nil {
+		return errors.New("entity tag must be a quoted version")
+	}
+
+	*v = Version(n)
+	return nil
+}
+
+// Precondition is the condition a change is made under, as given by
+// the If-Match header: either any version, as by "*", or one of a list
+// of them. The zero value is no precondition at all.
+type Precondition struct {
+	given    bool
+	any      bool
+	versions []Version
+}
+
+// Match is the precondition of the version being one of those given.
+func Match(versions ...Version) Precondition {
+	return Precondition{given: true, versions: versions}
+}
+
+// MatchAny is the precondition of there being any version at all.
+func MatchAny() Precondition {
+	return Precondition{given: true, any: true}
+}
+
+// Given reports whether the precondition was given.
+func (p Precondition) Given() bool {
+	return p.given
+}
+
+// Matches reports whether the version satisfies the precondition,
+// every version does if none was given.
+func (p Precondition) Matches(v Version) bool {
+	if !p.given || p.any {
+		return true
+	}
+
+	for _, version := range p.versions {
+		if version == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalText implements the [encoding.TextMarshaler] interface on the
+// type, encoding the precondition as the value of an If-Match header.
+func (p Precondition) MarshalText() ([]byte, error) {
+	if p.any {
+		return []byte("*"), nil
+	}
+
+	var buf []byte
+	for i, v := range p.versions {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+
+		tag, _ := v.MarshalText()
+		buf = append(buf, tag...)
+	}
+
+	return buf, nil
+}
+
+// UnmarshalText implements the [encoding.TextUnmarshaler] interface on
+// the type, decoding the precondition from the value of an If-Match
+// header. Weak entity tags are accepted, but never match, as If-Match
+// is compared strongly.
+func (p *Precondition) UnmarshalText(buf []byte) error {
+	text := strings.TrimSpace(string(buf))
+	if text == "*" {
+		*p = MatchAny()
+		return nil
+	}
+
+	var versions []Version
+	for tag := range strings.SplitSeq(text, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		var v Version
+		if weak, ok := strings.CutPrefix(tag, "W/"); ok {
+			if err := v.UnmarshalText([]byte(weak)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := v.UnmarshalText([]byte(tag)); err != nil {
+			return err
+		}
+
+		versions = append(versions, v)
+	}
+
+	*p = Match(versions...)
+	return nil
+}
@@ -0,0 +1,281 @@
+// Package listing implements the listing of records shared by the
+// repositories: records matching a filter are sorted by some of their
+// fields and paged through opaque cursors.
+//
+// Ties are broken by the UUID of the records, so the order is stable
+// across requests. As UUIDv7 are ordered by their time of creation,
+// listings sorted by no field are listed in the order records were
+// created.
+package listing
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// Query is a query over the records of a repository, the filter is
+// given apart, as it is particular to each of them.
+type Query struct {
+	// Sort is the order records are listed in, by field, before
+	// being ordered by UUID.
+	Sort []Order
+
+	// After is the cursor of the page, the first page is given by the
+	// empty cursor.
+	After Cursor
+
+	Limit int
+}
+
+// CheckOffset rejects the offset listings were paged by before
+// cursors, rather than serving the first page as if it were the one
+// asked for.
+func CheckOffset(offset opt.Opt[int]) error {
+	if _, ok := offset.Unwrap(); ok {
+		return xerrors.ErrBadQueryParams.New(xerrors.ErrBadQueryParam.New("offset", "is no longer supported, follow the cursor of the previous page instead"))
+	}
+
+	return nil
+}
+
+// Order orders records by a field, ascending unless Desc.
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+// Page is a page of records. Next is the cursor of the following
+// page, empty if this is the last.
+type Page[E any] struct {
+	Records      []E
+	TotalRecords int
+	Next         Cursor
+}
+
+// Cursor is an opaque position within a listing, it holds the values
+// the last record listed was sorted by.
+type Cursor string
+
+// Schema describes how records are identified and the fields they
+// may be sorted by.
+type Schema[E any] struct {
+	ID     func(*E) uuid.UUID
+	Fields map[string]func(*E) Key
+}
+
+// ParseSort parses the order of a listing, as "name,-siape", each
+// field descending if prefixed by a minus sign. Specs may be given
+// either apart or separated by commas.
+func (s *Schema[E]) ParseSort(specs ...string) ([]Order, error) {
+	var orders []Order
+	for _, spec := range specs {
+		for field := range strings.SplitSeq(spec, ",") {
+			if field == "" {
+				continue
+			}
+
+			name, desc := strings.CutPrefix(field, "-")
+			if _, ok := s.Fields[name]; !ok {
+				return nil, xerrors.ErrBadSort.New(name)
+			}
+
+			orders = append(orders, Order{Field: name, Desc: desc})
+		}
+	}
+
+	return orders, nil
+}
+
+// List lists the page of the records matching, as given by the query.
+// Records are sorted by the fields of the query, then by UUID, in the
+// direction of the last field, if any, or ascending otherwise.
+func (s *Schema[E]) List(records []E, match func(*E) bool, q Query) (Page[E], error) {
+	for _, order := range q.Sort {
+		if _, ok := s.Fields[order.Field]; !ok {
+			return Page[E]{}, xerrors.ErrBadSort.New(order.Field)
+		}
+	}
+
+	var all []E
+	for i := range records {
+		if match(&records[i]) {
+			all = append(all, records[i])
+		}
+	}
+
+	slices.SortFunc(all, func(a, b E) int {
+		return compare(q.Sort, s.position(q.Sort, &a), s.position(q.Sort, &b))
+	})
+
+	lo := 0
+	if q.After != "" {
+		after, err := decode(q.Sort, q.After)
+		if err != nil {
+			return Page[E]{}, err
+		}
+
+		lo, _ = slices.BinarySearchFunc(all, after, func(e E, after position) int {
+			if compare(q.Sort, s.position(q.Sort, &e), after) <= 0 {
+				return -1
+			}
+			return 1
+		})
+	}
+
+	hi := min(lo+max(q.Limit, 0), len(all))
+
+	page := Page[E]{
+		Records:      all[lo:hi:hi],
+		TotalRecords: len(all),
+	}
+
+	if hi < len(all) && hi > lo {
+		page.Next = encode(q.Sort, s.position(q.Sort, &all[hi-1]))
+	}
+
+	return page, nil
+}
+
+// position is where a record lies within a listing, by the keys of
+// the fields it is sorted by and its UUID.
+type position struct {
+	Keys []Key     `json:"k"`
+	UUID uuid.UUID `json:"u"`
+}
+
+func (s *Schema[E]) position(sort []Order, e *E) position {
+	keys := make([]Key, len(sort))
+	for i, order := range sort {
+		keys[i] = s.Fields[order.Field](e)
+	}
+
+	return position{Keys: keys, UUID: s.ID(e)}
+}
+
+func compare(sort []Order, a, b position) int {
+	desc := false
+	for i, order := range sort {
+		c := a.Keys[i].Compare(b.Keys[i])
+		if desc = order.Desc; desc {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	c := bytes.Compare(a.UUID[:], b.UUID[:])
+	if desc {
+		c = -c
+	}
+
+	return c
+}
+
+// cursor is the content of a cursor, the sort it was issued for is
+// kept, so that it is not used for another.
+type cursor struct {
+	Sort string   `json:"s"`
+	Pos  position `json:"p"`
+}
+
+func encode(sort []Order, pos position) Cursor {
+	buf, _ := json.Marshal(cursor{Sort: format(sort), Pos: pos})
+	return Cursor(base64.RawURLEncoding.EncodeToString(buf))
+}
+
+func decode(sort []Order, c Cursor) (position, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(string(c))
+	if err != nil {
+		return position{}, xerrors.ErrBadCursor
+	}
+
+	var cur cursor
+	if err := json.Unmarshal(buf, &cur); err != nil {
+		return position{}, xerrors.ErrBadCursor
+	}
+
+	if cur.Sort != format(sort) || len(cur.Pos.Keys) != len(sort) {
+		return position{}, xerrors.ErrBadCursor
+	}
+
+	return cur.Pos, nil
+}
+
+func format(sort []Order) string {
+	var b strings.Builder
+	for i, order := range sort {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if order.Desc {
+			b.WriteByte('-')
+		}
+		b.WriteString(order.Field)
+	}
+
+	return b.String()
+}
+
+// Key is the value of a field records are sorted by, either a string
+// or an integer.
+type Key struct {
+	num int64
+	str string
+}
+
+// String is the key of a string field.
+func String(s string) Key {
+	return Key{str: s}
+}
+
+// Int is the key of an integer field.
+func Int(n int64) Key {
+	return Key{num: n}
+}
+
+// Time is the key of a time field, as its Unix time in nanoseconds.
+func Time(t time.Time) Key {
+	return Int(t.UnixNano())
+}
+
+// Compare compares the keys, integers first, then strings.
+func (k Key) Compare(o Key) int {
+	if c := cmp.Compare(k.num, o.num); c != 0 {
+		return c
+	}
+
+	return strings.Compare(k.str, o.str)
+}
+
+type key struct {
+	Num int64  `json:"n,omitempty"`
+	Str string `json:"s,omitempty"`
+}
+
+// MarshalJSON implements the [json.Marshaler] interface on the type.
+func (k Key) MarshalJSON() ([]byte, error) {
+	return json.Marshal(key{Num: k.num, Str: k.str})
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface on the
+// type.
+func (k *Key) UnmarshalJSON(buf []byte) error {
+	var v key
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return err
+	}
+
+	*k = Key{num: v.Num, str: v.Str}
+	return nil
+}
@@ -0,0 +1,156 @@
+package listing_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/alan-b-lima/almodon/internal/support/listing"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type record struct {
+	UUID uuid.UUID
+	Name string
+	Age  int
+}
+
+var schema = listing.Schema[record]{
+	ID: func(r *record) uuid.UUID { return r.UUID },
+	Fields: map[string]func(*record) listing.Key{
+		"name": func(r *record) listing.Key { return listing.String(r.Name) },
+		"age":  func(r *record) listing.Key { return listing.Int(int64(r.Age)) },
+	},
+}
+
+func all(*record) bool { return true }
+
+// names pages through the whole listing, a page of the given size at
+// a time.
+func names(t *testing.T, records []record, sort []listing.Order, limit int) []string {
+	var names []string
+
+	q := listing.Query{Sort: sort, Limit: limit}
+	for {
+		page, err := schema.List(records, all, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if page.TotalRecords != len(records) {
+			t.Fatalf("expected %d records in total, got %d", len(records), page.TotalRecords)
+		}
+
+		for _, r := range page.Records {
+			names = append(names, r.Name)
+		}
+
+		if page.Next == "" {
+			return names
+		}
+		q.After = page.Next
+	}
+}
+
+func TestList(t *testing.T) {
+	var records []record
+	for _, r := range []struct {
+		name string
+		age  int
+	}{{"carol", 30}, {"alice", 30}, {"bob", 25}, {"dave", 40}, {"erin", 25}} {
+		records = append(records, record{UUID: uuid.NewUUIDv7(), Name: r.name, Age: r.age})
+	}
+
+	type Tests struct {
+		sort string
+		exp  []string
+	}
+
+	tests := []Tests{
+		{"", []string{"carol", "alice", "bob", "dave", "erin"}},
+		{"name", []string{"alice", "bob", "carol", "dave", "erin"}},
+		{"-name", []string{"erin", "dave", "carol", "bob", "alice"}},
+		{"age,name", []string{"bob", "erin", "alice", "carol", "dave"}},
+		{"-age", []string{"dave", "alice", "carol", "erin", "bob"}},
+	}
+
+	for _, test := range tests {
+		sort, err := schema.ParseSort(test.sort)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, limit := range []int{1, 2, 5, 10} {
+			if got := names(t, records, sort, limit); !slices.Equal(got, test.exp) {
+				t.Errorf("%q by %d: expected %v, got %v", test.sort, limit, test.exp, got)
+			}
+		}
+	}
+}
+
+func TestListAfterChanges(t *testing.T) {
+	var records []record
+	for _, name := range []string{"a", "b", "c", "d"} {
+		records = append(records, record{UUID: uuid.NewUUIDv7(), Name: name})
+	}
+
+	page, err := schema.List(records, all, listing.Query{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the last record listed is deleted and another is created before
+	// the next page is requested
+	records = slices.Delete(records, 1, 2)
+	records = append([]record{{UUID: uuid.NewUUIDv7(), Name: "e"}}, records...)
+
+	page, err = schema.List(records, all, listing.Query{After: page.Next, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, r := range page.Records {
+		got = append(got, r.Name)
+	}
+
+	if exp := []string{"c", "d", "e"}; !slices.Equal(got, exp) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestListErrors(t *testing.T) {
+	if _, err := schema.ParseSort("name,height"); err == nil {
+		t.Error("expected an unknown field to be rejected")
+	}
+
+	records := []record{{UUID: uuid.NewUUIDv7(), Name: "a"}, {UUID: uuid.NewUUIDv7(), Name: "b"}}
+
+	page, err := schema.List(records, all, listing.Query{Sort: []listing.Order{{Field: "name"}}, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, q := range []listing.Query{
+		{After: "not a cursor", Limit: 1},
+		{After: page.Next, Limit: 1},
+		{Sort: []listing.Order{{Field: "name", Desc: true}}, After: page.Next, Limit: 1},
+	} {
+		if _, err := schema.List(records, all, q); err == nil {
+			t.Errorf("expected cursor %q to be rejected for %v", q.After, q.Sort)
+		}
+	}
+}
+
+func TestCheckOffset(t *testing.T) {
+	if err := listing.CheckOffset(opt.None[int]()); err != nil {
+		t.Errorf("expected no offset to be accepted, got %v", err)
+	}
+
+	// even the offset of the first page is rejected, as a client giving
+	// it still pages by offset
+	if err, ok := errors.AsType[*errors.Error](listing.CheckOffset(opt.Some(0))); !ok || err.Title != "bad-query" {
+		t.Errorf("expected an offset to be rejected as a bad query, got %v", err)
+	}
+}
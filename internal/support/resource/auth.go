@@ -1,10 +1,13 @@
 package resource
 
 import (
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/logging"
 	"github.com/alan-b-lima/almodon/internal/xerrors"
 	"github.com/alan-b-lima/almodon/pkg/errors"
 	"github.com/alan-b-lima/almodon/pkg/uuid"
@@ -12,7 +15,35 @@ import (
 
 const SessionCookie = "session"
 
+// Session resolves the actor of the request, either from a personal
+// access token given in the Authorization header or from the session
+// cookie. Unlike a missing or stale cookie, which yields an unlogged
+// actor, a bad token is reported, as its bearer expects to be logged.
 func Session(rc gatekeeper, r *http.Request) (auth.Actor, error) {
+	act, err := actor(rc, r)
+	if err == nil {
+		logging.SetActor(r.Context(), act.User())
+	}
+
+	return act.WithAddress(Address(r)), err
+}
+
+// Address returns the network address of the client of the request,
+// without the port.
+func Address(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func actor(rc gatekeeper, r *http.Request) (auth.Actor, error) {
+	if token, ok := bearer(r); ok {
+		return rc.TokenActor(token)
+	}
+
 	session, err := session(r)
 	if err != nil {
 		return auth.NewUnlogged(), nil
@@ -29,8 +60,29 @@ func Session(rc gatekeeper, r *http.Request) (auth.Actor, error) {
 	return act, err
 }
 
+// SessionID returns the uuid of the session carried by the request,
+// without resolving it to an actor.
+func SessionID(r *http.Request) (uuid.UUID, error) {
+	return session(r)
+}
+
 type gatekeeper interface {
 	Actor(session uuid.UUID) (auth.Actor, error)
+	TokenActor(token string) (auth.Actor, error)
+}
+
+func bearer(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", false
+	}
+
+	scheme, token, _ := strings.Cut(header, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
 }
 
 func session(r *http.Request) (uuid.UUID, error) {
@@ -47,16 +99,14 @@ func session(r *http.Request) (uuid.UUID, error) {
 	return session, nil
 }
 
-func SetSession(w http.ResponseWriter, uuid uuid.UUID, expires time.Time) {
+func SetSession(w http.ResponseWriter, policy CookiePolicy, uuid uuid.UUID, expires time.Time) {
 	cookie := &http.Cookie{
 		Name:     SessionCookie,
 		Value:    uuid.String(),
 		Expires:  expires,
 		Path:     "/",
 		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteLaxMode,
 	}
 
-	http.SetCookie(w, cookie)
+	http.SetCookie(w, policy.Apply(cookie))
 }
@@ -0,0 +1,36 @@
+package resource
+
+import "net/http"
+
+// CookiePolicy is how the cookies set by resources are scoped and
+// secured.
+type CookiePolicy struct {
+	// Secure restricts cookies to HTTPS, it should only be disabled
+	// when serving plain HTTP during development.
+	Secure bool
+
+	// Domain, if not empty, shares cookies with its subdomains.
+	Domain string
+
+	// SameSite is used for cookies that do not require a specific
+	// mode.
+	SameSite http.SameSite
+}
+
+var DefaultCookiePolicy = CookiePolicy{
+	Secure:   true,
+	SameSite: http.SameSiteLaxMode,
+}
+
+// Apply sets the attributes of the cookie governed by the policy,
+// keeping its SameSite mode, if set.
+func (p *CookiePolicy) Apply(cookie *http.Cookie) *http.Cookie {
+	cookie.Secure = p.Secure
+	cookie.Domain = p.Domain
+
+	if cookie.SameSite == 0 {
+		cookie.SameSite = p.SameSite
+	}
+
+	return cookie
+}
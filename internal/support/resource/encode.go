@@ -0,0 +1,404 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+)
+
+// Encoder encodes responses in a media type.
+type Encoder struct {
+	// MediaType is the media type of the encoding, as "text/csv",
+	// matched against the Accept header of requests.
+	MediaType string
+
+	// ContentType is the Content-Type of responses, if other than
+	// the media type, as to give a charset.
+	ContentType string
+
+	// Encode writes the response to w.
+	Encode func(w io.Writer, res any) error
+
+	// Stream tells whether responses are flushed as they are
+	// written, rather than buffered whole, so that large ones start
+	// arriving early. Errors of streamed encodings cannot be
+	// reported once the response has begun.
+	Stream bool
+}
+
+// Encoders are the encoders responses may be encoded with, by order
+// of preference.
+type Encoders []Encoder
+
+var (
+	// JSON encodes responses as JSON.
+	JSON = Encoder{
+		MediaType:   "application/json",
+		ContentType: "application/json; charset=utf-8",
+		Encode:      func(w io.Writer, res any) error { return json.NewEncoder(w).Encode(res) },
+	}
+
+	// CSV encodes the rows of responses as CSV, with a header of
+	// their columns, see [Rows] and [Columns].
+	CSV = Encoder{
+		MediaType:   "text/csv",
+		ContentType: "text/csv; charset=utf-8",
+		Encode:      encodeCSV,
+	}
+
+	// NDJSON encodes the rows of responses as newline-delimited JSON,
+	// one row per line, streamed, see [Rows].
+	NDJSON = Encoder{
+		MediaType: "application/x-ndjson",
+		Encode:    encodeNDJSON,
+		Stream:    true,
+	}
+)
+
+// DefaultEncoders are the encoders responses of endpoints are
+// encoded with, JSON being preferred. Further encoders may be
+// registered by appending them.
+var DefaultEncoders = Encoders{JSON, CSV, NDJSON}
+
+// Encode encodes the response with the default encoders, see
+// [Encoders.Encode].
+func Encode(res any, status int, w http.ResponseWriter, r *http.Request) error {
+	return DefaultEncoders.Encode(res, status, w, r)
+}
+
+// Encode encodes the response with the encoder negotiated through the
+// Accept header of the request, see [Encoders.Negotiate].
+func (e Encoders) Encode(res any, status int, w http.ResponseWriter, r *http.Request) error {
+	enc, ok := e.Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		return xerrors.ErrNotAcceptable.New(strings.Join(e.mediaTypes(), ", "))
+	}
+
+	contentType := enc.ContentType
+	if contentType == "" {
+		contentType = enc.MediaType
+	}
+
+	if enc.Stream {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+
+		return enc.Encode(&flusher{w: w, rc: http.NewResponseController(w)}, res)
+	}
+
+	b := bufPool.Get().(*bytes.Buffer)
+	defer bufPool.Put(b)
+	b.Reset()
+
+	if err := enc.Encode(b, res); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+
+	if _, err := io.Copy(w, b); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Negotiate chooses the encoder of the media type most preferred by
+// the Accept header, by quality, breaking ties by the order of the
+// encoders. Media types excluded by name, with a quality of 0, are
+// never chosen, not even through ranges, as "*/*".
+func (e Encoders) Negotiate(accept string) (Encoder, bool) {
+	quality := make([]float64, len(e))
+	excluded := make([]bool, len(e))
+
+	for rng := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(rng))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if val, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(val, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+
+		for i, enc := range e {
+			switch {
+			case mediaType == enc.MediaType && q == 0:
+				excluded[i] = true
+			case matches(mediaType, enc.MediaType):
+				quality[i] = max(quality[i], q)
+			}
+		}
+	}
+
+	best := -1
+	for i := range e {
+		if excluded[i] || quality[i] == 0 {
+			continue
+		}
+
+		if best < 0 || quality[i] > quality[best] {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return Encoder{}, false
+	}
+
+	return e[best], true
+}
+
+func (e Encoders) mediaTypes() []string {
+	types := make([]string, len(e))
+	for i, enc := range e {
+		types[i] = enc.MediaType
+	}
+
+	return types
+}
+
+// matches reports whether the media range, as "text/*", includes the
+// media type.
+func matches(rng, mediaType string) bool {
+	if rng == "*/*" || rng == mediaType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(rng, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// flusher flushes every write through to the client.
+type flusher struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (f *flusher) Write(buf []byte) (int, error) {
+	n, err := f.w.Write(buf)
+	if err != nil {
+		return n, err
+	}
+
+	if err := f.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// Rows returns the rows of a response, as encoded by tabular
+// encodings: the elements of the field tagged with `encode:"rows"`,
+// as the records of a listing, or of the response itself, if a slice,
+// or else the response as a single row.
+func Rows(res any) []reflect.Value {
+	rv := reflect.ValueOf(res)
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() == reflect.Struct {
+		for i := range rv.NumField() {
+			if rv.Type().Field(i).Tag.Get("encode") == "rows" {
+				rv = rv.Field(i)
+				break
+			}
+		}
+	}
+
+	if rv.Kind() != reflect.Slice {
+		return []reflect.Value{rv}
+	}
+
+	rows := make([]reflect.Value, rv.Len())
+	for i := range rows {
+		rows[i] = rv.Index(i)
+	}
+
+	return rows
+}
+
+// Column is a column of a tabular encoding, the path of indices
+// leads to its field, through embedded structs.
+type Column struct {
+	Name  string
+	Index []int
+}
+
+// Columns returns the columns of rows of the type, one per field
+// marshaled to JSON, named by its "csv" tag, as in `csv:"Name"`, or
+// else by its JSON name. Fields tagged with `csv:"-"` are left out,
+// and the fields of untagged embedded structs are promoted.
+func Columns(t reflect.Type) []Column {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return []Column{{Name: "value"}}
+	}
+
+	var columns []Column
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for _, column := range Columns(field.Type) {
+				column.Index = append([]int{i}, column.Index...)
+				columns = append(columns, column)
+			}
+			continue
+		}
+
+		if csv, ok := field.Tag.Lookup("csv"); ok {
+			if csv == "-" {
+				continue
+			}
+			name = csv
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		columns = append(columns, Column{Name: name, Index: []int{i}})
+	}
+
+	return columns
+}
+
+func encodeCSV(w io.Writer, res any) error {
+	rows := Rows(res)
+
+	var t reflect.Type
+	if len(rows) > 0 {
+		t = rows[0].Type()
+	} else {
+		t = reflect.TypeOf(res)
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		if t.Kind() == reflect.Struct {
+			for i := range t.NumField() {
+				if t.Field(i).Tag.Get("encode") == "rows" {
+					t = t.Field(i).Type
+					break
+				}
+			}
+		}
+
+		if t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+	}
+
+	columns := Columns(t)
+
+	cw := csv.NewWriter(w)
+
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.Name
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		for i, column := range columns {
+			cell, err := cell(row, column.Index)
+			if err != nil {
+				return err
+			}
+			record[i] = cell
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// cell encodes the field of the row as a cell, as it is marshaled to
+// JSON, strings being unquoted and nulls left empty, so that values
+// read the same across encodings.
+//
+// Strings that spreadsheets would take as formulas, starting with "=",
+// "+", "-", "@", a tab or a carriage return, are prefixed with "'",
+// so that user input opened from an export is never evaluated.
+func cell(row reflect.Value, index []int) (string, error) {
+	for row.Kind() == reflect.Pointer {
+		if row.IsNil() {
+			return "", nil
+		}
+		row = row.Elem()
+	}
+
+	if index != nil {
+		field, err := row.FieldByIndexErr(index)
+		if err != nil {
+			return "", nil
+		}
+		row = field
+	}
+
+	buf, err := json.Marshal(row.Interface())
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case string(buf) == "null":
+		return "", nil
+
+	case len(buf) > 0 && buf[0] == '"':
+		var s string
+		if err := json.Unmarshal(buf, &s); err != nil {
+			return "", err
+		}
+
+		if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+			s = "'" + s
+		}
+		return s, nil
+	}
+
+	return string(buf), nil
+}
+
+func encodeNDJSON(w io.Writer, res any) error {
+	enc := json.NewEncoder(w)
+	for _, row := range Rows(res) {
+		if err := enc.Encode(row.Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
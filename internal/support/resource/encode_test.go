@@ -0,0 +1,156 @@
+package resource_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/internal/support/resource"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+)
+
+type (
+	row struct {
+		Name    string             `json:"name" csv:"Name"`
+		Price   int                `json:"price_cents"`
+		Expires opt.Opt[time.Time] `json:"expires"`
+		Tags    []string           `json:"tags"`
+		Secret  string             `json:"-"`
+		Note    string             `json:"note" csv:"-"`
+	}
+
+	page struct {
+		Length int    `json:"length"`
+		Rows   []row  `json:"rows" encode:"rows"`
+		Next   string `json:"next,omitempty" header:"Next-Cursor"`
+	}
+
+	created struct {
+		row
+		ID string `json:"id"`
+	}
+)
+
+func TestNegotiate(t *testing.T) {
+	type Tests struct {
+		accept string
+		exp    string
+	}
+
+	tests := []Tests{
+		{"application/json", "application/json"},
+		{"text/csv", "text/csv"},
+		{"application/x-ndjson", "application/x-ndjson"},
+		{"*/*", "application/json"},
+		{"text/*", "text/csv"},
+		{"text/html, text/csv;q=0.5, application/json;q=0.4", "text/csv"},
+		{"application/*, text/csv", "application/json"},
+		{"*/*, application/json;q=0", "text/csv"},
+		{"*/*;q=0.1, application/x-ndjson", "application/x-ndjson"},
+		{"text/html", ""},
+		{"application/json;q=0", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		enc, ok := DefaultEncoders.Negotiate(test.accept)
+		if ok != (test.exp != "") || enc.MediaType != test.exp {
+			t.Errorf("%q: expected %q, got %q", test.accept, test.exp, enc.MediaType)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	expires := time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	res := page{
+		Length: 2,
+		Rows: []row{
+			{Name: "Açaí, 1kg", Price: 2590, Expires: opt.Some(expires), Tags: []string{"fruit"}, Secret: "s", Note: "n"},
+			{Name: `"Plain" water`, Price: 300},
+		},
+	}
+
+	type Tests struct {
+		accept string
+		res    any
+
+		contentType string
+		body        string
+	}
+
+	tests := []Tests{
+		{
+			"text/csv", res,
+			"text/csv; charset=utf-8",
+			"Name,price_cents,expires,tags\n" +
+				`"Açaí, 1kg",2590,2026-01-02T03:04:05Z,"[""fruit""]"` + "\n" +
+				`"""Plain"" water",300,,` + "\n",
+		},
+		{
+			"text/csv", page{},
+			"text/csv; charset=utf-8",
+			"Name,price_cents,expires,tags\n",
+		},
+		{
+			"text/csv", created{row: row{Name: "x"}, ID: "1"},
+			"text/csv; charset=utf-8",
+			"Name,price_cents,expires,tags,id\nx,0,,,1\n",
+		},
+		{
+			"text/csv", page{Rows: []row{
+				{Name: "=HYPERLINK(\"http://x\")", Price: -1},
+				{Name: "+1"}, {Name: "-1"}, {Name: "@SUM(A1)"}, {Name: "\tx"}, {Name: "a=b"},
+			}},
+			"text/csv; charset=utf-8",
+			"Name,price_cents,expires,tags\n" +
+				`"'=HYPERLINK(""http://x"")",-1,,` + "\n" +
+				"'+1,0,,\n'-1,0,,\n'@SUM(A1),0,,\n'\tx,0,,\na=b,0,,\n",
+		},
+		{
+			"application/x-ndjson", res,
+			"application/x-ndjson",
+			`{"name":"Açaí, 1kg","price_cents":2590,"expires":"2026-01-02T03:04:05Z","tags":["fruit"],"note":"n"}` + "\n" +
+				`{"name":"\"Plain\" water","price_cents":300,"expires":null,"tags":null,"note":""}` + "\n",
+		},
+		{
+			"application/json", page{Length: 0, Rows: []row{}},
+			"application/json; charset=utf-8",
+			`{"length":0,"rows":[]}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/rows", nil)
+		r.Header.Set("Accept", test.accept)
+
+		w := httptest.NewRecorder()
+		if err := Encode(test.res, http.StatusOK, w, r); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.accept, err)
+			continue
+		}
+
+		if got := w.Header().Get("Content-Type"); got != test.contentType {
+			t.Errorf("%s: expected content type %q, got %q", test.accept, test.contentType, got)
+		}
+
+		if w.Body.String() != test.body {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", test.accept, test.body, w.Body)
+		}
+	}
+}
+
+func TestEncodeNotAcceptable(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/rows", nil)
+	r.Header.Set("Accept", "text/html")
+
+	w := httptest.NewRecorder()
+	if err := Encode(page{}, http.StatusOK, w, r); err == nil {
+		t.Fatalf("expected an error, got %d: %s", w.Code, w.Body)
+	}
+
+	if w.Body.Len() != 0 {
+		t.Errorf("expected nothing written, got %s", w.Body)
+	}
+}
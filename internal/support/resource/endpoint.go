@@ -0,0 +1,288 @@
+package resource
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// Endpoint adapts a call of a service into a handler. The actor is
+// resolved by the gatekeeper and the request is bound out of the HTTP
+// request, see [Bind], then the response is encoded with the given
+// status.
+//
+// Fields of the response tagged with "header" are sent as the header
+// of that name, rather than in the body. Responses to reads tagged
+// with an ETag listed by the If-None-Match header of the request are
+// responded to with 304 Not Modified.
+func Endpoint[Req, Res any](gk gatekeeper, status int, call func(auth.Actor, Req) (Res, error)) http.HandlerFunc {
+	body := hasBody(reflect.TypeFor[Req]())
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		act, err := Session(gk, r)
+		if err != nil {
+			WriteJsonError(w, err)
+			return
+		}
+
+		var req Req
+		if err := bind(&req, r, body); err != nil {
+			WriteJsonError(w, err)
+			return
+		}
+
+		res, err := call(act, req)
+		if err != nil {
+			WriteJsonError(w, err)
+			return
+		}
+
+		if err := writeHeaders(w, &res); err != nil {
+			WriteJsonError(w, err)
+			return
+		}
+
+		if notModified(r, w.Header().Get("ETag")) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		if err := Encode(&res, status, w, r); err != nil {
+			WriteJsonError(w, err)
+			return
+		}
+	}
+}
+
+// Action adapts a call of a service with no response into a handler,
+// as [Endpoint] does, responding with 204 No Content.
+func Action[Req any](gk gatekeeper, call func(auth.Actor, Req) error) http.HandlerFunc {
+	body := hasBody(reflect.TypeFor[Req]())
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		act, err := Session(gk, r)
+		if err != nil {
+			WriteJsonError(w, err)
+			return
+		}
+
+		var req Req
+		if err := bind(&req, r, body); err != nil {
+			WriteJsonError(w, err)
+			return
+		}
+
+		if err := call(act, req); err != nil {
+			WriteJsonError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Bind binds the HTTP request into the struct pointed to by req.
+// Fields tagged with "path" take the path wildcard of that name, those
+// tagged with "query" are bound as by [QueryParams], those tagged with
+// "header" take the header of that name, if present, unless marked as
+// required, as in `header:"If-Match,required"`, and the remaining
+// ones, if any, are decoded from the JSON body. Path wildcards take
+// precedence over the query, and both over the body.
+func Bind(req any, r *http.Request) error {
+	return bind(req, r, hasBody(reflect.TypeOf(req).Elem()))
+}
+
+func bind(req any, r *http.Request, body bool) error {
+	if body {
+		if err := DecodeJSON(req, r); err != nil {
+			return err
+		}
+	}
+
+	if err := QueryParams(r.URL.Query(), req); err != nil {
+		return err
+	}
+
+	if err := headerValues(r, req); err != nil {
+		return err
+	}
+
+	return pathValues(r, req)
+}
+
+var (
+	_TypeUUID            = reflect.TypeFor[uuid.UUID]()
+	_TypeTextUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+	_TypeTextMarshaler   = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// pathValues binds the fields tagged with "path" to the wildcards of
+// the request.
+func pathValues(r *http.Request, req any) error {
+	rv := reflect.ValueOf(req).Elem()
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		name := rt.Field(i).Tag.Get("path")
+		if name == "" {
+			continue
+		}
+
+		val := r.PathValue(name)
+		field := rv.Field(i)
+
+		switch {
+		case field.Type() == _TypeUUID:
+			uuid, err := uuid.FromString(val)
+			if err != nil {
+				return xerrors.ErrBadUUID
+			}
+
+			field.Set(reflect.ValueOf(uuid))
+
+		case field.Kind() == reflect.String:
+			field.SetString(val)
+
+		case field.CanInt():
+			num, err := strconv.ParseInt(val, 10, field.Type().Bits())
+			if err != nil {
+				return xerrors.ErrBadPathParam.New(name)
+			}
+
+			field.SetInt(num)
+
+		case reflect.PointerTo(field.Type()).Implements(_TypeTextUnmarshaler):
+			if err := field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val)); err != nil {
+				return xerrors.ErrBadPathParam.New(name)
+			}
+
+		default:
+			return xerrors.ErrBadPathParam.New(name)
+		}
+	}
+
+	return nil
+}
+
+// headerValues binds the fields tagged with "header" to the headers of
+// the request, fields whose header is absent are left untouched. As
+// headers are required as preconditions, as If-Match, missing ones are
+// reported as required preconditions.
+func headerValues(r *http.Request, req any) error {
+	rv := reflect.ValueOf(req).Elem()
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		name, opts, _ := strings.Cut(rt.Field(i).Tag.Get("header"), ",")
+		if name == "" {
+			continue
+		}
+
+		val := r.Header.Get(name)
+		if val == "" {
+			if opts == "required" {
+				return xerrors.ErrHeaderRequired.New(name)
+			}
+			continue
+		}
+
+		field := rv.Field(i)
+
+		switch {
+		case field.Kind() == reflect.String:
+			field.SetString(val)
+
+		case reflect.PointerTo(field.Type()).Implements(_TypeTextUnmarshaler):
+			if err := field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val)); err != nil {
+				return xerrors.ErrBadHeader.New(name)
+			}
+
+		default:
+			return fmt.Errorf("header: field %s: unsupported type %v", rt.Field(i).Name, field.Type())
+		}
+	}
+
+	return nil
+}
+
+// writeHeaders sets the headers of the response out of the fields of
+// the struct pointed to by res tagged with "header", zero fields are
+// left out.
+func writeHeaders(w http.ResponseWriter, res any) error {
+	rv := reflect.ValueOf(res).Elem()
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		name, _, _ := strings.Cut(rt.Field(i).Tag.Get("header"), ",")
+		if name == "" || rv.Field(i).IsZero() {
+			continue
+		}
+
+		field := rv.Field(i)
+
+		switch {
+		case field.Kind() == reflect.String:
+			w.Header().Set(name, field.String())
+
+		case field.Type().Implements(_TypeTextMarshaler):
+			buf, err := field.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return err
+			}
+
+			w.Header().Set(name, string(buf))
+
+		default:
+			return fmt.Errorf("header: field %s: unsupported type %v", rt.Field(i).Name, field.Type())
+		}
+	}
+
+	return nil
+}
+
+// notModified reports whether the request is a read conditioned to the
+// entity tag not being any of those listed by its If-None-Match header,
+// which is compared weakly.
+func notModified(r *http.Request, etag string) bool {
+	if etag == "" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for tag := range strings.SplitSeq(r.Header.Get("If-None-Match"), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasBody reports whether any field of the struct is left to be
+// decoded from the body, that is, exported fields neither tagged with
+// "path" nor "query" nor "header" nor hidden from JSON.
+func hasBody(t reflect.Type) bool {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		if field.Tag.Get("path") == "" && field.Tag.Get("query") == "" && field.Tag.Get("header") == "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,177 @@
+package resource_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+	. "github.com/alan-b-lima/almodon/internal/support/resource"
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type gatekeeper struct{}
+
+func (gatekeeper) Actor(uuid.UUID) (auth.Actor, error)   { return auth.NewUnlogged(), nil }
+func (gatekeeper) TokenActor(string) (auth.Actor, error) { return auth.NewUnlogged(), nil }
+
+type request struct {
+	UUID  uuid.UUID `json:"-" path:"uuid"`
+	Count int       `json:"-" path:"count"`
+	Limit int       `query:"limit" default:"10"`
+	Name  string    `json:"name"`
+}
+
+func TestEndpoint(t *testing.T) {
+	var got request
+	call := func(act auth.Actor, req request) (request, error) {
+		got = req
+		return req, nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /things/{uuid}/{count}", Endpoint(gatekeeper{}, http.StatusCreated, call))
+
+	id := uuid.NewUUIDv7()
+
+	type Tests struct {
+		path, body string
+		status     int
+		exp        request
+	}
+
+	tests := []Tests{
+		{"/things/" + id.String() + "/3", `{"name":"x"}`, http.StatusCreated, request{UUID: id, Count: 3, Limit: 10, Name: "x"}},
+		{"/things/" + id.String() + "/3?limit=5", `{"name":"y"}`, http.StatusCreated, request{UUID: id, Count: 3, Limit: 5, Name: "y"}},
+		{"/things/" + id.String() + "/three", `{"name":"x"}`, http.StatusBadRequest, request{}},
+		{"/things/not-a-uuid/3", `{"name":"x"}`, http.StatusBadRequest, request{}},
+		{"/things/" + id.String() + "/3?limit=many", `{"name":"x"}`, http.StatusBadRequest, request{}},
+	}
+
+	for _, test := range tests {
+		got = request{}
+
+		r := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", "application/json")
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("%s: expected %d, got %d: %s", test.path, test.status, w.Code, w.Body)
+			continue
+		}
+
+		if got != test.exp {
+			t.Errorf("%s: expected %+v, got %+v", test.path, test.exp, got)
+		}
+
+		if test.status != http.StatusBadRequest {
+			continue
+		}
+
+		var err errors.Error
+		if json.Unmarshal(w.Body.Bytes(), &err) != nil || err.Kind != errors.InvalidInput {
+			t.Errorf("%s: expected an invalid input error, got %s", test.path, w.Body)
+		}
+	}
+}
+
+func TestAction(t *testing.T) {
+	called := false
+	call := func(act auth.Actor, req struct {
+		UUID uuid.UUID `json:"-" path:"uuid"`
+	}) error {
+		called = true
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("DELETE /things/{uuid}", Action(gatekeeper{}, call))
+
+	// requests with nothing left for the body are not required to
+	// have one
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/things/"+uuid.NewUUIDv7().String(), nil))
+
+	if w.Code != http.StatusNoContent || !called {
+		t.Errorf("expected the action to be called, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestEndpointConditional(t *testing.T) {
+	type thing struct {
+		Name    string         `json:"name"`
+		Version entity.Version `json:"-" header:"ETag"`
+	}
+
+	current := thing{Name: "x", Version: 3}
+
+	get := func(act auth.Actor, req struct{}) (thing, error) {
+		return current, nil
+	}
+
+	patch := func(act auth.Actor, req struct {
+		IfMatch entity.Precondition `json:"-" header:"If-Match,required"`
+	}) error {
+		if !req.IfMatch.Matches(current.Version) {
+			return xerrors.ErrVersionMismatch
+		}
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /thing", Endpoint(gatekeeper{}, http.StatusOK, get))
+	mux.Handle("PATCH /thing", Action(gatekeeper{}, patch))
+
+	type Tests struct {
+		method, header, value string
+		status                int
+	}
+
+	tests := []Tests{
+		{http.MethodGet, "", "", http.StatusOK},
+		{http.MethodGet, "If-None-Match", `"3"`, http.StatusNotModified},
+		{http.MethodGet, "If-None-Match", `W/"3"`, http.StatusNotModified},
+		{http.MethodGet, "If-None-Match", `"1", "2"`, http.StatusOK},
+		{http.MethodGet, "If-None-Match", `*`, http.StatusNotModified},
+		{http.MethodPatch, "", "", http.StatusPreconditionRequired},
+		{http.MethodPatch, "If-Match", `"2"`, http.StatusPreconditionFailed},
+		{http.MethodPatch, "If-Match", `W/"3"`, http.StatusPreconditionFailed},
+		{http.MethodPatch, "If-Match", `"2", "3"`, http.StatusNoContent},
+		{http.MethodPatch, "If-Match", `*`, http.StatusNoContent},
+		{http.MethodPatch, "If-Match", `3`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, "/thing", nil)
+		r.Header.Set("Accept", "application/json")
+		if test.header != "" {
+			r.Header.Set(test.header, test.value)
+		}
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("%s %s: %s: expected %d, got %d: %s", test.method, test.header, test.value, test.status, w.Code, w.Body)
+			continue
+		}
+
+		if test.method == http.MethodGet {
+			if etag := w.Header().Get("ETag"); etag != `"3"` {
+				t.Errorf("%s: expected the ETag of the version, got %q", test.value, etag)
+			}
+
+			if test.status == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("%s: expected no body, got %s", test.value, w.Body)
+			}
+		}
+	}
+}
@@ -12,13 +12,20 @@ import (
 	"github.com/alan-b-lima/almodon/pkg/errors"
 )
 
+// WriteJsonError writes the error with the status of its kind. Errors
+// wrapping an [*errors.Error] are written as it, unless they marshal
+// themselves, as failures located at fields do.
 func WriteJsonError(w http.ResponseWriter, err error) {
 	if err == nil {
 		return
 	}
 
-	if err, ok := errors.AsType[*errors.Error](err); ok {
-		writeJsonError(w, err, toHTTPStatus(err.Kind))
+	if e, ok := errors.AsType[*errors.Error](err); ok {
+		if _, ok := err.(json.Marshaler); !ok {
+			err = e
+		}
+
+		writeJsonError(w, err, toHTTPStatus(e.Kind))
 		return
 	}
 
@@ -38,13 +45,14 @@ func writeJsonError(w http.ResponseWriter, err error, status int) {
 }
 
 var statusCodes = map[errors.Kind]int{
-	errors.InvalidInput:       http.StatusBadRequest,
-	errors.Unauthorized:       http.StatusUnauthorized,
-	errors.Forbidden:          http.StatusForbidden,
-	errors.PreconditionFailed: http.StatusPreconditionFailed,
-	errors.NotFound:           http.StatusNotFound,
-	errors.Conflict:           http.StatusConflict,
-	errors.Timeout:            http.StatusRequestTimeout,
+	errors.InvalidInput:         http.StatusBadRequest,
+	errors.Unauthorized:         http.StatusUnauthorized,
+	errors.Forbidden:            http.StatusForbidden,
+	errors.PreconditionFailed:   http.StatusPreconditionFailed,
+	errors.PreconditionRequired: http.StatusPreconditionRequired,
+	errors.NotFound:             http.StatusNotFound,
+	errors.Conflict:             http.StatusConflict,
+	errors.Timeout:              http.StatusRequestTimeout,
 
 	errors.Internal:    http.StatusInternalServerError,
 	errors.Unavailable: http.StatusServiceUnavailable,
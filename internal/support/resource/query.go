@@ -1,23 +1,45 @@
 package resource
 
 import (
-	"errors"
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alan-b-lima/almodon/internal/xerrors"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
 )
 
+// QueryParams binds the query into the fields of the struct pointed to
+// by v tagged with "query", as in `query:"name"`. Fields whose
+// parameter is absent take the value of their "default" tag, if any,
+// or else are left untouched, unless marked as required, as in
+// `query:"name,required"`.
+//
+// Fields may be strings, booleans, numbers, [time.Time], given either
+// in RFC 3339 or as a date, [uuid.UUID], types implementing
+// [encoding.TextUnmarshaler], optionals of those, as [opt.Opt], or
+// slices of those, given by repeating the parameter or by separating
+// values with commas.
+//
+// Bad parameters are reported as [xerrors.ErrBadQueryParams], caused
+// by each of them, while fields of unsupported types are reported as
+// internal errors.
 func QueryParams(q url.Values, v any) error {
 	rt := reflect.ValueOf(v)
 
 	if rt.Kind() != reflect.Pointer {
-		return errors.New("query: v must be a pointer type")
+		return fmt.Errorf("query: v must be a pointer type")
 	}
 
 	st := rt.Elem()
 	if st.Kind() != reflect.Struct {
-		return errors.New("query: v must point to a struct type")
+		return fmt.Errorf("query: v must point to a struct type")
 	}
 
 	return queryParams(q, v)
@@ -28,40 +50,205 @@ func queryParams(q url.Values, v any) error {
 	rv := reflect.ValueOf(v).Elem()
 
 	if !rv.CanSet() {
-		return errors.New("query: cannot change contents of v")
+		return fmt.Errorf("query: cannot change contents of v")
 	}
 
+	var errs []error
 	for i := range rt.NumField() {
 		field := rt.Field(i)
-		query := field.Tag.Get("query")
 
-		if !q.Has(query) {
+		tag := field.Tag.Get("query")
+		if tag == "" {
 			continue
 		}
 
-		val := q.Get(query)
-
-		switch t := field.Type; t.Kind() {
-		case reflect.String:
-			rv.Field(i).SetString(val)
+		name, opts, _ := strings.Cut(tag, ",")
+
+		vals, ok := q[name]
+		if !ok {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				vals = []string{def}
+			} else if opts == "required" {
+				errs = append(errs, xerrors.ErrBadQueryParam.New(name, "is required"))
+				continue
+			} else {
+				continue
+			}
+		}
 
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			num, err := strconv.ParseInt(val, 10, int(t.Size())*8)
-			if err != nil {
-				return fmt.Errorf("query: not convertible to an %v: %w", t, err)
+		if err := setQuery(rv.Field(i), vals); err != nil {
+			if _, ok := err.(*unsupportedError); ok {
+				return fmt.Errorf("query: field %s: %w", field.Name, err)
 			}
 
-			rv.Field(i).SetInt(int64(num))
+			errs = append(errs, xerrors.ErrBadQueryParam.New(name, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return xerrors.ErrBadQueryParams.New(err)
+	}
+
+	return nil
+}
+
+var (
+	_TypeTime        = reflect.TypeFor[time.Time]()
+	_TypeUnmarshaler = reflect.TypeFor[json.Unmarshaler]()
+)
+
+type unsupportedError struct{ t reflect.Type }
+
+func (e *unsupportedError) Error() string {
+	return fmt.Sprintf("unsupported type %v", e.t)
+}
+
+// setQuery sets the field to the values of its parameter, only the
+// last of which is taken by fields other than slices.
+func setQuery(field reflect.Value, vals []string) error {
+	t := field.Type()
 
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			num, err := strconv.ParseUint(val, 10, int(t.Size())*8)
-			if err != nil {
-				return fmt.Errorf("query: not convertible to an %v: %w", t, err)
+	if t.Kind() == reflect.Slice && !isScalar(t) {
+		var elems []string
+		for _, val := range vals {
+			elems = append(elems, strings.Split(val, ",")...)
+		}
+
+		slice := reflect.MakeSlice(t, len(elems), len(elems))
+		for i, elem := range elems {
+			if err := setScalar(slice.Index(i), elem); err != nil {
+				return err
 			}
+		}
+
+		field.Set(slice)
+		return nil
+	}
+
+	return setScalar(field, vals[len(vals)-1])
+}
+
+// setScalar parses a single value into the field.
+func setScalar(field reflect.Value, val string) error {
+	t := field.Type()
+
+	switch {
+	case t == _TypeTime:
+		tm, err := parseTime(val)
+		if err != nil {
+			return err
+		}
+
+		field.Set(reflect.ValueOf(tm))
+		return nil
+
+	case t == _TypeUUID:
+		uuid, err := uuid.FromString(val)
+		if err != nil {
+			return fmt.Errorf("must be a UUID")
+		}
+
+		field.Set(reflect.ValueOf(uuid))
+		return nil
+
+	case reflect.PointerTo(t).Implements(_TypeTextUnmarshaler):
+		if err := field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val)); err != nil {
+			return fmt.Errorf("is malformed: %w", err)
+		}
+		return nil
+	}
+
+	if inner, ok := optional(t); ok {
+		// optionals are set through their JSON unmarshaling, which
+		// takes whatever is not null as present
+		elem := reflect.New(inner).Elem()
+		if err := setScalar(elem, val); err != nil {
+			return err
+		}
 
-			rv.Field(i).SetUint(uint64(num))
+		buf, err := json.Marshal(elem.Interface())
+		if err != nil {
+			return err
 		}
+
+		return field.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(buf)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		field.SetString(val)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+
+		field.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num, err := strconv.ParseInt(val, 10, t.Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+
+		field.SetInt(num)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num, err := strconv.ParseUint(val, 10, t.Bits())
+		if err != nil {
+			return fmt.Errorf("must be a non-negative integer")
+		}
+
+		field.SetUint(num)
+
+	case reflect.Float32, reflect.Float64:
+		num, err := strconv.ParseFloat(val, t.Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+
+		field.SetFloat(num)
+
+	default:
+		return &unsupportedError{t}
 	}
 
 	return nil
 }
+
+// parseTime parses either a RFC 3339 time or a date, at midnight UTC.
+func parseTime(val string) (time.Time, error) {
+	if tm, err := time.Parse(time.RFC3339, val); err == nil {
+		return tm, nil
+	}
+
+	if tm, err := time.Parse(time.DateOnly, val); err == nil {
+		return tm, nil
+	}
+
+	return time.Time{}, fmt.Errorf("must be a RFC 3339 time or a date")
+}
+
+// isScalar reports whether the type is taken as a single value, even
+// if a slice, as those unmarshaling themselves.
+func isScalar(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(_TypeTextUnmarshaler)
+}
+
+// optional reports whether the type is an optional, that is, one with
+// an Unwrap() (T, bool) method and a JSON unmarshaling, and if so,
+// the type T.
+func optional(t reflect.Type) (reflect.Type, bool) {
+	m, ok := t.MethodByName("Unwrap")
+	if !ok || !reflect.PointerTo(t).Implements(_TypeUnmarshaler) {
+		return nil, false
+	}
+
+	mt := m.Type
+	if mt.NumIn() != 1 || mt.NumOut() != 2 || mt.Out(1).Kind() != reflect.Bool {
+		return nil, false
+	}
+
+	return mt.Out(0), true
+}
@@ -0,0 +1,93 @@
+package resource_test
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/internal/support/resource"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+type query struct {
+	Active bool          `query:"active"`
+	Ratio  float64       `query:"ratio"`
+	Since  time.Time     `query:"since"`
+	Owner  uuid.UUID     `query:"owner"`
+	Max    opt.Opt[uint] `query:"max"`
+	Tags   []string      `query:"tag"`
+	Limit  int           `query:"limit" default:"10"`
+	Name   string        `query:"name,required"`
+}
+
+func TestQueryParams(t *testing.T) {
+	id := uuid.NewUUIDv7()
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	type Tests struct {
+		query string
+		exp   query
+		bad   []string
+	}
+
+	tests := []Tests{
+		{"name=x", query{Name: "x", Limit: 10}, nil},
+		{"name=x&active=true&ratio=0.5&limit=3", query{Name: "x", Active: true, Ratio: 0.5, Limit: 3}, nil},
+		{"name=x&since=2025-03-01", query{Name: "x", Since: since, Limit: 10}, nil},
+		{"name=x&since=2025-03-01T00:00:00Z", query{Name: "x", Since: since, Limit: 10}, nil},
+		{"name=x&owner=" + id.String(), query{Name: "x", Owner: id, Limit: 10}, nil},
+		{"name=x&max=7", query{Name: "x", Max: opt.Some[uint](7), Limit: 10}, nil},
+		{"name=x&tag=a&tag=b,c", query{Name: "x", Tags: []string{"a", "b", "c"}, Limit: 10}, nil},
+		{"", query{}, []string{"name"}},
+		{"name=x&active=maybe&max=-1&since=yesterday", query{}, []string{"active", "since", "max"}},
+		{"name=x&owner=someone&limit=many", query{}, []string{"owner", "limit"}},
+	}
+
+	for _, test := range tests {
+		q, err := url.ParseQuery(test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got query
+		err = QueryParams(q, &got)
+
+		if test.bad == nil {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", test.query, err)
+				continue
+			}
+
+			if !reflect.DeepEqual(got, test.exp) {
+				t.Errorf("%q: expected %+v, got %+v", test.query, test.exp, got)
+			}
+			continue
+		}
+
+		if e, ok := errors.AsType[*errors.Error](err); !ok || e.Title != "bad-query" {
+			t.Errorf("%q: expected bad query parameters, got %v", test.query, err)
+			continue
+		}
+
+		for _, name := range test.bad {
+			if !strings.Contains(err.Error(), `"`+name+`"`) {
+				t.Errorf("%q: expected %q to be reported, got %v", test.query, name, err)
+			}
+		}
+	}
+}
+
+func TestQueryParamsUnsupported(t *testing.T) {
+	var v struct {
+		M map[string]string `query:"m"`
+	}
+
+	err := QueryParams(url.Values{"m": {"x"}}, &v)
+	if _, ok := errors.AsType[*errors.Error](err); err == nil || ok {
+		t.Errorf("expected an internal error, got %v", err)
+	}
+}
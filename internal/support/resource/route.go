@@ -0,0 +1,38 @@
+package resource
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/alan-b-lima/almodon/internal/logging"
+)
+
+// Routed reports the pattern the request was routed by, without its
+// method, to the middlewares wrapping the mux, which only see the
+// request before it is routed, as a pattern such as "/users/{uuid}"
+// tells apart what the path does not. Prefixes stripped from the path
+// before routing are restored, as in "/api/v1/users/{uuid}".
+func Routed(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pattern := r.Pattern
+		if _, path, ok := strings.Cut(pattern, " "); ok {
+			pattern = path
+		}
+
+		logging.SetRoute(r.Context(), prefix(r)+pattern)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// prefix returns what was stripped from the path of the request, as
+// by [http.StripPrefix], which keeps the original request URI.
+func prefix(r *http.Request) string {
+	original, _, _ := strings.Cut(r.RequestURI, "?")
+
+	prefix, ok := strings.CutSuffix(original, r.URL.EscapedPath())
+	if !ok || !strings.HasPrefix(prefix, "/") {
+		return ""
+	}
+
+	return prefix
+}
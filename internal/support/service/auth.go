@@ -5,10 +5,20 @@ import (
 	"github.com/alan-b-lima/almodon/internal/xerrors"
 )
 
-func Authorize(auth auth.Permission, act auth.Actor) error {
-	if role := act.Role(); !auth.Authorize(role) {
-		return xerrors.ErrUnauthorizedUser.New(role, auth)
+// Authorize checks the action against the policy, reporting why the
+// actor is not allowed to perform it, if that is the case.
+func Authorize(policy *auth.Policy, act auth.Actor, action string, rc auth.Resource) error {
+	if policy.Authorize(act, action, rc) {
+		return nil
 	}
 
-	return nil
+	if !act.InScope(action) {
+		return xerrors.ErrOutOfScope.New(action)
+	}
+
+	if role := act.Role(); !role.IsValid() {
+		return xerrors.ErrUnauthenticatedUser.New(nil)
+	}
+
+	return xerrors.ErrUnauthorizedUser.New(action, act.Role())
 }
@@ -0,0 +1,5 @@
+package xerrors
+
+import "github.com/alan-b-lima/almodon/pkg/errors"
+
+var ErrAuditRecord = errors.Imp(errors.Internal, "audit-record", "failed to record the action")
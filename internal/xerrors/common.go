@@ -10,12 +10,36 @@ var (
 	ErrBadUUID        = errors.New(errors.InvalidInput, "bad-uuid", "given UUID could not be parsed", nil)
 	ErrBadRole        = errors.New(errors.InvalidInput, "bad-role", "given role could not be parsed", nil)
 	ErrBadQueryParams = errors.Imp(errors.InvalidInput, "bad-query", "bad query parameters")
+	ErrBadQueryParam  = errors.Fmt(errors.InvalidInput, "bad-query-param", "query parameter %q %v")
+	ErrBadPathParam   = errors.Fmt(errors.InvalidInput, "bad-path", "bad path parameter %q")
+	ErrBadHeader      = errors.Fmt(errors.InvalidInput, "bad-header", "bad header %q")
+	ErrBadSort        = errors.Fmt(errors.InvalidInput, "bad-sort", "cannot sort by %q")
+	ErrBadCursor      = errors.New(errors.InvalidInput, "bad-cursor", "given cursor is malformed or was issued for another sort", nil)
 
 	ErrNoContentType              = errors.New(errors.PreconditionFailed, "no-content-type", "content type must be informed", nil)
 	ErrUnsupportedContentTypeJson = errors.New(errors.PreconditionFailed, "unsupported-content-type", "content type must be application/json", nil)
 	ErrJsonSyntax                 = errors.Fmt(errors.InvalidInput, "json-syntax-error", "JSON syntax error at %d")
 	ErrJsonType                   = errors.Fmt(errors.InvalidInput, "json-type-error", "JSON type error at %d, expected %v but got %v")
 	ErrNotAcceptableJson          = errors.New(errors.PreconditionFailed, "not-acceptable-type", "client does not accept application/json", nil)
+	ErrNotAcceptable              = errors.Fmt(errors.PreconditionFailed, "not-acceptable-type", "client accepts none of %s")
+
+	ErrHeaderRequired  = errors.Fmt(errors.PreconditionRequired, "header-required", "header %q is required")
+	ErrVersionMismatch = errors.New(errors.PreconditionFailed, "version-mismatch", "resource was changed since the given version", nil)
+)
+
+var (
+	ErrCrossOriginRequest = errors.New(errors.Forbidden, "cross-origin-request", "cross-origin request was rejected", nil)
+	ErrCSRFTokenMissing   = errors.New(errors.Forbidden, "csrf-token-missing", "CSRF token must be informed in both cookie and header", nil)
+	ErrCSRFTokenMismatch  = errors.New(errors.Forbidden, "csrf-token-mismatch", "CSRF tokens in cookie and header do not match", nil)
+)
+
+var (
+	ErrBadIdempotencyKey    = errors.New(errors.InvalidInput, "bad-idempotency-key", "idempotency key must be at most 255 characters long", nil)
+	ErrIdempotencyKeyReused = errors.New(errors.Conflict, "idempotency-key-reused", "idempotency key was already used for another request", nil)
+	ErrIdempotencyInFlight  = errors.New(errors.Conflict, "idempotency-in-flight", "request with the same idempotency key is still being served", nil)
+	ErrIdempotencyLimit     = errors.New(errors.Conflict, "idempotency-limit", "too many idempotency keys are kept for the actor, try again later", nil)
+
+	ErrIdempotentBodyTooLarge = errors.Fmt(errors.InvalidInput, "idempotent-body-too-large", "body of requests with an idempotency key must be at most %d bytes")
 )
 
 var ErrTODO = errors.New(errors.Internal, "todo", "implement me", nil)
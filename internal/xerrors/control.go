@@ -0,0 +1,8 @@
+package xerrors
+
+import "github.com/alan-b-lima/almodon/pkg/errors"
+
+var (
+	ErrConfigReload    = errors.Imp(errors.InvalidInput, "config-reload", "configuration could not be reloaded")
+	ErrRestartRequired = errors.Fmt(errors.Conflict, "restart-required", "changing %s requires a restart")
+)
@@ -0,0 +1,16 @@
+package xerrors
+
+import "github.com/alan-b-lima/almodon/pkg/errors"
+
+var (
+	ErrDirectoryConfig      = errors.Fmt(errors.Internal, "directory-config", "directory is misconfigured: %s")
+	ErrDirectoryUnavailable = errors.Imp(errors.Unavailable, "directory-unavailable", "directory server could not be reached")
+	ErrDirectoryFailure     = errors.Imp(errors.Internal, "directory-failure", "directory server failed to authenticate the user")
+)
+
+var (
+	ErrSingleSignOnDisabled = errors.New(errors.NotFound, "sso-disabled", "single sign-on is not enabled", nil)
+	ErrSingleSignOnState    = errors.New(errors.Unauthorized, "sso-state", "single sign-on state is missing or does not match", nil)
+	ErrSingleSignOnFailed   = errors.Imp(errors.Unauthorized, "sso-failed", "identity provider failed to authenticate the user")
+	ErrSingleSignOnNoSIAPE  = errors.Fmt(errors.Forbidden, "sso-no-siape", "identity provider did not give a valid siape in the %q claim")
+)
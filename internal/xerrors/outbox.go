@@ -0,0 +1,17 @@
+package xerrors
+
+import "github.com/alan-b-lima/almodon/pkg/errors"
+
+var (
+	ErrMessageCreation  = errors.Imp(errors.InvalidInput, "message-creation", "given data does not satisfy the message type")
+	ErrNoRecipients     = errors.New(errors.InvalidInput, "no-recipients", "message must have at least one recipient", nil)
+	ErrRecipientInvalid = errors.Fmt(errors.InvalidInput, "recipient-invalid", "recipient %q must be a valid address")
+	ErrSubjectEmpty     = errors.New(errors.InvalidInput, "subject-empty", "subject cannot be empty", nil)
+	ErrMessageEmpty     = errors.New(errors.InvalidInput, "message-empty", "message must have a text or HTML body", nil)
+
+	ErrTemplateNotFound = errors.Fmt(errors.Internal, "template-not-found", "template %q not found")
+	ErrTemplateRender   = errors.Imp(errors.Internal, "template-render", "failed to render the template")
+
+	ErrBadStatus       = errors.New(errors.InvalidInput, "bad-status", "given status could not be parsed", nil)
+	ErrMessageNotFound = errors.New(errors.NotFound, "message-not-found", "message not found", nil)
+)
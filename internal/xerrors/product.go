@@ -0,0 +1,16 @@
+package xerrors
+
+import "github.com/alan-b-lima/almodon/pkg/errors"
+
+var (
+	ErrProductCreation = errors.Imp(errors.InvalidInput, "product-creation", "given data does not satisfy the product type")
+	ErrProductUpdate   = errors.Imp(errors.InvalidInput, "product-update", "given data does not satisfy the product type")
+
+	ErrProductNameTooLong        = errors.Fmt(errors.InvalidInput, "product-name-too-long", "product name must be a maximum of %d characters long")
+	ErrProductDescriptionTooLong = errors.Fmt(errors.InvalidInput, "product-description-too-long", "product description must be a maximum of %d characters long")
+	ErrProductCodeInvalid        = errors.Fmt(errors.InvalidInput, "product-code-invalid", "%s code must be made of up to %d digits")
+	ErrProductUnitEmpty          = errors.New(errors.InvalidInput, "product-unit-empty", "unit of supply cannot be empty", nil)
+	ErrProductUnitTooLong        = errors.Fmt(errors.InvalidInput, "product-unit-too-long", "unit of supply must be a maximum of %d characters long")
+
+	ErrProductNotFound = errors.New(errors.NotFound, "product-not-found", "product not found", nil)
+)
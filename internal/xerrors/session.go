@@ -3,7 +3,10 @@ package xerrors
 import "github.com/alan-b-lima/almodon/pkg/errors"
 
 var (
-	ErrSessionTooLong = errors.Fmt(errors.InvalidInput, "session-too-long", "session must not last longer than %v")
+	ErrSessionTooShort     = errors.New(errors.InvalidInput, "session-too-short", "session must last a positive duration", nil)
+	ErrSessionTooLong      = errors.Fmt(errors.InvalidInput, "session-too-long", "session must not last longer than %v")
+	ErrSessionLevelInvalid = errors.New(errors.InvalidInput, "session-level-invalid", "session level must be valid", nil)
+	ErrSessionNotPending   = errors.New(errors.Conflict, "session-not-pending", "session is not awaiting a second factor", nil)
 
 	ErrSessionNotFound = errors.New(errors.NotFound, "session-not-found", "session not found", nil)
 )
@@ -0,0 +1,16 @@
+package xerrors
+
+import "github.com/alan-b-lima/almodon/pkg/errors"
+
+var (
+	ErrTokenCreation      = errors.Imp(errors.InvalidInput, "token-creation", "given data does not satisfy the token type")
+	ErrTokenNameTooLong   = errors.Fmt(errors.InvalidInput, "token-name-too-long", "token name must be a maximum of %d characters long")
+	ErrTokenNoScopes      = errors.New(errors.InvalidInput, "token-no-scopes", "token must have at least one scope", nil)
+	ErrScopeInvalid       = errors.Fmt(errors.InvalidInput, "scope-invalid", "scope %q must be of the form \"resource:action\", \"resource:*\" or \"*\"")
+	ErrScopeNotHeld       = errors.Fmt(errors.Forbidden, "scope-not-held", "scope %q is not within the scopes of the actor")
+	ErrTokenExpiresInPast = errors.New(errors.InvalidInput, "token-expires-in-past", "token expiration must be in the future", nil)
+	ErrTokenTooLong       = errors.Fmt(errors.InvalidInput, "token-too-long", "token must not last longer than %v")
+
+	ErrTokenNotFound = errors.New(errors.NotFound, "token-not-found", "token not found", nil)
+	ErrTokenInvalid  = errors.New(errors.Unauthorized, "token-invalid", "given token is invalid or expired", nil)
+)
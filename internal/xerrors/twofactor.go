@@ -0,0 +1,15 @@
+package xerrors
+
+import "github.com/alan-b-lima/almodon/pkg/errors"
+
+var (
+	ErrTwoFactorNotFound    = errors.New(errors.NotFound, "two-factor-not-found", "second factor not found", nil)
+	ErrTwoFactorEnabled     = errors.New(errors.Conflict, "two-factor-enabled", "second factor is already enabled", nil)
+	ErrTwoFactorNotEnabled  = errors.New(errors.PreconditionFailed, "two-factor-not-enabled", "second factor is not enabled", nil)
+	ErrTwoFactorCodeInvalid = errors.New(errors.Unauthorized, "two-factor-code-invalid", "given code is incorrect", nil)
+	ErrTwoFactorCodeReused  = errors.New(errors.Unauthorized, "two-factor-code-reused", "given code has already been used", nil)
+	ErrTwoFactorLocked      = errors.New(errors.Forbidden, "two-factor-locked", "too many codes failed, try again later", nil)
+	ErrSecondFactorPending  = errors.New(errors.Unauthorized, "second-factor-pending", "session awaits a second factor", nil)
+
+	ErrQRCode = errors.Imp(errors.Internal, "qr-code", "failed to generate the QR code")
+)
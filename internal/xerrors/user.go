@@ -17,7 +17,8 @@ var (
 	ErrFailedToHashPassword = errors.Imp(errors.Internal, "hash-failure", "failed to hash the password")
 
 	ErrUnauthenticatedUser = errors.Imp(errors.Unauthorized, "unauthenticated-user", "user is not logged in")
-	ErrUnauthorizedUser    = errors.Fmt(errors.Forbidden, "unauthorized-user", "auth role %v does not match any criteria in %v")
+	ErrUnauthorizedUser    = errors.Fmt(errors.Forbidden, "unauthorized-user", "action %q is not allowed for auth role %v")
+	ErrOutOfScope          = errors.Fmt(errors.Forbidden, "out-of-scope", "action %q is not within the scopes of the actor")
 
 	ErrUserNotFound    = errors.New(errors.NotFound, "user-not-found", "user not found", nil)
 	ErrSiapeTaken      = errors.New(errors.NotFound, "siape-in-use", "siape is already in use", nil)
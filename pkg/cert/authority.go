@@ -0,0 +1,188 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+package cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+var ErrBadPEM = errors.New("cert: no PEM block of the expected type")
+
+// Authority is a certificate authority, able to issue
+// certificates signed by itself. It is meant for development,
+// browsers trust its certificates only if it is installed as a
+// root authority.
+type Authority struct {
+	Certificate *x509.Certificate
+	Key         crypto.Signer
+}
+
+// NewAuthority generates a self-signed certificate authority,
+// valid for the given duration.
+func NewAuthority(name string, validity time.Duration) (*Authority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("cert: %w", err)
+	}
+
+	tmpl, err := template(name, validity)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.MaxPathLenZero = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("cert: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("cert: %w", err)
+	}
+
+	return &Authority{Certificate: cert, Key: key}, nil
+}
+
+// ParseAuthority parses a certificate authority from its PEM
+// encoded certificate and key, as given by [EncodeCertificate]
+// and [EncodeKey].
+func ParseAuthority(certPEM, keyPEM []byte) (*Authority, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, ErrBadPEM
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cert: %w", err)
+	}
+
+	block, _ = pem.Decode(keyPEM)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, ErrBadPEM
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cert: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok || !cert.IsCA {
+		return nil, errors.New("cert: not a certificate authority")
+	}
+
+	return &Authority{Certificate: cert, Key: signer}, nil
+}
+
+// IssueServer issues a server certificate for the given hosts,
+// either names or IP addresses. The first host is its common
+// name.
+func (a *Authority) IssueServer(hosts []string, validity time.Duration) (*x509.Certificate, crypto.Signer, error) {
+	if len(hosts) == 0 {
+		return nil, nil, errors.New("cert: no hosts to issue a certificate for")
+	}
+
+	tmpl, err := template(hosts[0], validity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, host)
+		}
+	}
+
+	return a.issue(tmpl)
+}
+
+// IssueClient issues a client certificate, identified by its
+// common name.
+func (a *Authority) IssueClient(name string, validity time.Duration) (*x509.Certificate, crypto.Signer, error) {
+	tmpl, err := template(name, validity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	return a.issue(tmpl)
+}
+
+func (a *Authority) issue(tmpl *x509.Certificate) (*x509.Certificate, crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cert: %w", err)
+	}
+
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, a.Certificate, key.Public(), a.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cert: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cert: %w", err)
+	}
+
+	return cert, key, nil
+}
+
+// EncodeCertificate encodes the certificate in PEM.
+func EncodeCertificate(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+// EncodeKey encodes the private key in PEM, as PKCS #8.
+func EncodeKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("cert: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func template(name string, validity time.Duration) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("cert: %w", err)
+	}
+
+	// backdated, so that clocks slightly behind accept it
+	now := time.Now()
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"Almodon"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+	}, nil
+}
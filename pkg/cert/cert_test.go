@@ -0,0 +1,129 @@
+package cert_test
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/pkg/cert"
+)
+
+func TestAuthority(t *testing.T) {
+	ca, err := NewAuthority("Almodon Development CA", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := EncodeKey(ca.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca, err = ParseAuthority(EncodeCertificate(ca.Certificate), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Certificate)
+
+	server, _, err := ca.IssueServer([]string{"localhost", "127.0.0.1"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, host := range []string{"localhost", "127.0.0.1"} {
+		_, err := server.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		if err != nil {
+			t.Errorf("%s: %v", host, err)
+		}
+	}
+
+	if _, err := server.Verify(x509.VerifyOptions{DNSName: "ufvjm.edu.br", Roots: roots}); err == nil {
+		t.Errorf("server certificate should not be valid for hosts it was not issued for")
+	}
+
+	client, _, err := ca.IssueClient("operator", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}
+	if _, err := client.Verify(opts); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := server.Verify(opts); err == nil {
+		t.Errorf("server certificate should not be valid for client authentication")
+	}
+}
+
+func TestReloader(t *testing.T) {
+	ca, err := NewAuthority("Almodon Development CA", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	issue := func(host string) {
+		cert, key, err := ca.IssueServer([]string{host}, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keyPEM, err := EncodeKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(certFile, EncodeCertificate(cert), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	current := func(r *Reloader) string {
+		cert, err := r.GetCertificate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return cert.Leaf.Subject.CommonName
+	}
+
+	issue("first.localhost")
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := current(r); name != "first.localhost" {
+		t.Fatalf("expected first.localhost, got %s", name)
+	}
+
+	issue("second.localhost")
+	time.Sleep(CheckInterval)
+
+	if name := current(r); name != "second.localhost" {
+		t.Errorf("expected the changed certificate to be reloaded, got %s", name)
+	}
+
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Reload(); err == nil {
+		t.Errorf("expected a broken key to fail to load")
+	}
+
+	if name := current(r); name != "second.localhost" {
+		t.Errorf("expected the previous certificate to be kept, got %s", name)
+	}
+}
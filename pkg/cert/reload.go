@@ -0,0 +1,135 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package cert loads TLS certificates, reloading them as they
+// change on disk, and issues certificates from a local
+// certificate authority, for development.
+package cert
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// CheckInterval is the minimum interval between two checks of
+// the files of a [Reloader] for changes.
+const CheckInterval = time.Second
+
+// Reloader serves a certificate and its key from files, which
+// are checked for changes, at most every [CheckInterval], as
+// handshakes happen. A certificate that fails to load does not
+// replace the one being served, so that files may be replaced
+// one at a time.
+type Reloader struct {
+	// ErrorLog logs the failures to reload the certificate. If
+	// nil, they are logged by the log package.
+	ErrorLog *log.Logger
+
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	stamp   stamp
+	checked time.Time
+}
+
+// stamp identifies a version of the files, by their
+// modification times and sizes.
+type stamp [2]struct {
+	mod  time.Time
+	size int64
+}
+
+// NewReloader loads the certificate and key from the PEM files
+// and returns a reloader serving them.
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Reload loads the certificate and key, regardless of whether
+// they have changed. If they fail to load, the previous
+// certificate is kept.
+func (r *Reloader) Reload() error {
+	defer r.mu.Unlock()
+	r.mu.Lock()
+
+	return r.load()
+}
+
+// GetCertificate returns the current certificate, reloading it
+// first if its files have changed. It is meant to be used as
+// [tls.Config.GetCertificate].
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	defer r.mu.Unlock()
+	r.mu.Lock()
+
+	if now := time.Now(); now.Sub(r.checked) >= CheckInterval {
+		r.checked = now
+
+		if s, err := r.stat(); err == nil && s != r.stamp {
+			if err := r.load(); err != nil {
+				r.logf("cert: keeping the previous certificate: %v", err)
+			}
+		}
+	}
+
+	return r.cert, nil
+}
+
+func (r *Reloader) load() error {
+	s, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("cert: %w", err)
+	}
+
+	r.cert = &cert
+	r.stamp = s
+	return nil
+}
+
+func (r *Reloader) stat() (stamp, error) {
+	var s stamp
+
+	for i, name := range [...]string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return stamp{}, fmt.Errorf("cert: %w", err)
+		}
+
+		s[i].mod = info.ModTime()
+		s[i].size = info.Size()
+	}
+
+	return s, nil
+}
+
+func (r *Reloader) logf(format string, v ...any) {
+	if r.ErrorLog != nil {
+		r.ErrorLog.Printf(format, v...)
+		return
+	}
+
+	log.Printf(format, v...)
+}
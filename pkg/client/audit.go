@@ -0,0 +1,23 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Audit is the audit records service, as seen by the actor of the
+// client.
+type Audit struct{ c *Client }
+
+func (s *Audit) List(ctx context.Context, req ListAuditRequest) (AuditListResponse, error) {
+	var res AuditListResponse
+	err := s.c.do(ctx, http.MethodGet, "audit/", values(req), nil, &res)
+	return res, err
+}
@@ -0,0 +1,320 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package client implements a typed client of the v1 API of
+// Almodon.
+//
+// The client keeps the session cookie set on logging in, and sends
+// the CSRF token the API requires along with it, so that a single
+// [Client] acts as a single logged in user. Alternatively, it may act
+// through a personal access token, see [Client.WithToken].
+//
+// Errors returned by the API are decoded into [*errors.Error], so
+// that they can be told apart by their kind and title, as on the
+// server.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/alan-b-lima/almodon/pkg/errors"
+)
+
+const (
+	_CSRFCookie = "csrf"
+	_CSRFHeader = "X-CSRF-Token"
+)
+
+// Client is a client of the API, its services are grouped by
+// resource.
+type Client struct {
+	base  *url.URL
+	http  *http.Client
+	token string
+
+	Users    *Users
+	Tokens   *Tokens
+	Products *Products
+	Audit    *Audit
+}
+
+// New creates a client of the API hosted at base, as
+// "https://almodon.example", over the given HTTP client, or over
+// a default one, if nil. A client with no cookie jar is copied and
+// given one, as sessions are kept in cookies.
+func New(base string, hc *http.Client) (*Client, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("client: bad base url: %w", err)
+	}
+	u = u.JoinPath("/api/v1/")
+
+	if hc == nil {
+		hc = &http.Client{}
+	}
+
+	if hc.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, fmt.Errorf("client: %w", err)
+		}
+
+		copy := *hc
+		copy.Jar = jar
+		hc = &copy
+	}
+
+	return with(&Client{base: u, http: hc}), nil
+}
+
+// WithToken returns a copy of the client that acts through the given
+// personal access token, rather than through its session.
+func (c *Client) WithToken(token string) *Client {
+	return with(&Client{base: c.base, http: c.http, token: token})
+}
+
+func with(c *Client) *Client {
+	c.Users = &Users{c}
+	c.Tokens = &Tokens{c}
+	c.Products = &Products{c}
+	c.Audit = &Audit{c}
+	return c
+}
+
+// do performs a request to the path, relative to the base of the API,
+// encoding the body, if not nil, and decoding the response into res,
+// if not nil. Responses with statuses other than 2xx are decoded into
+// errors.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, res any) error {
+	return c.doWith(ctx, method, path, query, nil, body, res)
+}
+
+// doWith performs a request as [Client.do] does, along with the given
+// headers. Fields of res tagged with "header" take the header of that
+// name of the response.
+func (c *Client) doWith(ctx context.Context, method, path string, query url.Values, header http.Header, body, res any) error {
+	u := c.base.JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	var r io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("client: %w", err)
+		}
+
+		r = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return fmt.Errorf("client: %w", err)
+	}
+
+	for name, vals := range header {
+		req.Header[name] = vals
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if !safe(method) {
+		token, err := c.csrf(ctx)
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set(_CSRFHeader, token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("client: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return fmt.Errorf("client: bad response body: %w", err)
+	}
+
+	return fill(res, resp.Header)
+}
+
+// csrf returns the CSRF token kept in the cookie jar, issuing one if
+// there is none. Tokens, once issued, are reused by the API.
+func (c *Client) csrf(ctx context.Context) (string, error) {
+	for _, cookie := range c.http.Jar.Cookies(c.base) {
+		if cookie.Name == _CSRFCookie {
+			return cookie.Value, nil
+		}
+	}
+
+	var res struct {
+		Token string `json:"token"`
+	}
+	if err := c.do(ctx, http.MethodGet, "csrf/", nil, nil, &res); err != nil {
+		return "", err
+	}
+
+	return res.Token, nil
+}
+
+func safe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	return false
+}
+
+// decodeError decodes the body of an error response, responses that
+// are not errors of the API are reported by their status.
+func decodeError(resp *http.Response) error {
+	var err errors.Error
+	if json.NewDecoder(resp.Body).Decode(&err) == nil && err.Kind != 0 {
+		return &err
+	}
+
+	return &errors.Error{
+		Kind:    kinds[resp.StatusCode],
+		Title:   "unexpected-response",
+		Message: "unexpected response: " + resp.Status,
+	}
+}
+
+var kinds = map[int]errors.Kind{
+	http.StatusBadRequest:            errors.InvalidInput,
+	http.StatusUnauthorized:          errors.Unauthorized,
+	http.StatusForbidden:             errors.Forbidden,
+	http.StatusNotAcceptable:         errors.PreconditionFailed,
+	http.StatusPreconditionFailed:    errors.PreconditionFailed,
+	http.StatusPreconditionRequired:  errors.PreconditionRequired,
+	http.StatusUnsupportedMediaType:  errors.PreconditionFailed,
+	http.StatusNotFound:              errors.NotFound,
+	http.StatusConflict:              errors.Conflict,
+	http.StatusRequestTimeout:        errors.Timeout,
+	http.StatusInternalServerError:   errors.Internal,
+	http.StatusServiceUnavailable:    errors.Unavailable,
+	http.StatusBadGateway:            errors.BadGateway,
+	http.StatusMethodNotAllowed:      errors.NotFound,
+	http.StatusRequestEntityTooLarge: errors.InvalidInput,
+}
+
+// values encodes the fields of a struct tagged with "query" as query
+// parameters, zero fields are left out, so that the API defaults
+// them. Slices are encoded by repeating the parameter.
+func values(v any) url.Values {
+	q := make(url.Values)
+
+	rv := reflect.ValueOf(v)
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		name, _, _ := strings.Cut(rt.Field(i).Tag.Get("query"), ",")
+		if name == "" || rv.Field(i).IsZero() {
+			continue
+		}
+
+		field := rv.Field(i)
+		if field.Kind() == reflect.Slice {
+			for j := range field.Len() {
+				q.Add(name, value(field.Index(j)))
+			}
+			continue
+		}
+
+		q.Set(name, value(field))
+	}
+
+	return q
+}
+
+// headers encodes the fields of a struct tagged with "header" as
+// headers, zero fields are left out.
+func headers(v any) http.Header {
+	h := make(http.Header)
+
+	rv := reflect.ValueOf(v)
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		name, _, _ := strings.Cut(rt.Field(i).Tag.Get("header"), ",")
+		if name == "" || rv.Field(i).IsZero() {
+			continue
+		}
+
+		h.Set(name, value(rv.Field(i)))
+	}
+
+	return h
+}
+
+// fill decodes the headers into the fields of the struct pointed to by
+// res tagged with "header", if it is one.
+func fill(res any, h http.Header) error {
+	rv := reflect.ValueOf(res).Elem()
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		name, _, _ := strings.Cut(rt.Field(i).Tag.Get("header"), ",")
+		val := h.Get(name)
+		if name == "" || val == "" {
+			continue
+		}
+
+		field := rv.Field(i).Addr().Interface()
+		if u, ok := field.(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(val)); err != nil {
+				return fmt.Errorf("client: bad %s header: %w", name, err)
+			}
+		} else if s, ok := field.(*string); ok {
+			*s = val
+		}
+	}
+
+	return nil
+}
+
+func value(v reflect.Value) string {
+	switch val := v.Interface().(type) {
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case encoding.TextMarshaler:
+		if buf, err := val.MarshalText(); err == nil {
+			return string(buf)
+		}
+	}
+
+	return fmt.Sprint(v.Interface())
+}
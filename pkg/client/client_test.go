@@ -0,0 +1,290 @@
+package client_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "github.com/alan-b-lima/almodon/internal/api/v1"
+	"github.com/alan-b-lima/almodon/internal/auth"
+	"github.com/alan-b-lima/almodon/internal/config"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/storage"
+	. "github.com/alan-b-lima/almodon/pkg/client"
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	"github.com/alan-b-lima/almodon/pkg/metrics"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+	"github.com/alan-b-lima/almodon/pkg/totp"
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+const (
+	_SIAPE      = 1000001
+	_AdminSIAPE = 1000011
+	_UserSIAPE  = 1000021
+	_Password   = "correct horse battery"
+)
+
+// server serves the API over memory, with a single chief, an admin
+// and a user.
+func server(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Cookies.Secure = false
+
+	repos, err := storage.Open(cfg.Storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repos.Close() })
+
+	if _, err := user.Create(repos.Users, _SIAPE, "Chief", "chief@example.com", _Password, auth.Chief); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := user.Create(repos.Users, _AdminSIAPE, "Storeroom", "storeroom@example.com", _Password, auth.Admin); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := user.Create(repos.Users, _UserSIAPE, "Dentist", "dentist@example.com", _Password, auth.User); err != nil {
+		t.Fatal(err)
+	}
+
+	handler, err := api.New(cfg, repos, metrics.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { handler.Close() })
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestErrors(t *testing.T) {
+	srv := server(t)
+	ctx := context.Background()
+
+	c, err := New(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Users.Authenticate(ctx, AuthRequest{SIAPE: _SIAPE, Password: "incorrect password"})
+	if err, ok := errors.AsType[*errors.Error](err); !ok || err.Kind != errors.Unauthorized || err.Title != "incorrect-password" {
+		t.Errorf("expected an incorrect password error, got %v", err)
+	}
+
+	_, err = c.Users.List(ctx, ListUsersRequest{})
+	if err, ok := errors.AsType[*errors.Error](err); !ok || err.Kind != errors.Unauthorized {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestSession(t *testing.T) {
+	srv := server(t)
+	ctx := context.Background()
+
+	c, err := New(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.Users.Authenticate(ctx, AuthRequest{SIAPE: _SIAPE, Password: _Password})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	me, err := c.Users.Me(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if me.UUID != res.User || me.SIAPE != _SIAPE {
+		t.Fatalf("expected to be %v, got %+v", res.User, me)
+	}
+
+	// chiefs need a second factor to act as such
+	enrollment, err := c.Users.EnrollTwoFactor(ctx, EnrollTwoFactorRequest{UUID: me.UUID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes, err := confirm(ctx, c, me.UUID, enrollment.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = New(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = c.Users.Authenticate(ctx, AuthRequest{SIAPE: _SIAPE, Password: _Password})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.TwoFactorRequired {
+		t.Fatal("expected a second factor to be required")
+	}
+
+	if _, err := c.Users.AuthenticateSecondFactor(ctx, SecondFactorRequest{Code: codes[0]}); err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := c.Users.Create(ctx, CreateUserRequest{SIAPE: 1000002, Name: "Admin", Email: "admin@example.com", Password: _Password, Role: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := c.Users.Get(ctx, GetUserRequest{UUID: admin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Users.Patch(ctx, PatchUserRequest{UUID: admin, Name: opt.Some("Admin")})
+	if err, ok := errors.AsType[*errors.Error](err); !ok || err.Kind != errors.PreconditionRequired {
+		t.Errorf("expected patches to require a version, got %v", err)
+	}
+
+	if err := c.Users.Patch(ctx, PatchUserRequest{UUID: admin, IfMatch: Match(created.Version), Name: opt.Some("Administrator")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a patch based on the version since changed is refused
+	err = c.Users.Patch(ctx, PatchUserRequest{UUID: admin, IfMatch: Match(created.Version), Name: opt.Some("Admin")})
+	if err, ok := errors.AsType[*errors.Error](err); !ok || err.Kind != errors.PreconditionFailed {
+		t.Errorf("expected a stale patch to fail, got %v", err)
+	}
+
+	if err := c.Users.UpdateRole(ctx, UpdateRoleRequest{UUID: admin, IfMatch: Match(created.Version + 1), Role: "user"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Users.GetBySIAPE(ctx, GetUserBySIAPERequest{SIAPE: 1000002})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.UUID != admin || got.Name != "Administrator" || got.Role != auth.User.String() || got.Version != created.Version+2 {
+		t.Errorf("expected the patched user, got %+v", got)
+	}
+
+	list, err := c.Users.List(ctx, ListUsersRequest{Sort: []string{"siape"}, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Length != 3 || list.TotalRecords != 4 || list.Next == "" || list.Records[0].UUID != me.UUID || list.Records[1].UUID != admin {
+		t.Errorf("expected 3 of 4 users, by SIAPE, got %+v", list)
+	}
+
+	rest, err := c.Users.List(ctx, ListUsersRequest{Sort: []string{"siape"}, Cursor: list.Next, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rest.Length != 1 || rest.Next != "" || rest.Records[0].SIAPE != _UserSIAPE {
+		t.Errorf("expected the user to be last, got %+v", rest)
+	}
+
+	found, err := c.Users.List(ctx, ListUsersRequest{Name: "ADMIN", Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Length != 1 || found.Records[0].UUID != admin {
+		t.Errorf("expected the patched user to be found, got %+v", found)
+	}
+
+	token, err := c.Tokens.Create(ctx, CreateTokenRequest{Name: "tool", Scopes: []string{"users:get"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := c.WithToken(token.Token)
+	if _, err := tc.Users.Get(ctx, GetUserRequest{UUID: me.UUID}); err != nil {
+		t.Errorf("expected the token to get its user, got %v", err)
+	}
+
+	if err := c.Users.Delete(ctx, DeleteUserRequest{UUID: admin, IfMatch: Match(got.Version)}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Users.Get(ctx, GetUserRequest{UUID: admin})
+	if err, ok := errors.AsType[*errors.Error](err); !ok || err.Kind != errors.NotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestProducts(t *testing.T) {
+	srv := server(t)
+	ctx := context.Background()
+
+	// admins, unlike chiefs, act as such with a single factor
+	c, err := New(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Users.Authenticate(ctx, AuthRequest{SIAPE: _AdminSIAPE, Password: _Password}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, req := range []CreateProductRequest{
+		{Name: "Anestésico local", Description: "Lidocaína 2%", CATMAT: "270911", Unit: "tubete"},
+		{Name: "Luva de procedimento", Description: "Látex", CATMAT: "269944", Unit: "caixa"},
+		{Name: "Resina composta", CATMAT: "445566", Unit: "seringa"},
+	} {
+		if _, err := c.Products.Create(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := c.Products.Search(ctx, SearchProductsRequest{Query: "anestesico"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Length != 1 || found.Records[0].CATMAT != "270911" {
+		t.Errorf("expected the anesthetic, got %+v", found)
+	}
+
+	completed, err := c.Products.Complete(ctx, CompleteProductRequest{Query: "LUV"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(completed.Suggestions) != 1 || completed.Suggestions[0].Name != "Luva de procedimento" {
+		t.Errorf("expected the glove to be suggested, got %+v", completed)
+	}
+
+	_, err = c.Products.Search(ctx, SearchProductsRequest{})
+	if err, ok := errors.AsType[*errors.Error](err); !ok || err.Kind != errors.InvalidInput {
+		t.Errorf("expected the query to be required, got %v", err)
+	}
+
+	uc, err := New(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uc.Users.Authenticate(ctx, AuthRequest{SIAPE: _UserSIAPE, Password: _Password}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uc.Products.Complete(ctx, CompleteProductRequest{Query: "resina"}); err != nil {
+		t.Errorf("expected users to search products, got %v", err)
+	}
+
+	_, err = uc.Products.Create(ctx, CreateProductRequest{Name: "Gaze", Unit: "pacote"})
+	if err, ok := errors.AsType[*errors.Error](err); !ok || err.Kind != errors.Forbidden {
+		t.Errorf("expected users not to create products, got %v", err)
+	}
+}
+
+func confirm(ctx context.Context, c *Client, user uuid.UUID, secret string) ([]string, error) {
+	key, err := totp.DecodeSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.Users.ConfirmTwoFactor(ctx, ConfirmTwoFactorRequest{UUID: user, Code: totp.Code(key, time.Now())})
+	return res.RecoveryCodes, err
+}
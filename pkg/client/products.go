@@ -0,0 +1,59 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// Products is the products service, as seen by the actor of the
+// client.
+type Products struct{ c *Client }
+
+func (s *Products) List(ctx context.Context, req ListProductsRequest) (ProductListResponse, error) {
+	var res ProductListResponse
+	err := s.c.do(ctx, http.MethodGet, "products/", values(req), nil, &res)
+	return res, err
+}
+
+// Search searches products, ranked by how well they match the query.
+func (s *Products) Search(ctx context.Context, req SearchProductsRequest) (ProductSearchResponse, error) {
+	var res ProductSearchResponse
+	err := s.c.do(ctx, http.MethodGet, "products/search/", values(req), nil, &res)
+	return res, err
+}
+
+// Complete suggests products matching the query as it is typed.
+func (s *Products) Complete(ctx context.Context, req CompleteProductRequest) (ProductCompleteResponse, error) {
+	var res ProductCompleteResponse
+	err := s.c.do(ctx, http.MethodGet, "products/autocomplete/", values(req), nil, &res)
+	return res, err
+}
+
+func (s *Products) Get(ctx context.Context, req GetProductRequest) (ProductResponse, error) {
+	var res ProductResponse
+	err := s.c.do(ctx, http.MethodGet, "products/"+req.UUID.String(), nil, nil, &res)
+	return res, err
+}
+
+func (s *Products) Create(ctx context.Context, req CreateProductRequest) (uuid.UUID, error) {
+	var res uuid.UUID
+	err := s.c.do(ctx, http.MethodPost, "products/", nil, req, &res)
+	return res, err
+}
+
+func (s *Products) Patch(ctx context.Context, req PatchProductRequest) error {
+	return s.c.doWith(ctx, http.MethodPatch, "products/"+req.UUID.String(), nil, headers(req), req, nil)
+}
+
+func (s *Products) Delete(ctx context.Context, req DeleteProductRequest) error {
+	return s.c.doWith(ctx, http.MethodDelete, "products/"+req.UUID.String(), nil, headers(req), nil, nil)
+}
@@ -0,0 +1,33 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Tokens is the personal access tokens service, as seen by the actor
+// of the client.
+type Tokens struct{ c *Client }
+
+func (s *Tokens) List(ctx context.Context, req ListTokensRequest) (TokenListResponse, error) {
+	var res TokenListResponse
+	err := s.c.do(ctx, http.MethodGet, "tokens/", values(req), nil, &res)
+	return res, err
+}
+
+func (s *Tokens) Create(ctx context.Context, req CreateTokenRequest) (CreateTokenResponse, error) {
+	var res CreateTokenResponse
+	err := s.c.do(ctx, http.MethodPost, "tokens/", nil, req, &res)
+	return res, err
+}
+
+func (s *Tokens) Delete(ctx context.Context, req DeleteTokenRequest) error {
+	return s.c.do(ctx, http.MethodDelete, "tokens/"+req.UUID.String(), nil, nil, nil)
+}
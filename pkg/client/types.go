@@ -0,0 +1,92 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"github.com/alan-b-lima/almodon/internal/domain/audit"
+	"github.com/alan-b-lima/almodon/internal/domain/product"
+	"github.com/alan-b-lima/almodon/internal/domain/token"
+	"github.com/alan-b-lima/almodon/internal/domain/user"
+	"github.com/alan-b-lima/almodon/internal/support/entity"
+)
+
+// The types exchanged with the API are those of its services, aliased
+// here so that they can be named outside of this module. Fields
+// tagged with `json:"-"`, as identifiers, are sent in the path, or in
+// the headers, if tagged with "header".
+
+type (
+	// Version is the version of a user or product, as taken from the
+	// ETag of the response getting it.
+	Version = entity.Version
+
+	// Precondition conditions a change to the version it was based
+	// on, it is required by patches and deletions.
+	Precondition = entity.Precondition
+)
+
+// Match is the precondition of the version being one of those given.
+func Match(versions ...Version) Precondition {
+	return entity.Match(versions...)
+}
+
+type (
+	ListUsersRequest        = user.ListRequest
+	GetUserRequest          = user.GetRequest
+	GetUserBySIAPERequest   = user.GetBySIAPERequest
+	CreateUserRequest       = user.CreateRequest
+	PatchUserRequest        = user.PatchRequest
+	UpdatePasswordRequest   = user.UpdatePasswordRequest
+	UpdateRoleRequest       = user.UpdateRoleRequest
+	DeleteUserRequest       = user.DeleteRequest
+	AuthRequest             = user.AuthRequest
+	SecondFactorRequest     = user.SecondFactorRequest
+	EnrollTwoFactorRequest  = user.EnrollTwoFactorRequest
+	ConfirmTwoFactorRequest = user.ConfirmTwoFactorRequest
+	DisableTwoFactorRequest = user.DisableTwoFactorRequest
+
+	UserListResponse         = user.ListResponse
+	UserResponse             = user.Response
+	AuthResponse             = user.AuthResponse
+	EnrollTwoFactorResponse  = user.EnrollTwoFactorResponse
+	ConfirmTwoFactorResponse = user.ConfirmTwoFactorResponse
+)
+
+type (
+	ListTokensRequest  = token.ListRequest
+	CreateTokenRequest = token.CreateRequest
+	DeleteTokenRequest = token.DeleteRequest
+
+	TokenListResponse   = token.ListResponse
+	TokenResponse       = token.Response
+	CreateTokenResponse = token.CreateResponse
+)
+
+type (
+	ListProductsRequest    = product.ListRequest
+	SearchProductsRequest  = product.SearchRequest
+	CompleteProductRequest = product.CompleteRequest
+	GetProductRequest      = product.GetRequest
+	CreateProductRequest   = product.CreateRequest
+	PatchProductRequest    = product.PatchRequest
+	DeleteProductRequest   = product.DeleteRequest
+
+	ProductListResponse       = product.ListResponse
+	ProductSearchResponse     = product.SearchResponse
+	ProductCompleteResponse   = product.CompleteResponse
+	ProductResponse           = product.Response
+	ProductSuggestionResponse = product.SuggestionResponse
+)
+
+type (
+	ListAuditRequest = audit.ListRequest
+
+	AuditListResponse   = audit.ListResponse
+	AuditResponse       = audit.Response
+	AuditChangeResponse = audit.ChangeResponse
+)
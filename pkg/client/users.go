@@ -0,0 +1,99 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/alan-b-lima/almodon/pkg/uuid"
+)
+
+// Users is the users service, as seen by the actor of the client.
+type Users struct{ c *Client }
+
+func (s *Users) List(ctx context.Context, req ListUsersRequest) (UserListResponse, error) {
+	var res UserListResponse
+	err := s.c.do(ctx, http.MethodGet, "users/", values(req), nil, &res)
+	return res, err
+}
+
+func (s *Users) Get(ctx context.Context, req GetUserRequest) (UserResponse, error) {
+	var res UserResponse
+	err := s.c.do(ctx, http.MethodGet, "users/"+req.UUID.String(), nil, nil, &res)
+	return res, err
+}
+
+func (s *Users) GetBySIAPE(ctx context.Context, req GetUserBySIAPERequest) (UserResponse, error) {
+	var res UserResponse
+	err := s.c.do(ctx, http.MethodGet, "users/siape/"+strconv.Itoa(req.SIAPE), nil, nil, &res)
+	return res, err
+}
+
+// Me gets the user the client acts as.
+func (s *Users) Me(ctx context.Context) (UserResponse, error) {
+	var res UserResponse
+	err := s.c.do(ctx, http.MethodGet, "users/me/", nil, nil, &res)
+	return res, err
+}
+
+func (s *Users) Create(ctx context.Context, req CreateUserRequest) (uuid.UUID, error) {
+	var res uuid.UUID
+	err := s.c.do(ctx, http.MethodPost, "users/", nil, req, &res)
+	return res, err
+}
+
+func (s *Users) Patch(ctx context.Context, req PatchUserRequest) error {
+	return s.c.doWith(ctx, http.MethodPatch, "users/"+req.UUID.String(), nil, headers(req), req, nil)
+}
+
+func (s *Users) UpdatePassword(ctx context.Context, req UpdatePasswordRequest) error {
+	return s.c.doWith(ctx, http.MethodPut, "users/"+req.UUID.String()+"/password/", nil, headers(req), req, nil)
+}
+
+func (s *Users) UpdateRole(ctx context.Context, req UpdateRoleRequest) error {
+	return s.c.doWith(ctx, http.MethodPut, "users/"+req.UUID.String()+"/role/", nil, headers(req), req, nil)
+}
+
+func (s *Users) Delete(ctx context.Context, req DeleteUserRequest) error {
+	return s.c.doWith(ctx, http.MethodDelete, "users/"+req.UUID.String(), nil, headers(req), nil, nil)
+}
+
+func (s *Users) EnrollTwoFactor(ctx context.Context, req EnrollTwoFactorRequest) (EnrollTwoFactorResponse, error) {
+	var res EnrollTwoFactorResponse
+	err := s.c.do(ctx, http.MethodPost, "users/"+req.UUID.String()+"/2fa/", nil, nil, &res)
+	return res, err
+}
+
+func (s *Users) ConfirmTwoFactor(ctx context.Context, req ConfirmTwoFactorRequest) (ConfirmTwoFactorResponse, error) {
+	var res ConfirmTwoFactorResponse
+	err := s.c.do(ctx, http.MethodPost, "users/"+req.UUID.String()+"/2fa/confirm/", nil, req, &res)
+	return res, err
+}
+
+func (s *Users) DisableTwoFactor(ctx context.Context, req DisableTwoFactorRequest) error {
+	return s.c.do(ctx, http.MethodDelete, "users/"+req.UUID.String()+"/2fa/", nil, req, nil)
+}
+
+// Authenticate logs the client in, keeping its session. If a second
+// factor is required, the session is pending until
+// [Users.AuthenticateSecondFactor] completes it.
+func (s *Users) Authenticate(ctx context.Context, req AuthRequest) (AuthResponse, error) {
+	var res AuthResponse
+	err := s.c.do(ctx, http.MethodPost, "users/auth/", nil, req, &res)
+	return res, err
+}
+
+// AuthenticateSecondFactor completes the pending session of the
+// client, the session of the request is ignored.
+func (s *Users) AuthenticateSecondFactor(ctx context.Context, req SecondFactorRequest) (AuthResponse, error) {
+	var res AuthResponse
+	err := s.c.do(ctx, http.MethodPost, "users/auth/2fa/", nil, req, &res)
+	return res, err
+}
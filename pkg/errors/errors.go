@@ -89,7 +89,11 @@ func (err *Error) UnmarshalJSON(buf []byte) error {
 		Kind:    efj.Kind,
 		Title:   efj.Title,
 		Message: efj.Message,
-		Cause:   &efj.Cause,
+	}
+
+	// an absent cause must be left nil, rather than wrapping nothing
+	if efj.Cause.error != nil {
+		err.Cause = &efj.Cause
 	}
 	return nil
 }
@@ -132,6 +136,12 @@ const (
 	// 415 Unsupported Media Type.
 	PreconditionFailed
 
+	// PreconditionRequired denotes that a precondition the request must
+	// carry (e.g. an expected resource version) is missing.
+	//
+	// Rough HTTP equivalent: 428 Precondition Required.
+	PreconditionRequired
+
 	// NotFound indicates that the requested resource does not exist. Use this
 	// when a lookup by identifier yields no result.
 	//
@@ -177,13 +187,14 @@ const (
 )
 
 var kindStrings = map[Kind]string{
-	InvalidInput:       "invalid input",
-	Unauthorized:       "unauthorized",
-	Forbidden:          "forbidden",
-	PreconditionFailed: "precondition failed",
-	NotFound:           "not found",
-	Conflict:           "conflict",
-	Timeout:            "timeout",
+	InvalidInput:         "invalid input",
+	Unauthorized:         "unauthorized",
+	Forbidden:            "forbidden",
+	PreconditionFailed:   "precondition failed",
+	PreconditionRequired: "precondition required",
+	NotFound:             "not found",
+	Conflict:             "conflict",
+	Timeout:              "timeout",
 
 	Internal:    "internal error",
 	Unavailable: "unavailable",
@@ -192,6 +203,19 @@ var kindStrings = map[Kind]string{
 
 var stringKinds = invert(kindStrings)
 
+// Kinds returns every kind of error, client kinds first, then
+// internal ones.
+func Kinds() []Kind {
+	kinds := make([]Kind, 0, len(kindStrings))
+	for k := client_errors_start + 1; k < internal_errors_end; k++ {
+		if _, ok := kindStrings[k]; ok {
+			kinds = append(kinds, k)
+		}
+	}
+
+	return kinds
+}
+
 // IsClient identifies whether the kind falls under the
 // client category.
 func (k Kind) IsClient() bool {
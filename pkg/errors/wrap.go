@@ -70,8 +70,8 @@ func (e wrapped) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON implements the [json.Unmarshaler] interface on the
 // type. It tries to unmarshal the error into an [Error], if that
-// fails, it tries to unmarshal it into a string, if that also fails,
-// it returns an error.
+// fails, it tries to unmarshal it into a string, and then into many
+// errors, as joined, if that also fails, it returns an error.
 func (e *wrapped) UnmarshalJSON(buf []byte) error {
 	var err_ Error
 	if err := json.Unmarshal(buf, &err_); err == nil {
@@ -85,5 +85,16 @@ func (e *wrapped) UnmarshalJSON(buf []byte) error {
 		return nil
 	}
 
+	var errs_ []wrapped
+	if err := json.Unmarshal(buf, &errs_); err == nil {
+		merr := Multi{errs: make([]error, 0, len(errs_))}
+		for i := range errs_ {
+			merr.errs = append(merr.errs, &errs_[i])
+		}
+
+		e.error = &merr
+		return nil
+	}
+
 	return errUnmarshal
 }
@@ -0,0 +1,336 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alan-b-lima/almodon/pkg/ldap/internal/ber"
+)
+
+// FilterOp is the operation of a search filter.
+type FilterOp uint8
+
+const (
+	And FilterOp = iota
+	Or
+	Not
+	Equal
+	GreaterOrEqual
+	LessOrEqual
+	Present
+)
+
+// Filter is a search filter, as defined in RFC 4511 section 4.5.1.7.
+// Substring and approximate matches, as well as extensible matches,
+// are not supported.
+type Filter struct {
+	Op       FilterOp
+	Attr     string
+	Value    string
+	Children []Filter
+}
+
+var ErrBadFilter = errors.New("ldap: malformed filter")
+
+// filter choice tags, see RFC 4511 section 4.5.1
+var filterTags = map[FilterOp]int{
+	And:            0,
+	Or:             1,
+	Not:            2,
+	Equal:          3,
+	GreaterOrEqual: 5,
+	LessOrEqual:    6,
+	Present:        7,
+}
+
+var tagFilters = func() map[int]FilterOp {
+	m := make(map[int]FilterOp, len(filterTags))
+	for op, tag := range filterTags {
+		m[tag] = op
+	}
+	return m
+}()
+
+// ParseFilter parses the string representation of a filter, as
+// defined in RFC 4515, such as "(&(objectClass=person)(uid=jdoe))".
+func ParseFilter(str string) (Filter, error) {
+	f, rest, err := parseFilter(str)
+	if err != nil {
+		return Filter{}, err
+	}
+
+	if rest != "" {
+		return Filter{}, ErrBadFilter
+	}
+
+	return f, nil
+}
+
+// EscapeFilter escapes a value to be safely interpolated into the
+// string representation of a filter.
+func EscapeFilter(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// String returns the string representation of the filter.
+func (f Filter) String() string {
+	var b strings.Builder
+	f.write(&b)
+	return b.String()
+}
+
+// MarshalBinary encodes the filter in BER.
+func (f Filter) MarshalBinary() ([]byte, error) {
+	p, err := f.packet()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a filter encoded in BER.
+func (f *Filter) UnmarshalBinary(buf []byte) error {
+	p, err := ber.Decode(buf)
+	if err != nil {
+		return err
+	}
+
+	res, err := filterFromPacket(p)
+	if err != nil {
+		return err
+	}
+
+	*f = res
+	return nil
+}
+
+func (f Filter) packet() (*ber.Packet, error) {
+	tag, in := filterTags[f.Op]
+	if !in {
+		return nil, ErrBadFilter
+	}
+
+	switch f.Op {
+	case And, Or:
+		children := make([]*ber.Packet, len(f.Children))
+		for i, child := range f.Children {
+			p, err := child.packet()
+			if err != nil {
+				return nil, err
+			}
+			children[i] = p
+		}
+
+		return ber.NewConstructed(ber.Context, tag, children...), nil
+
+	case Not:
+		if len(f.Children) != 1 {
+			return nil, ErrBadFilter
+		}
+
+		p, err := f.Children[0].packet()
+		if err != nil {
+			return nil, err
+		}
+
+		return ber.NewConstructed(ber.Context, tag, p), nil
+
+	case Present:
+		return ber.New(ber.Context, tag, []byte(f.Attr)), nil
+
+	default:
+		return ber.NewConstructed(ber.Context, tag, ber.OctetString(f.Attr), ber.OctetString(f.Value)), nil
+	}
+}
+
+func filterFromPacket(p *ber.Packet) (Filter, error) {
+	op, in := tagFilters[p.Tag]
+	if p.Class != ber.Context || !in {
+		return Filter{}, ErrBadFilter
+	}
+
+	f := Filter{Op: op}
+
+	switch op {
+	case And, Or, Not:
+		if !p.Constructed || op == Not && len(p.Children) != 1 {
+			return Filter{}, ErrBadFilter
+		}
+
+		for _, child := range p.Children {
+			cf, err := filterFromPacket(child)
+			if err != nil {
+				return Filter{}, err
+			}
+			f.Children = append(f.Children, cf)
+		}
+
+	case Present:
+		if p.Constructed {
+			return Filter{}, ErrBadFilter
+		}
+		f.Attr = p.String()
+
+	default:
+		if !p.Constructed || len(p.Children) != 2 {
+			return Filter{}, ErrBadFilter
+		}
+		f.Attr = p.Children[0].String()
+		f.Value = p.Children[1].String()
+	}
+
+	return f, nil
+}
+
+var compositeOps = map[byte]FilterOp{'&': And, '|': Or, '!': Not}
+
+func parseFilter(str string) (Filter, string, error) {
+	inner, ok := strings.CutPrefix(str, "(")
+	if !ok || inner == "" {
+		return Filter{}, "", ErrBadFilter
+	}
+
+	switch inner[0] {
+	case '&', '|', '!':
+		op := compositeOps[inner[0]]
+		f := Filter{Op: op}
+
+		rest := inner[1:]
+		for !strings.HasPrefix(rest, ")") {
+			child, r, err := parseFilter(rest)
+			if err != nil {
+				return Filter{}, "", err
+			}
+
+			f.Children = append(f.Children, child)
+			rest = r
+		}
+
+		if op == Not && len(f.Children) != 1 || len(f.Children) == 0 {
+			return Filter{}, "", ErrBadFilter
+		}
+
+		return f, rest[1:], nil
+	}
+
+	end := strings.IndexByte(inner, ')')
+	if end < 0 {
+		return Filter{}, "", ErrBadFilter
+	}
+	item, rest := inner[:end], inner[end+1:]
+
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return Filter{}, "", ErrBadFilter
+	}
+	attr, value := item[:eq], item[eq+1:]
+
+	f := Filter{Op: Equal}
+	switch attr[len(attr)-1] {
+	case '>':
+		f.Op, attr = GreaterOrEqual, attr[:len(attr)-1]
+	case '<':
+		f.Op, attr = LessOrEqual, attr[:len(attr)-1]
+	case '~', ':':
+		return Filter{}, "", ErrBadFilter
+	}
+
+	if attr == "" {
+		return Filter{}, "", ErrBadFilter
+	}
+	f.Attr = attr
+
+	if f.Op == Equal && value == "*" {
+		f.Op = Present
+		return f, rest, nil
+	}
+
+	if strings.IndexByte(value, '*') >= 0 {
+		return Filter{}, "", ErrBadFilter
+	}
+
+	value, err := unescape(value)
+	if err != nil {
+		return Filter{}, "", err
+	}
+	f.Value = value
+
+	return f, rest, nil
+}
+
+func unescape(value string) (string, error) {
+	if strings.IndexByte(value, '\\') < 0 {
+		return value, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		if i+3 > len(value) {
+			return "", ErrBadFilter
+		}
+
+		c, err := strconv.ParseUint(value[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", ErrBadFilter
+		}
+
+		b.WriteByte(byte(c))
+		i += 2
+	}
+
+	return b.String(), nil
+}
+
+func (f Filter) write(b *strings.Builder) {
+	b.WriteByte('(')
+
+	switch f.Op {
+	case And, Or, Not:
+		b.WriteByte("&|!"[f.Op])
+		for _, child := range f.Children {
+			child.write(b)
+		}
+
+	case Present:
+		b.WriteString(f.Attr)
+		b.WriteString("=*")
+
+	default:
+		b.WriteString(f.Attr)
+		switch f.Op {
+		case GreaterOrEqual:
+			b.WriteByte('>')
+		case LessOrEqual:
+			b.WriteByte('<')
+		}
+		b.WriteByte('=')
+		b.WriteString(EscapeFilter(f.Value))
+	}
+
+	b.WriteByte(')')
+}
@@ -0,0 +1,386 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package ber implements the subset of the Basic Encoding Rules of
+// ASN.1, as defined in ITU-T X.690, required by LDAP, see RFC 4511
+// section 5.1.
+//
+// Only definite lengths are supported, as LDAP forbids the
+// indefinite form.
+package ber
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Class is the class of a tag.
+type Class uint8
+
+const (
+	Universal   Class = 0b00 << 6
+	Application Class = 0b01 << 6
+	Context     Class = 0b10 << 6
+	Private     Class = 0b11 << 6
+)
+
+// Universal tags used by LDAP.
+const (
+	TagBoolean     = 1
+	TagInteger     = 2
+	TagOctetString = 4
+	TagNull        = 5
+	TagEnumerated  = 10
+	TagSequence    = 16
+	TagSet         = 17
+)
+
+// MaxSize is the maximum size of the content of a packet read by
+// [Read], so that a peer cannot make the reader allocate arbitrary
+// amounts of memory.
+const MaxSize = 16 << 20
+
+var (
+	ErrMalformed = errors.New("ber: malformed packet")
+	ErrTooLarge  = errors.New("ber: packet too large")
+)
+
+// Packet is an encoded value, either primitive, with its content in
+// Value, or constructed, with its content in Children.
+type Packet struct {
+	Class       Class
+	Constructed bool
+	Tag         int
+	Value       []byte
+	Children    []*Packet
+}
+
+// New creates a primitive packet.
+func New(class Class, tag int, value []byte) *Packet {
+	return &Packet{Class: class, Tag: tag, Value: value}
+}
+
+// NewConstructed creates a constructed packet.
+func NewConstructed(class Class, tag int, children ...*Packet) *Packet {
+	return &Packet{Class: class, Constructed: true, Tag: tag, Children: children}
+}
+
+// Sequence creates a universal sequence.
+func Sequence(children ...*Packet) *Packet {
+	return NewConstructed(Universal, TagSequence, children...)
+}
+
+// Set creates a universal set.
+func Set(children ...*Packet) *Packet {
+	return NewConstructed(Universal, TagSet, children...)
+}
+
+// OctetString creates a universal octet string.
+func OctetString(s string) *Packet {
+	return New(Universal, TagOctetString, []byte(s))
+}
+
+// Integer creates a universal integer.
+func Integer(n int64) *Packet {
+	return New(Universal, TagInteger, encodeInt(n))
+}
+
+// Enumerated creates a universal enumerated.
+func Enumerated(n int64) *Packet {
+	return New(Universal, TagEnumerated, encodeInt(n))
+}
+
+// Boolean creates a universal boolean.
+func Boolean(b bool) *Packet {
+	if b {
+		return New(Universal, TagBoolean, []byte{0xff})
+	}
+
+	return New(Universal, TagBoolean, []byte{0x00})
+}
+
+// Null creates a universal null.
+func Null() *Packet {
+	return New(Universal, TagNull, nil)
+}
+
+// Is returns whether the packet has the given class and tag.
+func (p *Packet) Is(class Class, tag int) bool {
+	return p.Class == class && p.Tag == tag
+}
+
+// Int decodes the content of the packet as an integer.
+func (p *Packet) Int() (int64, error) {
+	if p.Constructed || len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, ErrMalformed
+	}
+
+	n := int64(int8(p.Value[0]))
+	for _, b := range p.Value[1:] {
+		n = n<<8 | int64(b)
+	}
+
+	return n, nil
+}
+
+// Bool decodes the content of the packet as a boolean.
+func (p *Packet) Bool() (bool, error) {
+	if p.Constructed || len(p.Value) != 1 {
+		return false, ErrMalformed
+	}
+
+	return p.Value[0] != 0, nil
+}
+
+// String returns the content of a primitive packet as a string.
+func (p *Packet) String() string {
+	return string(p.Value)
+}
+
+// Bytes encodes the packet.
+func (p *Packet) Bytes() []byte {
+	return p.append(nil)
+}
+
+func (p *Packet) append(buf []byte) []byte {
+	id := byte(p.Class)
+	if p.Constructed {
+		id |= 0x20
+	}
+
+	if p.Tag < 31 {
+		buf = append(buf, id|byte(p.Tag))
+	} else {
+		buf = append(buf, id|0x1f)
+		buf = appendBase128(buf, p.Tag)
+	}
+
+	content := p.Value
+	if p.Constructed {
+		content = nil
+		for _, child := range p.Children {
+			content = child.append(content)
+		}
+	}
+
+	buf = appendLength(buf, len(content))
+	return append(buf, content...)
+}
+
+// Decode decodes a single packet that must span the whole buffer.
+func Decode(buf []byte) (*Packet, error) {
+	p, n, err := decode(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if n != len(buf) {
+		return nil, ErrMalformed
+	}
+
+	return p, nil
+}
+
+// Read reads a single packet from the reader.
+func Read(r *bufio.Reader) (*Packet, error) {
+	var header []byte
+
+	id, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	header = append(header, id)
+
+	if id&0x1f == 0x1f {
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, unexpected(err)
+			}
+			header = append(header, b)
+
+			if b&0x80 == 0 {
+				break
+			}
+
+			if len(header) > 5 {
+				return nil, ErrMalformed
+			}
+		}
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, unexpected(err)
+	}
+	header = append(header, b)
+
+	length := int(b)
+	if b&0x80 != 0 {
+		n := int(b & 0x7f)
+		if n == 0 || n > 4 {
+			return nil, ErrMalformed
+		}
+
+		length = 0
+		for range n {
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, unexpected(err)
+			}
+			header = append(header, b)
+
+			length = length<<8 | int(b)
+		}
+	}
+
+	if length > MaxSize {
+		return nil, ErrTooLarge
+	}
+
+	buf := make([]byte, len(header)+length)
+	copy(buf, header)
+
+	if _, err := io.ReadFull(r, buf[len(header):]); err != nil {
+		return nil, unexpected(err)
+	}
+
+	return Decode(buf)
+}
+
+func decode(buf []byte) (*Packet, int, error) {
+	if len(buf) < 2 {
+		return nil, 0, ErrMalformed
+	}
+
+	p := &Packet{
+		Class:       Class(buf[0] & 0xc0),
+		Constructed: buf[0]&0x20 != 0,
+		Tag:         int(buf[0] & 0x1f),
+	}
+	i := 1
+
+	if p.Tag == 0x1f {
+		p.Tag = 0
+		for {
+			if i >= len(buf) || i > 5 {
+				return nil, 0, ErrMalformed
+			}
+
+			b := buf[i]
+			i++
+
+			p.Tag = p.Tag<<7 | int(b&0x7f)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+	}
+
+	if i >= len(buf) {
+		return nil, 0, ErrMalformed
+	}
+
+	length := int(buf[i])
+	i++
+
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 || i+n > len(buf) {
+			return nil, 0, ErrMalformed
+		}
+
+		length = 0
+		for _, b := range buf[i : i+n] {
+			length = length<<8 | int(b)
+		}
+		i += n
+	}
+
+	if length < 0 || i+length > len(buf) {
+		return nil, 0, ErrMalformed
+	}
+
+	content := buf[i : i+length]
+	i += length
+
+	if !p.Constructed {
+		p.Value = content
+		return p, i, nil
+	}
+
+	for len(content) > 0 {
+		child, n, err := decode(content)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		p.Children = append(p.Children, child)
+		content = content[n:]
+	}
+
+	return p, i, nil
+}
+
+func encodeInt(n int64) []byte {
+	size := 1
+	for m := n; m > 127 || m < -128; m >>= 8 {
+		size++
+	}
+
+	buf := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		buf[i] = byte(n)
+		n >>= 8
+	}
+
+	return buf
+}
+
+func appendLength(buf []byte, length int) []byte {
+	if length < 0x80 {
+		return append(buf, byte(length))
+	}
+
+	size := 0
+	for m := length; m > 0; m >>= 8 {
+		size++
+	}
+
+	buf = append(buf, 0x80|byte(size))
+	for i := size - 1; i >= 0; i-- {
+		buf = append(buf, byte(length>>(8*i)))
+	}
+
+	return buf
+}
+
+func appendBase128(buf []byte, n int) []byte {
+	size := 1
+	for m := n >> 7; m > 0; m >>= 7 {
+		size++
+	}
+
+	for i := size - 1; i >= 0; i-- {
+		b := byte(n>>(7*i)) & 0x7f
+		if i > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+	}
+
+	return buf
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
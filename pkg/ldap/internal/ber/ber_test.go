@@ -0,0 +1,98 @@
+package ber_test
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/pkg/ldap/internal/ber"
+)
+
+func TestInteger(t *testing.T) {
+	type Tests struct {
+		n       int64
+		encoded []byte
+	}
+
+	tests := []Tests{
+		{0, []byte{0x02, 0x01, 0x00}},
+		{127, []byte{0x02, 0x01, 0x7f}},
+		{128, []byte{0x02, 0x02, 0x00, 0x80}},
+		{256, []byte{0x02, 0x02, 0x01, 0x00}},
+		{-1, []byte{0x02, 0x01, 0xff}},
+		{-128, []byte{0x02, 0x01, 0x80}},
+		{-129, []byte{0x02, 0x02, 0xff, 0x7f}},
+	}
+
+	for _, test := range tests {
+		encoded := Integer(test.n).Bytes()
+		if !bytes.Equal(encoded, test.encoded) {
+			t.Errorf("%d should be encoded as %x, got %x", test.n, test.encoded, encoded)
+		}
+
+		p, err := Decode(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n, err := p.Int(); err != nil || n != test.n {
+			t.Errorf("%x should be decoded as %d, got %d", encoded, test.n, n)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	long := string(bytes.Repeat([]byte{'a'}, 300))
+
+	p := Sequence(
+		Integer(1),
+		NewConstructed(Application, 3,
+			OctetString("dc=example,dc=com"),
+			Enumerated(2),
+			Boolean(true),
+			New(Context, 7, []byte("uid")),
+			OctetString(long),
+		),
+		New(Context, 40, []byte("high tag")),
+	)
+
+	encoded := p.Bytes()
+
+	decoded, err := Read(bufio.NewReader(bytes.NewReader(encoded)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(decoded.Bytes(), encoded) {
+		t.Errorf("re-encoding should yield the same bytes")
+	}
+
+	op := decoded.Children[1]
+	if !op.Is(Application, 3) || !op.Constructed || len(op.Children) != 5 {
+		t.Fatalf("unexpected operation %+v", op)
+	}
+
+	if op.Children[4].String() != long {
+		t.Errorf("long octet string should survive the round trip")
+	}
+
+	if !decoded.Children[2].Is(Context, 40) {
+		t.Errorf("high tag should survive the round trip")
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	tests := [][]byte{
+		{},
+		{0x30},
+		{0x30, 0x05, 0x02, 0x01},
+		{0x30, 0x80, 0x00, 0x00},
+		{0x04, 0x85, 0x01, 0x00, 0x00, 0x00, 0x00},
+	}
+
+	for _, test := range tests {
+		if _, err := Decode(test); err == nil {
+			t.Errorf("%x should not be decoded", test)
+		}
+	}
+}
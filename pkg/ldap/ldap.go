@@ -0,0 +1,463 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package ldap implements a minimal client of the Lightweight
+// Directory Access Protocol, version 3, as defined in RFC 4511.
+//
+// It covers what is needed to authenticate users against a
+// directory: simple binds, searches and the StartTLS extended
+// operation. Operations are issued one at a time, each waiting for
+// its response before the next one is sent.
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alan-b-lima/almodon/pkg/ldap/internal/ber"
+)
+
+// Application tags of the protocol operations, see RFC 4511 section
+// 4.2 onwards.
+const (
+	opBindRequest       = 0
+	opBindResponse      = 1
+	opUnbindRequest     = 2
+	opSearchRequest     = 3
+	opSearchResultEntry = 4
+	opSearchResultDone  = 5
+	opSearchResultRef   = 19
+	opExtendedRequest   = 23
+	opExtendedResponse  = 24
+)
+
+// StartTLSOID is the name of the StartTLS extended operation, see
+// RFC 4511 section 4.14.
+const StartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// ResultCode is the result code of an operation, see RFC 4511
+// appendix A.
+type ResultCode int
+
+const (
+	Success                  ResultCode = 0
+	OperationsError          ResultCode = 1
+	ProtocolError            ResultCode = 2
+	TimeLimitExceeded        ResultCode = 3
+	SizeLimitExceeded        ResultCode = 4
+	NoSuchObject             ResultCode = 32
+	InvalidCredentials       ResultCode = 49
+	InsufficientAccessRights ResultCode = 50
+	Busy                     ResultCode = 51
+	Unavailable              ResultCode = 52
+	UnwillingToPerform       ResultCode = 53
+)
+
+// Error is the result of an operation that did not succeed.
+type Error struct {
+	Code      ResultCode
+	MatchedDN string
+	Message   string
+}
+
+func (err *Error) Error() string {
+	if err.Message == "" {
+		return fmt.Sprintf("ldap: result code %d", err.Code)
+	}
+
+	return fmt.Sprintf("ldap: result code %d: %s", err.Code, err.Message)
+}
+
+// IsCode returns whether err is an [*Error] of the given code.
+func IsCode(err error, code ResultCode) bool {
+	var lerr *Error
+	return errors.As(err, &lerr) && lerr.Code == code
+}
+
+var (
+	ErrUnexpectedResponse = errors.New("ldap: unexpected response")
+	ErrClosed             = errors.New("ldap: connection closed")
+	ErrEmptyPassword      = errors.New("ldap: empty password on a named bind")
+)
+
+// Scope is the scope of a search.
+type Scope int
+
+const (
+	BaseObject   Scope = 0
+	SingleLevel  Scope = 1
+	WholeSubtree Scope = 2
+)
+
+// SearchRequest describes a search. The zero value of Scope searches
+// only the base object.
+type SearchRequest struct {
+	BaseDN     string
+	Scope      Scope
+	SizeLimit  int
+	TimeLimit  time.Duration
+	Filter     string
+	Attributes []string
+}
+
+// Attribute is an attribute of an entry with its values.
+type Attribute struct {
+	Name   string
+	Values []string
+}
+
+// Entry is an entry of the directory.
+type Entry struct {
+	DN         string
+	Attributes []Attribute
+}
+
+// Values returns the values of the attribute, whose name is matched
+// case-insensitively, as attribute names are in LDAP.
+func (e *Entry) Values(name string) []string {
+	for _, attr := range e.Attributes {
+		if strings.EqualFold(attr.Name, name) {
+			return attr.Values
+		}
+	}
+
+	return nil
+}
+
+// Value returns the first value of the attribute, or an empty string
+// if the entry does not have it.
+func (e *Entry) Value(name string) string {
+	values := e.Values(name)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// Conn is a connection to a directory server.
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	mu   sync.Mutex
+	id   int64
+	tls  bool
+
+	// Timeout bounds the time spent on each operation, zero means no
+	// bound.
+	Timeout time.Duration
+}
+
+// Dial connects to the directory server at addr, in plain text. The
+// connection may be secured later through [Conn.StartTLS].
+func Dial(addr string, timeout time.Duration) (*Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewConn(conn)
+	c.Timeout = timeout
+	return c, nil
+}
+
+// DialTLS connects to the directory server at addr over TLS, as
+// done by the ldaps scheme.
+func DialTLS(addr string, config *tls.Config, timeout time.Duration) (*Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewConn(conn)
+	c.Timeout = timeout
+	c.tls = true
+	return c, nil
+}
+
+// NewConn creates a client over an established connection.
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+	}
+}
+
+// TLS returns whether the connection is secured by TLS.
+func (c *Conn) TLS() bool {
+	defer c.mu.Unlock()
+	c.mu.Lock()
+
+	return c.tls
+}
+
+// StartTLS secures the connection with TLS, see RFC 4511 section
+// 4.14. The server name of the config must be set, unless
+// verification is skipped.
+func (c *Conn) StartTLS(config *tls.Config) error {
+	defer c.mu.Unlock()
+	c.mu.Lock()
+
+	if c.tls {
+		return errors.New("ldap: connection already secured")
+	}
+
+	op := ber.NewConstructed(ber.Application, opExtendedRequest,
+		ber.New(ber.Context, 0, []byte(StartTLSOID)),
+	)
+
+	res, err := c.roundTrip(op)
+	if err != nil {
+		return err
+	}
+
+	if !res.Is(ber.Application, opExtendedResponse) {
+		return ErrUnexpectedResponse
+	}
+
+	if err := result(res); err != nil {
+		return err
+	}
+
+	conn := tls.Client(c.conn, config)
+	if err := c.deadline(); err != nil {
+		return err
+	}
+
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+
+	c.conn = conn
+	c.r = bufio.NewReader(conn)
+	c.tls = true
+
+	return nil
+}
+
+// Bind authenticates the connection with a simple bind, see RFC 4511
+// section 4.2. An empty password with a non-empty DN is refused, as
+// servers treat it as an unauthenticated bind, which succeeds
+// without checking anything.
+func (c *Conn) Bind(dn, password string) error {
+	if dn != "" && password == "" {
+		return ErrEmptyPassword
+	}
+
+	defer c.mu.Unlock()
+	c.mu.Lock()
+
+	op := ber.NewConstructed(ber.Application, opBindRequest,
+		ber.Integer(3),
+		ber.OctetString(dn),
+		ber.New(ber.Context, 0, []byte(password)),
+	)
+
+	res, err := c.roundTrip(op)
+	if err != nil {
+		return err
+	}
+
+	if !res.Is(ber.Application, opBindResponse) {
+		return ErrUnexpectedResponse
+	}
+
+	return result(res)
+}
+
+// Search performs a search, see RFC 4511 section 4.5. Referrals are
+// ignored. If the size limit is exceeded, the entries received are
+// returned together with the error.
+func (c *Conn) Search(req *SearchRequest) ([]Entry, error) {
+	filter, err := ParseFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	fp, err := filter.packet()
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make([]*ber.Packet, len(req.Attributes))
+	for i, attr := range req.Attributes {
+		attrs[i] = ber.OctetString(attr)
+	}
+
+	op := ber.NewConstructed(ber.Application, opSearchRequest,
+		ber.OctetString(req.BaseDN),
+		ber.Enumerated(int64(req.Scope)),
+		ber.Enumerated(0), // never dereference aliases
+		ber.Integer(int64(req.SizeLimit)),
+		ber.Integer(int64(req.TimeLimit/time.Second)),
+		ber.Boolean(false),
+		fp,
+		ber.Sequence(attrs...),
+	)
+
+	defer c.mu.Unlock()
+	c.mu.Lock()
+
+	id, err := c.send(op)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for {
+		res, err := c.receive(id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case res.Is(ber.Application, opSearchResultEntry):
+			entry, err := entryFromPacket(res)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+
+		case res.Is(ber.Application, opSearchResultRef):
+			continue
+
+		case res.Is(ber.Application, opSearchResultDone):
+			return entries, result(res)
+
+		default:
+			return nil, ErrUnexpectedResponse
+		}
+	}
+}
+
+// Close sends an unbind request, see RFC 4511 section 4.3, and closes
+// the connection.
+func (c *Conn) Close() error {
+	defer c.mu.Unlock()
+	c.mu.Lock()
+
+	c.send(ber.New(ber.Application, opUnbindRequest, nil))
+	return c.conn.Close()
+}
+
+func (c *Conn) roundTrip(op *ber.Packet) (*ber.Packet, error) {
+	id, err := c.send(op)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.receive(id)
+}
+
+func (c *Conn) send(op *ber.Packet) (int64, error) {
+	c.id++
+	msg := ber.Sequence(ber.Integer(c.id), op)
+
+	if err := c.deadline(); err != nil {
+		return 0, err
+	}
+
+	if _, err := c.conn.Write(msg.Bytes()); err != nil {
+		return 0, err
+	}
+
+	return c.id, nil
+}
+
+func (c *Conn) receive(id int64) (*ber.Packet, error) {
+	for {
+		msg, err := ber.Read(c.r)
+		if err != nil {
+			return nil, err
+		}
+
+		if !msg.Is(ber.Universal, ber.TagSequence) || len(msg.Children) < 2 {
+			return nil, ErrUnexpectedResponse
+		}
+
+		mid, err := msg.Children[0].Int()
+		if err != nil {
+			return nil, err
+		}
+
+		// unsolicited notifications, such as the notice of
+		// disconnection, carry the message id 0, see RFC 4511
+		// section 4.4
+		if mid == 0 {
+			if err := result(msg.Children[1]); err != nil {
+				return nil, err
+			}
+			return nil, ErrClosed
+		}
+
+		if mid != id {
+			continue
+		}
+
+		return msg.Children[1], nil
+	}
+}
+
+func (c *Conn) deadline() error {
+	if c.Timeout == 0 {
+		return c.conn.SetDeadline(time.Time{})
+	}
+
+	return c.conn.SetDeadline(time.Now().Add(c.Timeout))
+}
+
+// result decodes the LDAPResult that starts every response, see RFC
+// 4511 section 4.1.9.
+func result(res *ber.Packet) error {
+	if !res.Constructed || len(res.Children) < 3 {
+		return ErrUnexpectedResponse
+	}
+
+	code, err := res.Children[0].Int()
+	if err != nil {
+		return err
+	}
+
+	if ResultCode(code) == Success {
+		return nil
+	}
+
+	return &Error{
+		Code:      ResultCode(code),
+		MatchedDN: res.Children[1].String(),
+		Message:   res.Children[2].String(),
+	}
+}
+
+func entryFromPacket(p *ber.Packet) (Entry, error) {
+	if !p.Constructed || len(p.Children) != 2 {
+		return Entry{}, ErrUnexpectedResponse
+	}
+
+	entry := Entry{DN: p.Children[0].String()}
+	for _, attr := range p.Children[1].Children {
+		if len(attr.Children) != 2 {
+			return Entry{}, ErrUnexpectedResponse
+		}
+
+		a := Attribute{Name: attr.Children[0].String()}
+		for _, value := range attr.Children[1].Children {
+			a.Values = append(a.Values, value.String())
+		}
+
+		entry.Attributes = append(entry.Attributes, a)
+	}
+
+	return entry, nil
+}
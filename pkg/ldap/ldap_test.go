@@ -0,0 +1,195 @@
+package ldap_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/pkg/ldap"
+	"github.com/alan-b-lima/almodon/pkg/ldap/ldaptest"
+)
+
+var entries = []Entry{
+	{
+		DN: "ou=people,dc=ufla,dc=br",
+	},
+	{
+		DN: "uid=jdoe,ou=people,dc=ufla,dc=br",
+		Attributes: []Attribute{
+			{"objectClass", []string{"person", "inetOrgPerson"}},
+			{"uid", []string{"jdoe"}},
+			{"cn", []string{"John Doe"}},
+			{"mail", []string{"jdoe@ufla.br"}},
+			{"employeeNumber", []string{"123456"}},
+			{"userPassword", []string{"secret"}},
+		},
+	},
+	{
+		DN: "uid=mroe,ou=people,dc=ufla,dc=br",
+		Attributes: []Attribute{
+			{"objectClass", []string{"person"}},
+			{"uid", []string{"mroe"}},
+			{"cn", []string{"Mary Roe"}},
+			{"employeeNumber", []string{"654321"}},
+			{"userPassword", []string{"hunter22"}},
+		},
+	},
+}
+
+func TestParseFilter(t *testing.T) {
+	type Tests struct {
+		filter     string
+		shouldFail bool
+	}
+
+	tests := []Tests{
+		{"(uid=jdoe)", false},
+		{"(&(objectClass=person)(uid=jdoe))", false},
+		{"(|(uid=jdoe)(!(mail=*)))", false},
+		{"(employeeNumber>=100000)", false},
+		{"(cn=John \\28Jr\\29)", false},
+
+		{"", true},
+		{"uid=jdoe", true},
+		{"(uid=jdoe", true},
+		{"(uid=jdoe))", true},
+		{"(=jdoe)", true},
+		{"(uid=j*e)", true},
+		{"(cn=bad\\2)", true},
+		{"(&)", true},
+		{"(!(uid=a)(uid=b))", true},
+	}
+
+	for _, test := range tests {
+		f, err := ParseFilter(test.filter)
+		if test.shouldFail {
+			if err == nil {
+				t.Errorf("%q should not be parsed", test.filter)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q should be parsed: %v", test.filter, err)
+			continue
+		}
+
+		if f.String() != test.filter {
+			t.Errorf("%q should be printed back, got %q", test.filter, f.String())
+		}
+
+		var decoded Filter
+		buf, _ := f.MarshalBinary()
+		if err := decoded.UnmarshalBinary(buf); err != nil || decoded.String() != test.filter {
+			t.Errorf("%q should survive encoding, got %q", test.filter, decoded.String())
+		}
+	}
+}
+
+func TestEscapeFilter(t *testing.T) {
+	f, err := ParseFilter("(uid=" + EscapeFilter("*)(uid=*") + ")")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Op != Equal || f.Value != "*)(uid=*" {
+		t.Errorf("escaped value should be taken literally, got %+v", f)
+	}
+}
+
+func TestBindAndSearch(t *testing.T) {
+	srv := ldaptest.NewServer(entries...)
+	defer srv.Close()
+
+	conn, err := Dial(srv.Addr, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.Bind("uid=jdoe,ou=people,dc=ufla,dc=br", "wrong"); !IsCode(err, InvalidCredentials) {
+		t.Errorf("bind with a wrong password should fail with invalid credentials, got %v", err)
+	}
+
+	if err := conn.Bind("uid=jdoe,ou=people,dc=ufla,dc=br", ""); err != ErrEmptyPassword {
+		t.Errorf("bind with an empty password should be refused, got %v", err)
+	}
+
+	if err := conn.Bind("uid=jdoe,ou=people,dc=ufla,dc=br", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := conn.Search(&SearchRequest{
+		BaseDN:     "dc=ufla,dc=br",
+		Scope:      WholeSubtree,
+		Filter:     "(&(objectClass=person)(employeeNumber=123456))",
+		Attributes: []string{"cn", "mail"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(res))
+	}
+
+	if res[0].Value("CN") != "John Doe" || res[0].Value("mail") != "jdoe@ufla.br" || res[0].Value("uid") != "" {
+		t.Errorf("unexpected entry %+v", res[0])
+	}
+
+	res, err = conn.Search(&SearchRequest{
+		BaseDN:    "ou=people,dc=ufla,dc=br",
+		Scope:     SingleLevel,
+		Filter:    "(objectClass=person)",
+		SizeLimit: 1,
+	})
+	if !IsCode(err, SizeLimitExceeded) || len(res) != 1 {
+		t.Errorf("search should be limited to 1 entry, got %d and %v", len(res), err)
+	}
+}
+
+func TestStartTLS(t *testing.T) {
+	srv := ldaptest.NewUnstartedServer(entries...)
+	srv.StartTLS()
+	defer srv.Close()
+
+	conn, err := Dial(srv.Addr, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.StartTLS(srv.ClientTLSConfig()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !conn.TLS() {
+		t.Errorf("connection should be secured")
+	}
+
+	if err := conn.Bind("uid=mroe,ou=people,dc=ufla,dc=br", "hunter22"); err != nil {
+		t.Fatal(err)
+	}
+
+	if binds := srv.Binds(); len(binds) != 1 || binds[0] != "uid=mroe,ou=people,dc=ufla,dc=br" {
+		t.Errorf("unexpected binds %v", binds)
+	}
+}
+
+func TestStartTLSUnavailable(t *testing.T) {
+	srv := ldaptest.NewServer(entries...)
+	defer srv.Close()
+
+	conn, err := Dial(srv.Addr, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.StartTLS(srv.ClientTLSConfig()); !IsCode(err, Unavailable) {
+		t.Errorf("StartTLS should be unavailable, got %v", err)
+	}
+
+	if err := conn.Bind("", ""); err != nil {
+		t.Errorf("connection should still be usable, got %v", err)
+	}
+}
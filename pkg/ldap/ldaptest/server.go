@@ -0,0 +1,464 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package ldaptest provides an in-process directory server for
+// testing LDAP clients, in the spirit of [net/http/httptest].
+//
+// The server understands simple binds, searches and the StartTLS
+// extended operation, holding its entries in memory. Passwords are
+// taken from the "userPassword" attribute, in plain text, which is
+// never returned by searches.
+package ldaptest
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alan-b-lima/almodon/pkg/ldap"
+	"github.com/alan-b-lima/almodon/pkg/ldap/internal/ber"
+)
+
+// PasswordAttribute is the attribute checked by binds.
+const PasswordAttribute = "userPassword"
+
+// application tags of the protocol operations, see RFC 4511 section
+// 4.2 onwards
+const (
+	opBindRequest       = 0
+	opBindResponse      = 1
+	opUnbindRequest     = 2
+	opSearchRequest     = 3
+	opSearchResultEntry = 4
+	opSearchResultDone  = 5
+	opExtendedRequest   = 23
+	opExtendedResponse  = 24
+)
+
+// Server is a directory server listening on a loopback address.
+type Server struct {
+	// Addr is the address of the server, in the form host:port.
+	Addr string
+
+	// Entries is the content of the directory. It must not be
+	// modified while the server is running.
+	Entries []ldap.Entry
+
+	// TLS, when set, enables the StartTLS extended operation.
+	TLS *tls.Config
+
+	listener net.Listener
+	cert     *x509.Certificate
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	binds []string
+}
+
+// NewServer starts a server with the given entries.
+func NewServer(entries ...ldap.Entry) *Server {
+	s := NewUnstartedServer(entries...)
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer creates a server with the given entries that
+// listens, but does not accept connections until started.
+func NewUnstartedServer(entries ...ldap.Entry) *Server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("ldaptest: failed to listen: " + err.Error())
+	}
+
+	return &Server{
+		Addr:     ln.Addr().String(),
+		Entries:  entries,
+		listener: ln,
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+// Start starts accepting connections.
+func (s *Server) Start() {
+	s.wg.Add(1)
+	go s.serve()
+}
+
+// StartTLS enables StartTLS with a freshly generated self-signed
+// certificate and starts accepting connections. Clients should trust
+// it through [Server.ClientTLSConfig].
+func (s *Server) StartTLS() {
+	cert, err := selfSigned()
+	if err != nil {
+		panic("ldaptest: failed to generate certificate: " + err.Error())
+	}
+
+	s.cert = cert.Leaf
+	s.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	s.Start()
+}
+
+// ClientTLSConfig returns a TLS config that trusts the certificate
+// generated by [Server.StartTLS].
+func (s *Server) ClientTLSConfig() *tls.Config {
+	pool := x509.NewCertPool()
+	if s.cert != nil {
+		pool.AddCert(s.cert)
+	}
+
+	return &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"}
+}
+
+// Certificate returns the certificate generated by [Server.StartTLS],
+// nil if it was not called.
+func (s *Server) Certificate() *x509.Certificate {
+	return s.cert
+}
+
+// Binds returns the DNs of the successful binds, in order.
+func (s *Server) Binds() []string {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+
+	return append([]string(nil), s.binds...)
+}
+
+// Close stops the server and closes every connection.
+func (s *Server) Close() {
+	s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(raw net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, raw)
+		s.mu.Unlock()
+
+		raw.Close()
+	}()
+
+	var conn net.Conn = raw
+
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := ber.Read(r)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+
+		id, err := msg.Children[0].Int()
+		if err != nil {
+			return
+		}
+
+		op := msg.Children[1]
+		if op.Class != ber.Application {
+			return
+		}
+
+		write := func(op *ber.Packet) error {
+			_, err := conn.Write(ber.Sequence(ber.Integer(id), op).Bytes())
+			return err
+		}
+
+		switch op.Tag {
+		case opBindRequest:
+			err = write(s.bind(op))
+
+		case opUnbindRequest:
+			return
+
+		case opSearchRequest:
+			err = s.search(op, write)
+
+		case opExtendedRequest:
+			var upgrade bool
+			upgrade, err = s.extended(op, write)
+			if err == nil && upgrade {
+				tconn := tls.Server(conn, s.TLS)
+				if tconn.Handshake() != nil {
+					return
+				}
+
+				conn, r = tconn, bufio.NewReader(tconn)
+			}
+
+		default:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) bind(op *ber.Packet) *ber.Packet {
+	if len(op.Children) != 3 || !op.Children[2].Is(ber.Context, 0) {
+		return response(opBindResponse, ldap.ProtocolError, "only simple binds are supported")
+	}
+
+	dn, password := op.Children[1].String(), op.Children[2].String()
+	if dn == "" && password == "" {
+		return response(opBindResponse, ldap.Success, "")
+	}
+
+	entry, ok := s.entry(dn)
+	if !ok || password == "" || entry.Value(PasswordAttribute) != password {
+		return response(opBindResponse, ldap.InvalidCredentials, "invalid credentials")
+	}
+
+	s.mu.Lock()
+	s.binds = append(s.binds, entry.DN)
+	s.mu.Unlock()
+
+	return response(opBindResponse, ldap.Success, "")
+}
+
+func (s *Server) search(op *ber.Packet, write func(*ber.Packet) error) error {
+	if len(op.Children) != 8 {
+		return write(response(opSearchResultDone, ldap.ProtocolError, "malformed search"))
+	}
+
+	base := op.Children[0].String()
+	scope, _ := op.Children[1].Int()
+	limit, _ := op.Children[3].Int()
+
+	var filter ldap.Filter
+	if err := filter.UnmarshalBinary(op.Children[6].Bytes()); err != nil {
+		return write(response(opSearchResultDone, ldap.ProtocolError, "malformed filter"))
+	}
+
+	var attrs []string
+	for _, attr := range op.Children[7].Children {
+		attrs = append(attrs, attr.String())
+	}
+
+	var count int64
+	for _, entry := range s.Entries {
+		if !inScope(entry.DN, base, ldap.Scope(scope)) || !matches(&entry, filter) {
+			continue
+		}
+
+		if limit > 0 && count == limit {
+			return write(response(opSearchResultDone, ldap.SizeLimitExceeded, ""))
+		}
+		count++
+
+		if err := write(entryPacket(&entry, attrs)); err != nil {
+			return err
+		}
+	}
+
+	return write(response(opSearchResultDone, ldap.Success, ""))
+}
+
+func (s *Server) extended(op *ber.Packet, write func(*ber.Packet) error) (bool, error) {
+	if len(op.Children) == 0 || op.Children[0].String() != ldap.StartTLSOID {
+		return false, write(response(opExtendedResponse, ldap.ProtocolError, "unsupported extended operation"))
+	}
+
+	if s.TLS == nil {
+		return false, write(response(opExtendedResponse, ldap.Unavailable, "StartTLS is not enabled"))
+	}
+
+	return true, write(response(opExtendedResponse, ldap.Success, ""))
+}
+
+func (s *Server) entry(dn string) (ldap.Entry, bool) {
+	for _, entry := range s.Entries {
+		if normalize(entry.DN) == normalize(dn) {
+			return entry, true
+		}
+	}
+
+	return ldap.Entry{}, false
+}
+
+func response(tag int, code ldap.ResultCode, message string) *ber.Packet {
+	return ber.NewConstructed(ber.Application, tag,
+		ber.Enumerated(int64(code)),
+		ber.OctetString(""),
+		ber.OctetString(message),
+	)
+}
+
+func entryPacket(entry *ldap.Entry, attrs []string) *ber.Packet {
+	var list []*ber.Packet
+	for _, attr := range entry.Attributes {
+		if strings.EqualFold(attr.Name, PasswordAttribute) || !requested(attr.Name, attrs) {
+			continue
+		}
+
+		values := make([]*ber.Packet, len(attr.Values))
+		for i, value := range attr.Values {
+			values[i] = ber.OctetString(value)
+		}
+
+		list = append(list, ber.Sequence(ber.OctetString(attr.Name), ber.Set(values...)))
+	}
+
+	return ber.NewConstructed(ber.Application, opSearchResultEntry,
+		ber.OctetString(entry.DN),
+		ber.Sequence(list...),
+	)
+}
+
+func requested(name string, attrs []string) bool {
+	if len(attrs) == 0 {
+		return true
+	}
+
+	for _, attr := range attrs {
+		if attr == "*" || strings.EqualFold(attr, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func inScope(dn, base string, scope ldap.Scope) bool {
+	dn, base = normalize(dn), normalize(base)
+
+	switch scope {
+	case ldap.BaseObject:
+		return dn == base
+
+	case ldap.SingleLevel:
+		_, parent, _ := strings.Cut(dn, ",")
+		return parent == base
+
+	default:
+		return base == "" || dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+func matches(entry *ldap.Entry, f ldap.Filter) bool {
+	switch f.Op {
+	case ldap.And:
+		for _, child := range f.Children {
+			if !matches(entry, child) {
+				return false
+			}
+		}
+		return true
+
+	case ldap.Or:
+		for _, child := range f.Children {
+			if matches(entry, child) {
+				return true
+			}
+		}
+		return false
+
+	case ldap.Not:
+		return !matches(entry, f.Children[0])
+
+	case ldap.Present:
+		return entry.Values(f.Attr) != nil
+	}
+
+	for _, value := range entry.Values(f.Attr) {
+		value, assertion := strings.ToLower(value), strings.ToLower(f.Value)
+
+		switch f.Op {
+		case ldap.Equal:
+			if value == assertion {
+				return true
+			}
+		case ldap.GreaterOrEqual:
+			if value >= assertion {
+				return true
+			}
+		case ldap.LessOrEqual:
+			if value <= assertion {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// normalize lowers the case of a DN and removes the spaces around
+// its separators, which is enough for the DNs of tests.
+func normalize(dn string) string {
+	rdns := strings.Split(strings.ToLower(dn), ",")
+	for i, rdn := range rdns {
+		attr, value, _ := strings.Cut(rdn, "=")
+		rdns[i] = strings.TrimSpace(attr) + "=" + strings.TrimSpace(value)
+	}
+
+	return strings.Join(rdns, ",")
+}
+
+func selfSigned() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ldaptest"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
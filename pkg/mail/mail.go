@@ -0,0 +1,259 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package mail implements the composition of e-mail messages
+// and their delivery through SMTP, as defined in RFC 5321,
+// using the standard [net/smtp] package.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Message is an e-mail message. At least one of Text and HTML
+// must be present, if both are, the message is sent as a
+// multipart/alternative message.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender is an entity capable of delivering messages.
+type Sender interface {
+	Send(Message) error
+}
+
+// SenderFunc is an adapter to allow the use of ordinary functions
+// as a [Sender].
+type SenderFunc func(Message) error
+
+// Send implements the [Sender] interface, it calls f(msg).
+func (f SenderFunc) Send(msg Message) error {
+	return f(msg)
+}
+
+var (
+	ErrNoSender    = errors.New("mail: message has no sender")
+	ErrNoRecipient = errors.New("mail: message has no recipients")
+	ErrNoBody      = errors.New("mail: message has no body")
+)
+
+// SMTP is a [Sender] that delivers messages to an SMTP server. The
+// zero value is not usable, at least Addr must be set.
+//
+// If the server advertises the STARTTLS extension, the connection is
+// upgraded before authenticating. Authentication is only attempted
+// if Username is not empty.
+type SMTP struct {
+	// Addr is the address of the server, in the form host:port.
+	Addr string
+
+	// Username and Password are the credentials used for the PLAIN
+	// authentication mechanism.
+	Username string
+	Password string
+
+	// TLSConfig is the configuration used for STARTTLS, if nil, a
+	// configuration with ServerName set to the host of Addr is used.
+	TLSConfig *tls.Config
+
+	// RequireTLS makes delivery fail whenever the server does not
+	// support STARTTLS.
+	RequireTLS bool
+
+	// Timeout bounds the whole delivery of a single message, if zero,
+	// 30 seconds is used.
+	Timeout time.Duration
+}
+
+// Send implements the [Sender] interface.
+func (s *SMTP) Send(msg Message) error {
+	body, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	from, err := netmail.ParseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("mail: bad sender address: %w", err)
+	}
+
+	to := make([]string, 0, len(msg.To))
+	for _, rcpt := range msg.To {
+		addr, err := netmail.ParseAddress(rcpt)
+		if err != nil {
+			return fmt.Errorf("mail: bad recipient address: %w", err)
+		}
+
+		to = append(to, addr.Address)
+	}
+
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return fmt.Errorf("mail: bad server address: %w", err)
+	}
+
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	conn, err := net.DialTimeout("tcp", s.Addr, timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		config := s.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: host}
+		}
+
+		if err := c.StartTLS(config); err != nil {
+			return err
+		}
+	} else if s.RequireTLS {
+		return errors.New("mail: server does not support STARTTLS")
+	}
+
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(body); err != nil {
+		w.Close()
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// Bytes composes the message in the Internet Message Format, as
+// defined in RFC 5322, with its MIME parts as defined in RFC 2045
+// and RFC 2046. Bodies are encoded as quoted-printable UTF-8 text.
+func (msg *Message) Bytes() ([]byte, error) {
+	if msg.From == "" {
+		return nil, ErrNoSender
+	}
+
+	if len(msg.To) == 0 {
+		return nil, ErrNoRecipient
+	}
+
+	if msg.Text == "" && msg.HTML == "" {
+		return nil, ErrNoBody
+	}
+
+	var b bytes.Buffer
+
+	header(&b, "From", msg.From)
+	header(&b, "To", strings.Join(msg.To, ", "))
+	header(&b, "Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header(&b, "Date", time.Now().Format(time.RFC1123Z))
+	header(&b, "Message-ID", messageID(msg.From))
+	header(&b, "MIME-Version", "1.0")
+
+	switch {
+	case msg.Text != "" && msg.HTML != "":
+		boundary := randomHex(16)
+
+		header(&b, "Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+		b.WriteString("\r\n")
+
+		b.WriteString("--" + boundary + "\r\n")
+		part(&b, "text/plain", msg.Text)
+		b.WriteString("\r\n--" + boundary + "\r\n")
+		part(&b, "text/html", msg.HTML)
+		b.WriteString("\r\n--" + boundary + "--\r\n")
+
+	case msg.Text != "":
+		part(&b, "text/plain", msg.Text)
+
+	default:
+		part(&b, "text/html", msg.HTML)
+	}
+
+	return b.Bytes(), nil
+}
+
+func header(b *bytes.Buffer, key, value string) {
+	b.WriteString(key)
+	b.WriteString(": ")
+	b.WriteString(strings.NewReplacer("\r", "", "\n", "").Replace(value))
+	b.WriteString("\r\n")
+}
+
+func part(b *bytes.Buffer, contentType, body string) {
+	header(b, "Content-Type", contentType+"; charset=utf-8")
+	header(b, "Content-Transfer-Encoding", "quoted-printable")
+	b.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(b)
+	w.Write([]byte(body))
+	w.Close()
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := netmail.ParseAddress(from); err == nil {
+		if i := strings.LastIndexByte(addr.Address, '@'); i >= 0 {
+			domain = addr.Address[i+1:]
+		}
+	}
+
+	return "<" + randomHex(16) + "@" + domain + ">"
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
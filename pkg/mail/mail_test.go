@@ -0,0 +1,185 @@
+package mail_test
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"strings"
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/pkg/mail"
+)
+
+func TestSMTPSend(t *testing.T) {
+	srv := newServer(t)
+
+	sender := SMTP{Addr: srv.addr}
+	msg := Message{
+		From:    "Almodon <almoxarifado@ufvjm.edu.br>",
+		To:      []string{"alan@ufvjm.edu.br"},
+		Subject: "Solicitação aprovada",
+		Text:    "Olá, Alan!\nSua solicitação foi aprovada.",
+		HTML:    "<p>Olá, Alan!</p><p>Sua solicitação foi aprovada.</p>",
+	}
+
+	if err := sender.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	env := <-srv.envelopes
+
+	if env.from != "almoxarifado@ufvjm.edu.br" {
+		t.Errorf("expected sender almoxarifado@ufvjm.edu.br, got %q", env.from)
+	}
+
+	if len(env.to) != 1 || env.to[0] != "alan@ufvjm.edu.br" {
+		t.Errorf("expected recipient alan@ufvjm.edu.br, got %v", env.to)
+	}
+
+	parsed, err := netmail.ReadMessage(strings.NewReader(env.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subject != msg.Subject {
+		t.Errorf("expected subject %q, got %q", msg.Subject, subject)
+	}
+
+	if ct := parsed.Header.Get("Content-Type"); !strings.HasPrefix(ct, "multipart/alternative") {
+		t.Errorf("expected multipart/alternative content, got %q", ct)
+	}
+}
+
+func TestMessageBytes(t *testing.T) {
+	type Tests struct {
+		msg        Message
+		shouldFail bool
+	}
+
+	tests := []Tests{
+		{Message{From: "a@b.com", To: []string{"c@d.com"}, Text: "x"}, false},
+		{Message{From: "a@b.com", To: []string{"c@d.com"}, HTML: "<p>x</p>"}, false},
+		{Message{To: []string{"c@d.com"}, Text: "x"}, true},
+		{Message{From: "a@b.com", Text: "x"}, true},
+		{Message{From: "a@b.com", To: []string{"c@d.com"}}, true},
+	}
+
+	for _, test := range tests {
+		_, err := test.msg.Bytes()
+
+		if (err != nil) != test.shouldFail {
+			if test.shouldFail {
+				t.Errorf("Message %+v: expected error, but got nil", test.msg)
+			} else {
+				t.Errorf("Message %+v: did not expect error, but got: %v", test.msg, err)
+			}
+		}
+	}
+}
+
+type envelope struct {
+	from string
+	to   []string
+	data string
+}
+
+type server struct {
+	addr      string
+	envelopes chan envelope
+}
+
+// newServer starts a minimal in-process SMTP server, enough for
+// exercising the client side of the protocol.
+func newServer(t *testing.T) *server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	srv := &server{
+		addr:      ln.Addr().String(),
+		envelopes: make(chan envelope, 1),
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go srv.serve(conn)
+		}
+	}()
+
+	return srv
+}
+
+func (srv *server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var env envelope
+	reply("220 localhost ESMTP")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		cmd := strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+
+		case "MAIL":
+			env.from = strings.Trim(cmd[strings.IndexByte(cmd, ':')+1:], "<> ")
+			reply("250 OK")
+
+		case "RCPT":
+			env.to = append(env.to, strings.Trim(cmd[strings.IndexByte(cmd, ':')+1:], "<> "))
+			reply("250 OK")
+
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				if line == ".\r\n" {
+					break
+				}
+
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+
+			env.data = data.String()
+			srv.envelopes <- env
+			reply("250 OK")
+
+		case "QUIT":
+			reply("221 Bye")
+			return
+
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
@@ -0,0 +1,390 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package metrics implements counters, gauges and histograms,
+// optionally labeled, exposed in the Prometheus text format,
+// version 0.0.4.
+//
+// Metrics are created through a [Registry], which returns the
+// metric already registered under a name, if any, so that
+// components created many times, as on configuration reloads,
+// keep counting where they stopped.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds of the buckets of
+// histograms of durations, in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry holds metrics and writes them out.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]metric)}
+}
+
+// Counter returns the counter of the given name, registering it
+// if needed. It panics if the name is registered for another
+// kind of metric.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return register(r, name, func() *Counter {
+		return &Counter{family: newFamily[float64](name, help, "counter", labels)}
+	})
+}
+
+// Gauge returns the gauge of the given name, registering it if
+// needed. It panics if the name is registered for another kind
+// of metric.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return register(r, name, func() *Gauge {
+		return &Gauge{family: newFamily[float64](name, help, "gauge", labels)}
+	})
+}
+
+// GaugeFunc registers a gauge whose value is given by fn at
+// every write, replacing the function registered before, if
+// any.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	g := register(r, name, func() *gaugeFunc {
+		return &gaugeFunc{name: name, help: help}
+	})
+
+	g.mu.Lock()
+	g.fn = fn
+	g.mu.Unlock()
+}
+
+// Histogram returns the histogram of the given name, with the
+// given bucket upper bounds, registering it if needed. It panics
+// if the name is registered for another kind of metric.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return register(r, name, func() *Histogram {
+		buckets := slices.Clone(buckets)
+		slices.Sort(buckets)
+
+		return &Histogram{family: newFamily[histogram](name, help, "histogram", labels), buckets: buckets}
+	})
+}
+
+func register[M metric](r *Registry, name string, create func() M) M {
+	defer r.mu.Unlock()
+	r.mu.Lock()
+
+	if m, in := r.metrics[name]; in {
+		m, ok := m.(M)
+		if !ok {
+			panic("metrics: " + name + " is already registered as another kind of metric")
+		}
+
+		return m
+	}
+
+	m := create()
+	r.metrics[name] = m
+	return m
+}
+
+// WriteTo writes every metric, sorted by name, in the text
+// format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]metric, len(names))
+	slices.Sort(names)
+	for i, name := range names {
+		metrics[i] = r.metrics[name]
+	}
+	r.mu.Unlock()
+
+	cw := countingWriter{w: w}
+	bw := bufio.NewWriter(&cw)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP writes the metrics as the response.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.WriteTo(w)
+}
+
+// family is a metric with all its labeled series.
+type family[S any] struct {
+	name, help, kind string
+	labels           []string
+
+	mu     sync.Mutex
+	series map[string]*series[S]
+}
+
+type series[S any] struct {
+	values []string
+	state  S
+}
+
+func newFamily[S any](name, help, kind string, labels []string) family[S] {
+	return family[S]{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		series: make(map[string]*series[S]),
+	}
+}
+
+// get returns the series of the label values, creating it if
+// needed, with the lock of the family held.
+func (f *family[S]) get(values []string) *series[S] {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+	s, in := f.series[key]
+	if !in {
+		s = &series[S]{values: slices.Clone(values)}
+		f.series[key] = s
+	}
+
+	return s
+}
+
+// each calls fn for every series, sorted by their label values,
+// with the lock of the family held.
+func (f *family[S]) each(fn func(s *series[S])) {
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		fn(f.series[key])
+	}
+}
+
+func (f *family[S]) header(w *bufio.Writer) {
+	writeHeader(w, f.name, f.help, f.kind)
+}
+
+// Counter is a value that only increases.
+type Counter struct {
+	family[float64]
+}
+
+// Inc increments the series of the label values by one.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v, which must not be negative, to the series of the
+// label values.
+func (c *Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+
+	defer c.mu.Unlock()
+	c.mu.Lock()
+
+	c.get(values).state += v
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	defer c.mu.Unlock()
+	c.mu.Lock()
+
+	c.header(w)
+	c.each(func(s *series[float64]) {
+		writeSample(w, c.name, "", c.labels, s.values, "", "", s.state)
+	})
+}
+
+// Gauge is a value that may increase and decrease.
+type Gauge struct {
+	family[float64]
+}
+
+// Set sets the series of the label values to v.
+func (g *Gauge) Set(v float64, values ...string) {
+	defer g.mu.Unlock()
+	g.mu.Lock()
+
+	g.get(values).state = v
+}
+
+// Add adds v, which may be negative, to the series of the label
+// values.
+func (g *Gauge) Add(v float64, values ...string) {
+	defer g.mu.Unlock()
+	g.mu.Lock()
+
+	g.get(values).state += v
+}
+
+func (g *Gauge) write(w *bufio.Writer) {
+	defer g.mu.Unlock()
+	g.mu.Lock()
+
+	g.header(w)
+	g.each(func(s *series[float64]) {
+		writeSample(w, g.name, "", g.labels, s.values, "", "", s.state)
+	})
+}
+
+type gaugeFunc struct {
+	name, help string
+
+	mu sync.Mutex
+	fn func() float64
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	g.mu.Lock()
+	fn := g.fn
+	g.mu.Unlock()
+
+	writeHeader(w, g.name, g.help, "gauge")
+	writeSample(w, g.name, "", nil, nil, "", "", fn())
+}
+
+// Histogram counts observations in buckets, along with their
+// sum and count.
+type Histogram struct {
+	family[histogram]
+	buckets []float64
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Observe records v in the series of the label values.
+func (h *Histogram) Observe(v float64, values ...string) {
+	defer h.mu.Unlock()
+	h.mu.Lock()
+
+	s := h.get(values)
+	if s.state.counts == nil {
+		s.state.counts = make([]uint64, len(h.buckets))
+	}
+
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		s.state.counts[i]++
+	}
+	s.state.sum += v
+	s.state.count++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	defer h.mu.Unlock()
+	h.mu.Lock()
+
+	h.header(w)
+	h.each(func(s *series[histogram]) {
+		// buckets are cumulative in the format
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.state.counts[i]
+			writeSample(w, h.name, "_bucket", h.labels, s.values, "le", formatFloat(bound), float64(cumulative))
+		}
+
+		writeSample(w, h.name, "_bucket", h.labels, s.values, "le", "+Inf", float64(s.state.count))
+		writeSample(w, h.name, "_sum", h.labels, s.values, "", "", s.state.sum)
+		writeSample(w, h.name, "_count", h.labels, s.values, "", "", float64(s.state.count))
+	})
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	if help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func writeSample(w *bufio.Writer, name, suffix string, labels, values []string, extra, extraValue string, v float64) {
+	w.WriteString(name)
+	w.WriteString(suffix)
+
+	if len(labels) > 0 || extra != "" {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, label, escaper.Replace(values[i]))
+		}
+
+		if extra != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extra, extraValue)
+		}
+		w.WriteByte('}')
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(buf []byte) (int, error) {
+	n, err := w.w.Write(buf)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,74 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/pkg/metrics"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.Counter("http_requests_total", "Requests served.", "method", "status")
+	requests.Inc("GET", "200")
+	requests.Inc("GET", "200")
+	requests.Add(3, "POST", "400")
+
+	r.Gauge("temperature", "", "room").Set(-2.5, `lab "B"`)
+	r.GaugeFunc("answer", "Always\nforty-two.", func() float64 { return 42 })
+
+	latency := r.Histogram("latency_seconds", "Latency.", []float64{1, 0.1})
+	latency.Observe(0.05)
+	latency.Observe(0.1)
+	latency.Observe(0.5)
+	latency.Observe(7)
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `# HELP answer Always\nforty-two.
+# TYPE answer gauge
+answer 42
+# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",status="200"} 2
+http_requests_total{method="POST",status="400"} 3
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 2
+latency_seconds_bucket{le="1"} 3
+latency_seconds_bucket{le="+Inf"} 4
+latency_seconds_sum 7.65
+latency_seconds_count 4
+# TYPE temperature gauge
+temperature{room="lab \"B\""} -2.5
+`
+
+	if got := b.String(); got != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, got)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+
+	r.Counter("logins_total", "").Inc()
+	r.Counter("logins_total", "").Inc()
+
+	var b strings.Builder
+	r.WriteTo(&b)
+
+	if !strings.Contains(b.String(), "logins_total 2\n") {
+		t.Errorf("expected registering again to return the same counter, got:\n%s", b.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected registering another kind of metric under the same name to panic")
+		}
+	}()
+	r.Gauge("logins_total", "")
+}
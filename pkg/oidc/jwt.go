@@ -0,0 +1,356 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// Leeway is the clock skew tolerated when checking the times of
+	// an ID token.
+	Leeway = time.Minute
+
+	// _MinRefetch is the minimum interval between fetches of the key
+	// set caused by unknown key ids, so that forged tokens cannot be
+	// used to flood the provider.
+	_MinRefetch = time.Minute
+)
+
+var (
+	ErrMalformedToken    = errors.New("oidc: malformed token")
+	ErrUnsupportedAlg    = errors.New("oidc: unsupported signature algorithm")
+	ErrUnknownKey        = errors.New("oidc: token signed by an unknown key")
+	ErrBadSignature      = errors.New("oidc: bad token signature")
+	ErrBadIssuer         = errors.New("oidc: token issued by another issuer")
+	ErrBadAudience       = errors.New("oidc: token issued to another client")
+	ErrExpired           = errors.New("oidc: token expired")
+	ErrIssuedInTheFuture = errors.New("oidc: token issued in the future")
+	ErrBadNonce          = errors.New("oidc: token nonce does not match")
+)
+
+// IDToken is a verified ID token, see OpenID Connect Core 1.0
+// section 2.
+type IDToken struct {
+	Issuer   string
+	Subject  string
+	Audience []string
+	Expiry   time.Time
+	IssuedAt time.Time
+	Nonce    string
+
+	// AMR are the authentication methods used by the provider, such
+	// as "pwd", "otp" or "mfa", see RFC 8176.
+	AMR []string
+
+	claims []byte
+}
+
+// Claims decodes the claims of the token into v.
+func (t *IDToken) Claims(v any) error {
+	return json.Unmarshal(t.claims, v)
+}
+
+// Verify verifies the signature and the claims of an ID token,
+// requiring its nonce to be the given one.
+func (rp *RelyingParty) Verify(ctx context.Context, raw, nonce string) (*IDToken, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	key, err := rp.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	var claims struct {
+		Issuer   string   `json:"iss"`
+		Subject  string   `json:"sub"`
+		Audience audience `json:"aud"`
+		Expiry   int64    `json:"exp"`
+		IssuedAt int64    `json:"iat"`
+		Nonce    string   `json:"nonce"`
+		AMR      []string `json:"amr"`
+		AZP      string   `json:"azp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if strings.TrimSuffix(claims.Issuer, "/") != strings.TrimSuffix(rp.metadata.Issuer, "/") {
+		return nil, ErrBadIssuer
+	}
+
+	if !slices.Contains(claims.Audience, rp.config.ClientID) {
+		return nil, ErrBadAudience
+	}
+
+	if len(claims.Audience) > 1 && claims.AZP != rp.config.ClientID {
+		return nil, ErrBadAudience
+	}
+
+	now := time.Now()
+	expiry, issued := time.Unix(claims.Expiry, 0), time.Unix(claims.IssuedAt, 0)
+
+	if claims.Expiry == 0 || now.After(expiry.Add(Leeway)) {
+		return nil, ErrExpired
+	}
+
+	if issued.After(now.Add(Leeway)) {
+		return nil, ErrIssuedInTheFuture
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, ErrBadNonce
+	}
+
+	if claims.Subject == "" {
+		return nil, ErrMalformedToken
+	}
+
+	return &IDToken{
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+		Audience: claims.Audience,
+		Expiry:   expiry,
+		IssuedAt: issued,
+		Nonce:    claims.Nonce,
+		AMR:      claims.AMR,
+		claims:   payload,
+	}, nil
+}
+
+// key returns the key of the given id, fetching the key set if the
+// id is unknown.
+func (rp *RelyingParty) key(ctx context.Context, kid string) (any, error) {
+	defer rp.mu.Unlock()
+	rp.mu.Lock()
+
+	if key, ok := lookup(rp.keys, kid); ok {
+		return key, nil
+	}
+
+	if time.Since(rp.fetched) < _MinRefetch {
+		return nil, ErrUnknownKey
+	}
+
+	keys, err := fetchKeys(ctx, rp)
+	if err != nil {
+		return nil, err
+	}
+	rp.keys, rp.fetched = keys, time.Now()
+
+	if key, ok := lookup(rp.keys, kid); ok {
+		return key, nil
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// lookup finds the key of the given id in the set. Tokens may omit the
+// key id if there is a single key.
+func lookup(keys map[string]any, kid string) (any, bool) {
+	if key, in := keys[kid]; in {
+		return key, true
+	}
+
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func fetchKeys(ctx context.Context, rp *RelyingParty) (map[string]any, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := get(ctx, rp.client, rp.metadata.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k *jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, ErrMalformedToken
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, ErrUnsupportedAlg
+		}
+
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, ErrMalformedToken
+		}
+
+		return key, nil
+	}
+
+	return nil, ErrUnsupportedAlg
+}
+
+func verifySignature(alg string, key any, signed string, sig []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrUnsupportedAlg
+		}
+
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) != nil {
+			return ErrBadSignature
+		}
+
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return ErrBadSignature
+		}
+
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrBadSignature
+		}
+
+	default:
+		return ErrUnsupportedAlg
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	buf, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformedToken
+	}
+
+	if err := json.Unmarshal(buf, v); err != nil {
+		return ErrMalformedToken
+	}
+
+	return nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(buf) == 0 {
+		return nil, ErrMalformedToken
+	}
+
+	return new(big.Int).SetBytes(buf), nil
+}
+
+// audience is the aud claim, which is either a string or an array of
+// strings, see RFC 7519 section 4.1.3.
+type audience []string
+
+func (a *audience) UnmarshalJSON(buf []byte) error {
+	var single string
+	if err := json.Unmarshal(buf, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(buf, &multiple); err != nil {
+		return err
+	}
+
+	*a = multiple
+	return nil
+}
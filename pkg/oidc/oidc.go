@@ -0,0 +1,253 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package oidc implements an OpenID Connect relying party, using the
+// authorization code flow with PKCE, as defined in OpenID Connect
+// Core 1.0 section 3.1 and RFC 7636.
+//
+// Providers are configured through discovery, see OpenID Connect
+// Discovery 1.0, and ID tokens are verified against the keys
+// published by the provider, see RFC 7517. Only the RS256 and ES256
+// signature algorithms are accepted.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrIssuerMismatch = errors.New("oidc: issuer of the discovery document does not match")
+	ErrNoIDToken      = errors.New("oidc: token response has no id_token")
+)
+
+// Config configures a relying party.
+type Config struct {
+	// Issuer is the URL of the provider, from which the discovery
+	// document is fetched.
+	Issuer string
+
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is where the provider sends the user back to, with
+	// the authorization code.
+	RedirectURL string
+
+	// Scopes are requested in addition to "openid".
+	Scopes []string
+
+	// HTTPClient is used for every request to the provider, if nil,
+	// a client with a 10 seconds timeout is used.
+	HTTPClient *http.Client
+}
+
+// Metadata is the subset of the discovery document used by the
+// relying party.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// RelyingParty authenticates users through a provider.
+type RelyingParty struct {
+	config   Config
+	metadata Metadata
+	client   *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]any
+	fetched time.Time
+}
+
+// Token is the response of the token endpoint.
+type Token struct {
+	AccessToken string
+	TokenType   string
+	IDToken     string
+	Expiry      time.Time
+}
+
+// New creates a relying party for the provider of the config, whose
+// discovery document is fetched.
+func New(ctx context.Context, config Config) (*RelyingParty, error) {
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	issuer := strings.TrimSuffix(config.Issuer, "/")
+
+	var metadata Metadata
+	if err := get(ctx, client, issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(metadata.Issuer, "/") != issuer {
+		return nil, ErrIssuerMismatch
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is missing endpoints")
+	}
+
+	return &RelyingParty{
+		config:   config,
+		metadata: metadata,
+		client:   client,
+	}, nil
+}
+
+// Metadata returns the discovered metadata of the provider.
+func (rp *RelyingParty) Metadata() Metadata {
+	return rp.metadata
+}
+
+// AuthCodeURL returns the URL of the provider the user must be sent
+// to. The state, the nonce and the verifier must be kept by the
+// caller, bound to the user agent, until the user comes back.
+func (rp *RelyingParty) AuthCodeURL(state, nonce, verifier string) string {
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", rp.config.ClientID)
+	query.Set("redirect_uri", rp.config.RedirectURL)
+	query.Set("scope", strings.Join(rp.scopes(), " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", Challenge(verifier))
+	query.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(rp.metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return rp.metadata.AuthorizationEndpoint + sep + query.Encode()
+}
+
+// Exchange exchanges the authorization code for the tokens, proving
+// possession of the verifier the code was requested with.
+func (rp *RelyingParty) Exchange(ctx context.Context, code, verifier string) (Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", rp.config.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", rp.config.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rp.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if rp.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(rp.config.ClientID), url.QueryEscape(rp.config.ClientSecret))
+	}
+
+	res, err := rp.client.Do(req)
+	if err != nil {
+		return Token{}, err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		IDToken          string `json:"id_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&body); err != nil {
+		return Token{}, fmt.Errorf("oidc: malformed token response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK || body.Error != "" {
+		return Token{}, fmt.Errorf("oidc: token endpoint returned %d: %s %s", res.StatusCode, body.Error, body.ErrorDescription)
+	}
+
+	if body.IDToken == "" {
+		return Token{}, ErrNoIDToken
+	}
+
+	token := Token{
+		AccessToken: body.AccessToken,
+		TokenType:   body.TokenType,
+		IDToken:     body.IDToken,
+	}
+	if body.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+func (rp *RelyingParty) scopes() []string {
+	scopes := []string{"openid"}
+	for _, scope := range rp.config.Scopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+// NewRandom returns a random string fit for states, nonces and
+// verifiers, with 256 bits of entropy.
+func NewRandom() string {
+	var buf [32]byte
+	rand.Read(buf[:])
+	return base64.RawURLEncoding.EncodeToString(buf[:])
+}
+
+// Challenge returns the S256 code challenge of a verifier, see RFC
+// 7636 section 4.2.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func get(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: GET %s returned %d", url, res.StatusCode)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(v); err != nil {
+		return fmt.Errorf("oidc: malformed response of %s: %w", url, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/pkg/oidc"
+	"github.com/alan-b-lima/almodon/pkg/oidc/oidctest"
+)
+
+const redirectURL = "https://almodon.example/callback"
+
+func newRelyingParty(t *testing.T, srv *oidctest.Server) *RelyingParty {
+	rp, err := New(context.Background(), Config{
+		Issuer:       srv.Issuer(),
+		ClientID:     srv.ClientID,
+		ClientSecret: srv.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"profile", "email"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rp
+}
+
+// authorize follows the authorization URL as a user agent would, and
+// returns the query the provider redirected back with.
+func authorize(t *testing.T, authURL string) url.Values {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	res, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("expected a redirect, got %d", res.StatusCode)
+	}
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return location.Query()
+}
+
+func TestFlow(t *testing.T) {
+	srv := oidctest.NewServer("almodon", "client-secret")
+	defer srv.Close()
+
+	srv.Login(map[string]any{"sub": "123456", "name": "John Doe", "amr": []string{"pwd", "otp"}})
+
+	rp := newRelyingParty(t, srv)
+	state, nonce, verifier := NewRandom(), NewRandom(), NewRandom()
+
+	query := authorize(t, rp.AuthCodeURL(state, nonce, verifier))
+	if query.Get("state") != state {
+		t.Fatalf("state should be sent back")
+	}
+
+	if _, err := rp.Exchange(context.Background(), query.Get("code"), NewRandom()); err == nil {
+		t.Errorf("code should not be exchanged with another verifier")
+	}
+
+	query = authorize(t, rp.AuthCodeURL(state, nonce, verifier))
+
+	token, err := rp.Exchange(context.Background(), query.Get("code"), verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rp.Exchange(context.Background(), query.Get("code"), verifier); err == nil {
+		t.Errorf("code should not be exchanged twice")
+	}
+
+	id, err := rp.Verify(context.Background(), token.IDToken, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var claims struct {
+		Name string `json:"name"`
+	}
+	if err := id.Claims(&claims); err != nil {
+		t.Fatal(err)
+	}
+
+	if id.Subject != "123456" || claims.Name != "John Doe" || len(id.AMR) != 2 {
+		t.Errorf("unexpected token %+v with claims %+v", id, claims)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	srv := oidctest.NewServer("almodon", "client-secret")
+	defer srv.Close()
+
+	rp := newRelyingParty(t, srv)
+	now := time.Now()
+
+	type Tests struct {
+		name  string
+		token string
+		err   error
+	}
+
+	valid := srv.Sign(srv.Claims("nonce", map[string]any{"sub": "123456"}))
+	parts := strings.Split(valid, ".")
+
+	tests := []Tests{
+		{"valid", valid, nil},
+		{"wrong nonce", srv.Sign(srv.Claims("other", map[string]any{"sub": "123456"})), ErrBadNonce},
+		{"wrong audience", srv.Sign(srv.Claims("nonce", map[string]any{"sub": "123456", "aud": "other"})), ErrBadAudience},
+		{"multiple audiences without azp", srv.Sign(srv.Claims("nonce", map[string]any{"sub": "123456", "aud": []string{"almodon", "other"}})), ErrBadAudience},
+		{"multiple audiences with azp", srv.Sign(srv.Claims("nonce", map[string]any{"sub": "123456", "aud": []string{"almodon", "other"}, "azp": "almodon"})), nil},
+		{"wrong issuer", srv.Sign(srv.Claims("nonce", map[string]any{"sub": "123456", "iss": "https://evil.example"})), ErrBadIssuer},
+		{"expired", srv.Sign(srv.Claims("nonce", map[string]any{"sub": "123456", "exp": now.Add(-2 * Leeway).Unix()})), ErrExpired},
+		{"issued in the future", srv.Sign(srv.Claims("nonce", map[string]any{"sub": "123456", "iat": now.Add(2 * Leeway).Unix()})), ErrIssuedInTheFuture},
+		{"tampered", parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1"}`)) + "." + parts[2], ErrBadSignature},
+		{"alg none", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"oidctest"}`)) + "." + parts[1] + ".", ErrUnsupportedAlg},
+		{"malformed", "not.a-token", ErrMalformedToken},
+	}
+
+	for _, test := range tests {
+		if _, err := rp.Verify(context.Background(), test.token, "nonce"); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+// newES256Provider starts a provider publishing a single P-256 key
+// under the id "ec", whose tokens are signed by hand.
+func newES256Provider(t *testing.T) (*httptest.Server, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 srv.URL,
+				"authorization_endpoint": srv.URL + "/authorize",
+				"token_endpoint":         srv.URL + "/token",
+				"jwks_uri":               srv.URL + "/jwks",
+			})
+		case "/jwks":
+			json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+				"kty": "EC",
+				"kid": "ec",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+				"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+			}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return srv, key
+}
+
+// signES256 signs an ID token for the provider with the given header.
+func signES256(t *testing.T, srv *httptest.Server, key *ecdsa.PrivateKey, header map[string]string) string {
+	head, _ := json.Marshal(header)
+	payload, _ := json.Marshal(map[string]any{
+		"iss": srv.URL, "sub": "123456", "aud": "almodon", "nonce": "nonce",
+		"iat": time.Now().Unix(), "exp": time.Now().Add(time.Minute).Unix(),
+	})
+
+	signed := base64.RawURLEncoding.EncodeToString(head) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifyES256(t *testing.T) {
+	srv, key := newES256Provider(t)
+	defer srv.Close()
+
+	rp, err := New(context.Background(), Config{Issuer: srv.URL, ClientID: "almodon"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := signES256(t, srv, key, map[string]string{"alg": "ES256", "kid": "ec"})
+	if _, err := rp.Verify(context.Background(), raw, "nonce"); err != nil {
+		t.Errorf("ES256 token should be verified, got %v", err)
+	}
+}
+
+func TestVerifyWithoutKeyID(t *testing.T) {
+	srv, key := newES256Provider(t)
+	defer srv.Close()
+
+	rp, err := New(context.Background(), Config{Issuer: srv.URL, ClientID: "almodon"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first token fetches the keys, so the following ones are
+	// verified while refetches are throttled
+	type Tests struct {
+		header map[string]string
+		err    error
+	}
+
+	tests := []Tests{
+		{map[string]string{"alg": "ES256", "kid": "ec"}, nil},
+		{map[string]string{"alg": "ES256"}, nil},
+		{map[string]string{"alg": "ES256", "kid": "other"}, ErrUnknownKey},
+	}
+
+	for i, test := range tests {
+		raw := signES256(t, srv, key, test.header)
+		if _, err := rp.Verify(context.Background(), raw, "nonce"); !errors.Is(err, test.err) {
+			t.Errorf("%d: %v: expected %v, got %v", i, test.header, test.err, err)
+		}
+	}
+}
+
+func TestIssuerMismatch(t *testing.T) {
+	srv := oidctest.NewServer("almodon", "client-secret")
+	defer srv.Close()
+
+	_, err := New(context.Background(), Config{Issuer: srv.Issuer() + "/other", ClientID: "almodon"})
+	if err == nil {
+		t.Errorf("discovery should fail for another issuer")
+	}
+}
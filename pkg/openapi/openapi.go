@@ -0,0 +1,412 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package openapi generates OpenAPI 3.1 documents from routes, as
+// given to [net/http.ServeMux], and the Go types they exchange.
+//
+// Schemas are derived from types through reflection, following the
+// rules of [encoding/json]. Named struct types are described once,
+// as components, and referenced wherever used. Types that marshal
+// themselves are opaque to reflection, so they should be described
+// through [Document.Define], except for those with an
+// Unwrap() (T, bool) method, as optionals, which are described as T
+// or null.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Version is the version of the specification documents conform to.
+const Version = "3.1.0"
+
+// Document is an OpenAPI document, only the subset of the
+// specification used by this package is modeled.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	defined  map[reflect.Type]*Schema
+	fallback *Response
+}
+
+// Info holds metadata about the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL of the API, paths are relative to it.
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations over a path, keyed by their
+// lowercased method.
+type PathItem map[string]*Operation
+
+// Operation describes a single method over a path.
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter describes a path, query or header parameter.
+type Parameter struct {
+	Name       string  `json:"name"`
+	In         string  `json:"in"`
+	Required   bool    `json:"required,omitempty"`
+	Deprecated bool    `json:"deprecated,omitempty"`
+	Schema     *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response, its content is omitted for those
+// with no body.
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]*Header   `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header describes a header of a response.
+type Header struct {
+	Schema *Schema `json:"schema"`
+}
+
+// MediaType describes the schema of a body in a media type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas referenced throughout the document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema is a JSON Schema, as of the 2020-12 draft, restricted to
+// the keywords used by this package. The zero value accepts any
+// value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// Types are the types a value of a schema may have, it is marshaled
+// as a single string if it has only one.
+type Types []string
+
+// MarshalJSON implements the [json.Marshaler] interface on the type.
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface on the
+// type, accepting either a single string or many.
+func (t *Types) UnmarshalJSON(buf []byte) error {
+	var one string
+	if err := json.Unmarshal(buf, &one); err == nil {
+		*t = Types{one}
+		return nil
+	}
+
+	return json.Unmarshal(buf, (*[]string)(t))
+}
+
+// Ref references the named component schema.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Media types of the bodies described, responses with rows, as marked
+// by a field tagged with `encode:"rows"`, are also described as CSV
+// and as newline-delimited JSON of those rows.
+const (
+	JSON   = "application/json"
+	CSV    = "text/csv"
+	NDJSON = "application/x-ndjson"
+)
+
+// Route describes an operation by the pattern it is routed by and
+// the values it exchanges. Values are only used for their types, so
+// zero values suffice.
+type Route struct {
+	// Pattern is the pattern of the route, as given to
+	// [http.ServeMux], with a method and no host.
+	Pattern string
+
+	// ID uniquely identifies the operation.
+	ID      string
+	Summary string
+	Tags    []string
+
+	// Params holds values of the types of the path wildcards, by
+	// name, those absent are described as strings.
+	Params map[string]any
+
+	// Query is a struct whose fields tagged with "query" are the
+	// query parameters, those tagged as `query:"name,required"` being
+	// required, and those tagged as `query:"name,deprecated"` being
+	// deprecated.
+	Query any
+
+	// Headers is a struct whose fields tagged with "header" are the
+	// header parameters, those tagged as `header:"name,required"`
+	// being required.
+	Headers any
+
+	// Body is the body of requests, nil if none.
+	Body any
+
+	// Status is the status of successful responses, if zero, it is
+	// 200 OK if there is a response body, 204 No Content otherwise.
+	Status int
+
+	// Response is the body of successful responses, nil if none,
+	// its fields tagged with "header" are the headers of them, and
+	// its field tagged with `encode:"rows"`, the rows of them.
+	Response any
+}
+
+// New creates an empty document.
+func New(info Info, servers ...Server) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Servers: servers,
+		Paths:   make(map[string]*PathItem),
+		defined: make(map[reflect.Type]*Schema),
+	}
+
+	d.Define(reflect.TypeFor[json.RawMessage](), &Schema{})
+
+	return d
+}
+
+// Define sets the schema of a type, overriding its reflection.
+func (d *Document) Define(t reflect.Type, s *Schema) {
+	d.defined[t] = s
+}
+
+// Component sets a named component schema, and returns a reference
+// to it.
+func (d *Document) Component(name string, s *Schema) *Schema {
+	if d.Components.Schemas == nil {
+		d.Components.Schemas = make(map[string]*Schema)
+	}
+
+	d.Components.Schemas[name] = s
+	return Ref(name)
+}
+
+// Fallback sets the response of operations for statuses other than
+// the documented ones, as error responses.
+func (d *Document) Fallback(r *Response) {
+	d.fallback = r
+}
+
+// Add describes the routes in the document, each under the path of
+// its pattern.
+func (d *Document) Add(routes ...Route) {
+	for _, route := range routes {
+		method, path, _ := strings.Cut(route.Pattern, " ")
+		path, params := d.path(path, route.Params)
+
+		op := &Operation{
+			OperationID: route.ID,
+			Summary:     route.Summary,
+			Tags:        route.Tags,
+			Parameters:  params,
+			Responses:   make(map[string]*Response),
+		}
+
+		if route.Query != nil {
+			op.Parameters = append(op.Parameters, d.query(reflect.TypeOf(route.Query))...)
+		}
+
+		if route.Headers != nil {
+			op.Parameters = append(op.Parameters, d.headers(reflect.TypeOf(route.Headers))...)
+		}
+
+		if route.Body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{JSON: {d.Schema(reflect.TypeOf(route.Body))}},
+			}
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusNoContent
+			if route.Response != nil {
+				status = http.StatusOK
+			}
+		}
+
+		res := &Response{Description: http.StatusText(status)}
+		if route.Response != nil {
+			res.Content = map[string]MediaType{JSON: {d.Schema(reflect.TypeOf(route.Response))}}
+
+			if row, ok := rows(reflect.TypeOf(route.Response)); ok {
+				res.Content[CSV] = MediaType{&Schema{Type: Types{"string"}}}
+				res.Content[NDJSON] = MediaType{d.Schema(row)}
+			}
+
+			for _, param := range d.headers(reflect.TypeOf(route.Response)) {
+				if res.Headers == nil {
+					res.Headers = make(map[string]*Header)
+				}
+				res.Headers[param.Name] = &Header{Schema: param.Schema}
+			}
+		}
+
+		op.Responses[strconv.Itoa(status)] = res
+		if d.fallback != nil {
+			op.Responses["default"] = d.fallback
+		}
+
+		item, ok := d.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			d.Paths[path] = item
+		}
+
+		(*item)[strings.ToLower(method)] = op
+	}
+}
+
+// path translates the path of a pattern into an OpenAPI path and its
+// parameters. Both the {$} anchor and the trailing dots of wildcards
+// are dropped, as they have no counterpart.
+func (d *Document) path(pattern string, types map[string]any) (string, []Parameter) {
+	var params []Parameter
+
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if segment == "{$}" {
+			segments[i] = ""
+			continue
+		}
+
+		name, ok := strings.CutPrefix(segment, "{")
+		if !ok {
+			continue
+		}
+
+		name = strings.TrimSuffix(strings.TrimSuffix(name, "}"), "...")
+		segments[i] = "{" + name + "}"
+
+		schema := &Schema{Type: Types{"string"}}
+		if v, ok := types[name]; ok {
+			schema = d.Schema(reflect.TypeOf(v))
+		}
+
+		params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// query describes the fields of a struct tagged with "query" as
+// query parameters.
+func (d *Document) query(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var params []Parameter
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		name, opts, _ := strings.Cut(field.Tag.Get("query"), ",")
+		if name == "" || !field.IsExported() {
+			continue
+		}
+
+		params = append(params, Parameter{Name: name, In: "query", Required: opts == "required", Deprecated: opts == "deprecated", Schema: d.Schema(field.Type)})
+	}
+
+	return params
+}
+
+// headers describes the fields of a struct tagged with "header" as
+// header parameters, whose values are always strings.
+// rows returns the type of the rows of a response, the elements of its
+// field tagged with `encode:"rows"`, if it has one.
+func rows(t reflect.Type) (reflect.Type, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Tag.Get("encode") == "rows" && field.Type.Kind() == reflect.Slice {
+			return field.Type.Elem(), true
+		}
+	}
+
+	return nil, false
+}
+
+func (d *Document) headers(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []Parameter
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		name, opts, _ := strings.Cut(field.Tag.Get("header"), ",")
+		if name == "" || !field.IsExported() {
+			continue
+		}
+
+		params = append(params, Parameter{Name: name, In: "header", Required: opts == "required", Schema: &Schema{Type: Types{"string"}}})
+	}
+
+	return params
+}
@@ -0,0 +1,162 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/pkg/openapi"
+	"github.com/alan-b-lima/almodon/pkg/opt"
+)
+
+type (
+	Base struct {
+		ID  string `json:"id"`
+		Tag string `json:"-" header:"ETag"`
+	}
+
+	Node struct {
+		Base
+		Name     string             `json:"name"`
+		Note     string             `json:"note,omitempty"`
+		Secret   string             `json:"-"`
+		Created  time.Time          `json:"created"`
+		Expires  opt.Opt[time.Time] `json:"expires"`
+		Children []Node             `json:"children"`
+		Labels   map[string]int     `json:"labels"`
+		Raw      json.RawMessage    `json:"raw"`
+	}
+
+	Query struct {
+		Offset int    `query:"offset"`
+		Search string `query:"q"`
+		Match  string `json:"-" header:"If-Match,required"`
+		Ignore string
+	}
+
+	Page struct {
+		Nodes []Node `json:"nodes" encode:"rows"`
+		Next  string `json:"next" header:"Next-Cursor"`
+	}
+)
+
+func TestSchema(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+
+	ref := doc.Schema(reflect.TypeFor[*Node]())
+	if ref.Ref != "#/components/schemas/openapi_test.Node" {
+		t.Fatalf("expected a reference, got %+v", ref)
+	}
+
+	got, err := json.Marshal(doc.Components.Schemas["openapi_test.Node"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s map[string]any
+	json.Unmarshal(got, &s)
+
+	exp := `{"type":"object","properties":{` +
+		`"children":{"type":"array","items":{"$ref":"#/components/schemas/openapi_test.Node"}},` +
+		`"created":{"type":"string","format":"date-time"},` +
+		`"expires":{"type":["string","null"],"format":"date-time"},` +
+		`"id":{"type":"string"},` +
+		`"labels":{"type":"object","additionalProperties":{"type":"integer"}},` +
+		`"name":{"type":"string"},` +
+		`"note":{"type":"string"},` +
+		`"raw":{}},` +
+		`"required":["id","name","created","children","labels","raw"]}`
+
+	var e map[string]any
+	json.Unmarshal([]byte(exp), &e)
+
+	if !reflect.DeepEqual(s, e) {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, got)
+	}
+}
+
+func TestNullable(t *testing.T) {
+	type Tests struct {
+		in, out string
+	}
+
+	tests := []Tests{
+		{`{"type":"integer"}`, `{"type":["integer","null"]}`},
+		{`{"$ref":"#/components/schemas/A"}`, `{"oneOf":[{"$ref":"#/components/schemas/A"},{"type":"null"}]}`},
+		{`{}`, `{"oneOf":[{},{"type":"null"}]}`},
+	}
+
+	for _, test := range tests {
+		var in Schema
+		if err := json.Unmarshal([]byte(test.in), &in); err != nil {
+			t.Fatal(err)
+		}
+
+		got, _ := json.Marshal(Nullable(&in))
+		if string(got) != test.out {
+			t.Errorf("%s: expected %s, got %s", test.in, test.out, got)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+	doc.Fallback(&Response{Description: "Error"})
+
+	doc.Add(
+		Route{Pattern: "GET /nodes/{$}", ID: "list", Query: Query{}, Response: Page{}},
+		Route{Pattern: "POST /nodes/{$}", ID: "create", Body: Node{}, Status: http.StatusCreated, Response: Base{}},
+		Route{Pattern: "DELETE /nodes/{id}/{rest...}", ID: "delete", Params: map[string]any{"id": 0}, Headers: Query{}},
+	)
+
+	list := (*doc.Paths["/nodes/"])["get"]
+	if list == nil || list.OperationID != "list" {
+		t.Fatalf("expected the list operation under /nodes/, got %+v", doc.Paths)
+	}
+
+	if len(list.Parameters) != 2 || list.Parameters[0].Name != "offset" || list.Parameters[1].Name != "q" || list.Parameters[0].In != "query" {
+		t.Errorf("expected the offset and q query parameters, got %+v", list.Parameters)
+	}
+
+	if res, ok := list.Responses["200"]; !ok {
+		t.Errorf("expected a 200 response, got %v", list.Responses)
+	} else if _, ok := res.Content[CSV]; !ok || res.Content[NDJSON].Schema == nil || res.Content[NDJSON].Schema.Ref != "#/components/schemas/openapi_test.Node" {
+		t.Errorf("expected the rows of the list as CSV and NDJSON, got %+v", res.Content)
+	}
+
+	if list.Responses["default"] == nil || list.Responses["default"].Description != "Error" {
+		t.Errorf("expected the fallback response, got %v", list.Responses)
+	}
+
+	create := (*doc.Paths["/nodes/"])["post"]
+	if create == nil || create.RequestBody == nil || create.Responses["201"] == nil {
+		t.Fatalf("expected the create operation with a body and a 201 response, got %+v", create)
+	}
+
+	if _, ok := create.Responses["201"].Headers["ETag"]; !ok {
+		t.Errorf("expected the ETag header in the 201 response, got %+v", create.Responses["201"])
+	}
+
+	if _, ok := create.Responses["201"].Content[CSV]; ok {
+		t.Errorf("expected no CSV for a response without rows, got %+v", create.Responses["201"].Content)
+	}
+
+	del := (*doc.Paths["/nodes/{id}/{rest}"])["delete"]
+	if del == nil {
+		t.Fatalf("expected the delete operation under /nodes/{id}/{rest}, got %+v", doc.Paths)
+	}
+
+	if res := del.Responses["204"]; res == nil || res.Content != nil {
+		t.Errorf("expected a 204 response with no content, got %v", del.Responses)
+	}
+
+	if len(del.Parameters) != 3 || !del.Parameters[0].Required || del.Parameters[0].Schema.Type[0] != "integer" || del.Parameters[1].Schema.Type[0] != "string" {
+		t.Fatalf("expected the integer id and string rest path parameters, and a header, got %+v", del.Parameters)
+	}
+
+	if match := del.Parameters[2]; match.Name != "If-Match" || match.In != "header" || !match.Required {
+		t.Errorf("expected the required If-Match header, got %+v", match)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	_TypeTime          = reflect.TypeFor[time.Time]()
+	_TypeMarshaler     = reflect.TypeFor[json.Marshaler]()
+	_TypeTextMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Schema describes a type, named struct types are described as
+// components and referenced.
+func (d *Document) Schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if s, ok := d.defined[t]; ok {
+		return s
+	}
+
+	if inner, ok := optional(t); ok {
+		return Nullable(d.Schema(inner))
+	}
+
+	switch {
+	case t == _TypeTime:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+
+	case t.Implements(_TypeMarshaler):
+		return &Schema{}
+
+	case t.Implements(_TypeTextMarshaler):
+		return &Schema{Type: Types{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: Types{"string"}, Format: "byte"}
+		}
+
+		return &Schema{Type: Types{"array"}, Items: d.Schema(t.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: d.Schema(t.Elem())}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.object(t)
+		}
+
+		name := componentName(t)
+		if _, ok := d.Components.Schemas[name]; !ok {
+			// registered before being described, so that recursive
+			// types end up referencing themselves
+			ref := d.Component(name, &Schema{})
+			d.defined[t] = ref
+
+			*d.Components.Schemas[name] = *d.object(t)
+			return ref
+		}
+
+		return Ref(name)
+	}
+
+	return &Schema{}
+}
+
+// Nullable describes the values of a schema or null.
+func Nullable(s *Schema) *Schema {
+	if s.Ref != "" || len(s.Type) == 0 {
+		return &Schema{OneOf: []*Schema{s, {Type: Types{"null"}}}}
+	}
+
+	ns := *s
+	ns.Type = append(Types{}, s.Type...)
+	ns.Type = append(ns.Type, "null")
+	return &ns
+}
+
+// object describes the fields of a struct, as marshaled by
+// [encoding/json]. Untagged embedded structs have their fields
+// promoted, as their own.
+func (d *Document) object(t reflect.Type) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded := d.object(ft)
+			for name, prop := range embedded.Properties {
+				s.Properties[name] = prop
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = d.Schema(field.Type)
+
+		_, isOptional := optional(ft)
+		omitted := strings.Contains(","+opts+",", ",omitempty,") || strings.Contains(","+opts+",", ",omitzero,")
+		if !isOptional && !omitted {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
+
+// optional reports whether the type has an Unwrap() (T, bool)
+// method, and if so, the type T.
+func optional(t reflect.Type) (reflect.Type, bool) {
+	m, ok := t.MethodByName("Unwrap")
+	if !ok {
+		return nil, false
+	}
+
+	// the receiver is the first input of methods from types
+	mt := m.Type
+	if mt.NumIn() != 1 || mt.NumOut() != 2 || mt.Out(1).Kind() != reflect.Bool {
+		return nil, false
+	}
+
+	return mt.Out(0), true
+}
+
+// componentName names a type by its package and name, as
+// "user.Response", replacing the characters not allowed in component
+// names, as those of type arguments.
+func componentName(t reflect.Type) string {
+	name := path.Base(t.PkgPath()) + "." + t.Name()
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
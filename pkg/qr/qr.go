@@ -0,0 +1,559 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package qr implements a QR Code encoder, as defined in
+// ISO/IEC 18004.
+//
+// Only the subset needed for encoding short URIs is
+// supported: byte mode, error correction level M and
+// versions 1 through 10, that is, up to 213 bytes.
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// MaxVersion is the largest version supported by the encoder.
+const MaxVersion = 10
+
+// ErrTooLong is returned when the data does not fit in a code of the
+// largest supported version.
+var ErrTooLong = errors.New("qr: data too long to be encoded")
+
+// Code is a QR Code. Modules are indexed from the top left corner,
+// without the quiet zone.
+type Code struct {
+	size     int
+	version  int
+	modules  []bool
+	function []bool
+}
+
+// Encode encodes data in byte mode, with error correction level M,
+// in the smallest version it fits.
+func Encode(data []byte) (*Code, error) {
+	version := 0
+	for v := 1; v <= MaxVersion; v++ {
+		if capacity(v) >= len(data) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	size := 4*version + 17
+	c := &Code{
+		size:     size,
+		version:  version,
+		modules:  make([]bool, size*size),
+		function: make([]bool, size*size),
+	}
+
+	c.drawFunctionPatterns()
+	c.drawCodewords(codewords(version, data))
+
+	best, penalty := 0, -1
+	for mask := range 8 {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+
+		if p := c.penalty(); penalty < 0 || p < penalty {
+			best, penalty = mask, p
+		}
+
+		c.applyMask(mask) // masks are involutions
+	}
+
+	c.applyMask(best)
+	c.drawFormatBits(best)
+
+	return c, nil
+}
+
+// Size returns the number of modules in each side of the code.
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Version returns the version of the code.
+func (c *Code) Version() int {
+	return c.version
+}
+
+// Black returns whether the module at column x and row y is dark,
+// modules outside of the code are light.
+func (c *Code) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.size || y >= c.size {
+		return false
+	}
+
+	return c.modules[y*c.size+x]
+}
+
+// Image renders the code with scale pixels per module and a quiet
+// zone of 4 modules, as required by the standard.
+func (c *Code) Image(scale int) image.Image {
+	const quiet = 4
+
+	side := (c.size + 2*quiet) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+
+	for y := range side {
+		for x := range side {
+			if c.Black(x/scale-quiet, y/scale-quiet) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+
+	return img
+}
+
+// PNG renders the code as a PNG image, see [Code.Image].
+func (c *Code) PNG(scale int) ([]byte, error) {
+	var b bytes.Buffer
+	if err := png.Encode(&b, c.Image(scale)); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y*c.size+x] = dark
+}
+
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y*c.size+x] = dark
+	c.function[y*c.size+x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	for i := range c.size {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.size-4, 3)
+	c.drawFinder(3, c.size-4)
+
+	positions := alignments[c.version]
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+
+			c.drawAlignment(x, y)
+		}
+	}
+
+	c.drawFormatBits(0) // reserves the area, overwritten later
+	c.drawVersion()
+}
+
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.size || yy >= c.size {
+				continue
+			}
+
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+
+	for i := range 6 {
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := range 8 {
+		c.setFunction(c.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.size-15+i, bit(bits, i))
+	}
+
+	c.setFunction(8, c.size-8, true) // the dark module
+}
+
+func (c *Code) drawVersion() {
+	if c.version < 7 {
+		return
+	}
+
+	rem := c.version
+	for range 12 {
+		rem = rem<<1 ^ (rem>>11)*0x1f25
+	}
+	bits := c.version<<12 | rem
+
+	for i := range 18 {
+		a, b := c.size-11+i%3, i/3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+func (c *Code) drawCodewords(data []byte) {
+	var i int
+
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+
+		upward := (right+1)&2 == 0
+		for vert := range c.size {
+			for j := range 2 {
+				x, y := right-j, vert
+				if upward {
+					y = c.size - 1 - vert
+				}
+
+				if c.function[y*c.size+x] || i >= len(data)*8 {
+					continue
+				}
+
+				c.set(x, y, data[i>>3]>>(7-i&7)&1 == 1)
+				i++
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := range c.size {
+		for x := range c.size {
+			if c.function[y*c.size+x] {
+				continue
+			}
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			if invert {
+				c.modules[y*c.size+x] = !c.modules[y*c.size+x]
+			}
+		}
+	}
+}
+
+// penalty computes the penalty score of the code, as in ISO/IEC 18004
+// section 7.8.3, used for choosing the mask.
+func (c *Code) penalty() int {
+	var score, dark int
+
+	finder := []bool{true, false, true, true, true, false, true, false, false, false, false}
+
+	for i := range c.size {
+		row := make([]bool, c.size)
+		col := make([]bool, c.size)
+		for j := range c.size {
+			row[j] = c.Black(j, i)
+			col[j] = c.Black(i, j)
+
+			if row[j] {
+				dark++
+			}
+		}
+
+		for _, line := range [][]bool{row, col} {
+			run := 1
+			for j := 1; j <= len(line); j++ {
+				if j < len(line) && line[j] == line[j-1] {
+					run++
+					continue
+				}
+
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+
+			for j := 0; j+len(finder) <= len(line); j++ {
+				forward, backward := true, true
+				for k, f := range finder {
+					forward = forward && line[j+k] == f
+					backward = backward && line[j+k] == finder[len(finder)-1-k]
+				}
+
+				if forward {
+					score += 40
+				}
+				if backward {
+					score += 40
+				}
+			}
+		}
+	}
+
+	for y := range c.size - 1 {
+		for x := range c.size - 1 {
+			b := c.Black(x, y)
+			if b == c.Black(x+1, y) && b == c.Black(x, y+1) && b == c.Black(x+1, y+1) {
+				score += 3
+			}
+		}
+	}
+
+	percent := dark * 100 / (c.size * c.size)
+	score += abs(percent-50) / 5 * 10
+
+	return score
+}
+
+// formatBits returns the 15-bit format information for error
+// correction level M and the given mask, see ISO/IEC 18004 section
+// 7.9.
+func formatBits(mask int) int {
+	const levelM = 0b00
+
+	data := levelM<<3 | mask
+
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// capacity returns how many bytes can be encoded in byte mode in the
+// given version.
+func capacity(version int) int {
+	return (blocks[version].dataLen()*8 - 4 - countBits(version)) / 8
+}
+
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+
+	return 16
+}
+
+// codewords builds the final sequence of codewords, data and error
+// correction, interleaved by block, see ISO/IEC 18004 section 7.6.
+func codewords(version int, data []byte) []byte {
+	spec := blocks[version]
+
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := spec.dataLen() * 8
+	bits.append(0, min(4, capacity-bits.len()))
+	bits.append(0, (8-bits.len()%8)%8)
+	for pad := 0xec; bits.len() < capacity; pad ^= 0xec ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	stream := bits.bytes()
+
+	var dataBlocks, ecBlocks [][]byte
+	var offset int
+	for _, group := range spec.groups {
+		for range group.count {
+			block := stream[offset : offset+group.dataLen]
+			offset += group.dataLen
+
+			dataBlocks = append(dataBlocks, block)
+			ecBlocks = append(ecBlocks, reedSolomon(block, spec.ecLen))
+		}
+	}
+
+	result := make([]byte, 0, spec.dataLen()+len(ecBlocks)*spec.ecLen)
+	for i := range spec.groups[len(spec.groups)-1].dataLen {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := range spec.ecLen {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+
+	return result
+}
+
+type bitBuffer struct {
+	bits []bool
+}
+
+func (b *bitBuffer) len() int { return len(b.bits) }
+
+func (b *bitBuffer) append(val, n int) {
+	for i := n - 1; i >= 0; i-- {
+		b.bits = append(b.bits, val>>i&1 == 1)
+	}
+}
+
+func (b *bitBuffer) bytes() []byte {
+	res := make([]byte, (len(b.bits)+7)/8)
+	for i, bit := range b.bits {
+		if bit {
+			res[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+
+	return res
+}
+
+// reedSolomon computes n error correction codewords for data, over
+// GF(2^8) with the primitive polynomial x^8 + x^4 + x^3 + x^2 + 1.
+func reedSolomon(data []byte, n int) []byte {
+	divisor := make([]byte, n)
+	divisor[n-1] = 1
+
+	root := byte(1)
+	for range n {
+		for j := range n {
+			divisor[j] = gfMul(divisor[j], root)
+			if j+1 < n {
+				divisor[j] ^= divisor[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+
+	result := make([]byte, n)
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[n-1] = 0
+
+		for i, coef := range divisor {
+			result[i] ^= gfMul(coef, factor)
+		}
+	}
+
+	return result
+}
+
+func gfMul(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11d
+		z ^= int(y>>i&1) * int(x)
+	}
+
+	return byte(z)
+}
+
+type group struct {
+	count   int
+	dataLen int
+}
+
+type blockSpec struct {
+	ecLen  int
+	groups []group
+}
+
+func (s blockSpec) dataLen() int {
+	var n int
+	for _, g := range s.groups {
+		n += g.count * g.dataLen
+	}
+
+	return n
+}
+
+// blocks holds the block structure for error correction level M, see
+// ISO/IEC 18004 table 9.
+var blocks = [MaxVersion + 1]blockSpec{
+	1:  {10, []group{{1, 16}}},
+	2:  {16, []group{{1, 28}}},
+	3:  {26, []group{{1, 44}}},
+	4:  {18, []group{{2, 32}}},
+	5:  {24, []group{{2, 43}}},
+	6:  {16, []group{{4, 27}}},
+	7:  {18, []group{{4, 31}}},
+	8:  {22, []group{{2, 38}, {2, 39}}},
+	9:  {22, []group{{3, 36}, {2, 37}}},
+	10: {26, []group{{4, 43}, {1, 44}}},
+}
+
+// alignments holds the alignment pattern center coordinates, see
+// ISO/IEC 18004 annex E.
+var alignments = [MaxVersion + 1][]int{
+	1:  {},
+	2:  {6, 18},
+	3:  {6, 22},
+	4:  {6, 26},
+	5:  {6, 30},
+	6:  {6, 34},
+	7:  {6, 22, 38},
+	8:  {6, 24, 42},
+	9:  {6, 26, 46},
+	10: {6, 28, 50},
+}
+
+func bit(x, i int) bool {
+	return x>>i&1 == 1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
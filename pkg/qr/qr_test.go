@@ -0,0 +1,60 @@
+package qr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestReedSolomon uses the codewords of "HELLO WORLD", encoded in
+// alphanumeric mode in a version 1-M code, as the test vector.
+func TestReedSolomon(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	expected := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if ec := reedSolomon(data, len(expected)); !bytes.Equal(ec, expected) {
+		t.Errorf("expected %v, got %v", expected, ec)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	expected := [8]int{
+		0b101010000010010, 0b101000100100101, 0b101111001111100, 0b101101101001011,
+		0b100010111111001, 0b100000011001110, 0b100111110010111, 0b100101010100000,
+	}
+
+	for mask, bits := range expected {
+		if formatBits(mask) != bits {
+			t.Errorf("mask %d: expected %015b, got %015b", mask, bits, formatBits(mask))
+		}
+	}
+}
+
+func TestEncodeVersion(t *testing.T) {
+	type Tests struct {
+		length  int
+		version int
+	}
+
+	tests := []Tests{{1, 1}, {14, 1}, {15, 2}, {84, 5}, {122, 7}, {213, 10}, {214, 0}}
+
+	for _, test := range tests {
+		c, err := Encode([]byte(strings.Repeat("a", test.length)))
+
+		if test.version == 0 {
+			if err != ErrTooLong {
+				t.Errorf("length %d: expected ErrTooLong, got %v", test.length, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("length %d: did not expect error, but got: %v", test.length, err)
+			continue
+		}
+
+		if c.Version() != test.version || c.Size() != 4*test.version+17 {
+			t.Errorf("length %d: expected version %d, got %d", test.length, test.version, c.Version())
+		}
+	}
+}
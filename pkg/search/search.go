@@ -0,0 +1,253 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package search implements an in-memory full-text index over short
+// texts, such as names and codes, written in languages of the Latin
+// script, as Portuguese.
+//
+// Texts are folded, that is, lowercased and stripped of diacritics,
+// and split into terms, so that "Anestésico" is found by "anestesico",
+// and by "ANEST", as every term of a query is matched as the prefix
+// of a term of the documents.
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Fold lowercases the text and strips it of diacritics, either
+// precomposed or given as combining marks, ligatures are spelled out.
+func Fold(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for _, r := range text {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		r = unicode.ToLower(r)
+		if folded, ok := _Folds[r]; ok {
+			b.WriteString(folded)
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// _Folds maps the lowercase letters of the Latin-1 Supplement and
+// Latin Extended-A blocks to their letters without diacritics.
+var _Folds = func() map[rune]string {
+	folds := map[rune]string{
+		'æ': "ae", 'œ': "oe", 'ß': "ss", 'ø': "o", 'đ': "d", 'ħ': "h",
+		'ı': "i", 'ĳ': "ij", 'ł': "l", 'ŀ': "l", 'ŉ': "n", 'ſ': "s",
+		'þ': "th", 'ð': "d",
+	}
+
+	for base, letters := range map[string]string{
+		"a": "àáâãäåāăą",
+		"c": "çćĉċč",
+		"d": "ď",
+		"e": "èéêëēĕėęě",
+		"g": "ĝğġģ",
+		"h": "ĥ",
+		"i": "ìíîïĩīĭį",
+		"j": "ĵ",
+		"k": "ķ",
+		"l": "ĺļľ",
+		"n": "ñńņňŋ",
+		"o": "òóôõöōŏő",
+		"r": "ŕŗř",
+		"s": "śŝşš",
+		"t": "ţťŧ",
+		"u": "ùúûüũūŭůűų",
+		"w": "ŵ",
+		"y": "ýÿŷ",
+		"z": "źżž",
+	} {
+		for _, r := range letters {
+			folds[r] = base
+		}
+	}
+
+	return folds
+}()
+
+// Terms folds the text and splits it into its terms, the runs of
+// letters and digits within it.
+func Terms(text string) []string {
+	return strings.FieldsFunc(Fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Field is a text of a document, matches within it are scored by its
+// weight.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Result is a document matching a query, along with its score.
+type Result[K comparable] struct {
+	Key   K
+	Score float64
+}
+
+// Index is an index of documents, identified by keys. It is not safe
+// for concurrent use, the zero value is not ready for use, see
+// [NewIndex].
+type Index[K comparable] struct {
+	docs     map[K]*document
+	postings map[string]map[K]float64
+	terms    []string // sorted, for prefixes to be looked up
+	seq      uint64
+}
+
+type document struct {
+	terms []string
+	seq   uint64
+}
+
+// NewIndex creates an empty index.
+func NewIndex[K comparable]() *Index[K] {
+	return &Index[K]{
+		docs:     make(map[K]*document),
+		postings: make(map[string]map[K]float64),
+	}
+}
+
+// Len returns the number of documents in the index.
+func (ix *Index[K]) Len() int {
+	return len(ix.docs)
+}
+
+// Add indexes the document under the key, replacing the one
+// previously there, if any. Terms found in many fields are weighted
+// by the heaviest of them.
+func (ix *Index[K]) Add(key K, fields ...Field) {
+	seq := ix.seq
+	if doc, ok := ix.docs[key]; ok {
+		seq = doc.seq
+		ix.Remove(key)
+	} else {
+		ix.seq++
+	}
+
+	doc := &document{seq: seq}
+	for _, field := range fields {
+		for _, term := range Terms(field.Text) {
+			posting, ok := ix.postings[term]
+			if !ok {
+				posting = make(map[K]float64)
+				ix.postings[term] = posting
+
+				i, _ := slices.BinarySearch(ix.terms, term)
+				ix.terms = slices.Insert(ix.terms, i, term)
+			}
+
+			weight, ok := posting[key]
+			if !ok {
+				doc.terms = append(doc.terms, term)
+			}
+
+			posting[key] = max(weight, field.Weight)
+		}
+	}
+
+	ix.docs[key] = doc
+}
+
+// Remove removes the document under the key from the index, if there.
+func (ix *Index[K]) Remove(key K) {
+	doc, ok := ix.docs[key]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		posting := ix.postings[term]
+		delete(posting, key)
+
+		if len(posting) == 0 {
+			delete(ix.postings, term)
+
+			i, _ := slices.BinarySearch(ix.terms, term)
+			ix.terms = slices.Delete(ix.terms, i, i+1)
+		}
+	}
+
+	delete(ix.docs, key)
+}
+
+// Search finds the documents matching every term of the query, as a
+// prefix of any of their terms, ranked by score, at most limit of
+// them. Each term of the query scores the weight of the term it
+// matches best, in full, if matching it exactly, or as much as it
+// covers of it, otherwise. Ties are ranked in the order documents
+// were first added.
+func (ix *Index[K]) Search(query string, limit int) []Result[K] {
+	terms := Terms(query)
+	if len(terms) == 0 || limit <= 0 {
+		return []Result[K]{}
+	}
+
+	var scores map[K]float64
+	for _, qterm := range terms {
+		matches := make(map[K]float64)
+
+		i, _ := slices.BinarySearch(ix.terms, qterm)
+		for _, term := range ix.terms[i:] {
+			if !strings.HasPrefix(term, qterm) {
+				break
+			}
+
+			coverage := float64(len(qterm)) / float64(len(term))
+			for key, weight := range ix.postings[term] {
+				if scores != nil {
+					if _, ok := scores[key]; !ok {
+						continue
+					}
+				}
+
+				matches[key] = max(matches[key], weight*coverage)
+			}
+		}
+
+		if scores != nil {
+			for key, score := range matches {
+				matches[key] = score + scores[key]
+			}
+		}
+
+		scores = matches
+		if len(scores) == 0 {
+			break
+		}
+	}
+
+	results := make([]Result[K], 0, len(scores))
+	for key, score := range scores {
+		results = append(results, Result[K]{Key: key, Score: score})
+	}
+
+	slices.SortFunc(results, func(a, b Result[K]) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(ix.docs[a.Key].seq, ix.docs[b.Key].seq)
+	})
+
+	return results[:min(limit, len(results))]
+}
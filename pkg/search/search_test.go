@@ -0,0 +1,106 @@
+package search_test
+
+import (
+	"slices"
+	"testing"
+
+	. "github.com/alan-b-lima/almodon/pkg/search"
+)
+
+func TestFold(t *testing.T) {
+	type Tests struct {
+		in, exp string
+	}
+
+	tests := []Tests{
+		{"Anestésico", "anestesico"},
+		{"AÇÃO", "acao"},
+		{"Anestêsico", "anestesico"},
+		{"Anéstesico", "anestesico"},
+		{"Pinça Anatômica Nº 14", "pinca anatomica nº 14"},
+		{"Œuvre", "oeuvre"},
+	}
+
+	for _, test := range tests {
+		if got := Fold(test.in); got != test.exp {
+			t.Errorf("Fold(%q): expected %q, got %q", test.in, test.exp, got)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	got := Terms("Luva de procedimento, látex (tam. M) - 100un")
+	exp := []string{"luva", "de", "procedimento", "latex", "tam", "m", "100un"}
+
+	if !slices.Equal(got, exp) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ix := NewIndex[string]()
+
+	ix.Add("resina", Field{"Resina composta fotopolimerizável", 2}, Field{"Resina para restaurações", 1}, Field{"445566", 1})
+	ix.Add("anestesico", Field{"Anestésico local injetável", 2}, Field{"Lidocaína com epinefrina", 1}, Field{"270911", 1})
+	ix.Add("luva", Field{"Luva de procedimento", 2}, Field{"Látex, não estéril", 1}, Field{"269944", 1})
+	ix.Add("luva-cirurgica", Field{"Luva cirúrgica estéril", 2}, Field{"Látex", 1}, Field{"269945", 1})
+	ix.Add("sugador", Field{"Sugador descartável", 2}, Field{"Para uso com resina e anestesia", 1})
+
+	type Tests struct {
+		query string
+		exp   []string
+	}
+
+	tests := []Tests{
+		{"resina", []string{"resina", "sugador"}},
+		{"RESINA", []string{"resina", "sugador"}},
+		{"anestésico", []string{"anestesico"}},
+		{"anestesico", []string{"anestesico"}},
+		{"anest", []string{"anestesico", "sugador"}},
+		{"luva", []string{"luva", "luva-cirurgica"}},
+		{"luva esteril", []string{"luva-cirurgica", "luva"}},
+		{"luva cir", []string{"luva-cirurgica"}},
+		{"2699", []string{"luva", "luva-cirurgica"}},
+		{"269945", []string{"luva-cirurgica"}},
+		{"seringa", []string{}},
+		{"", []string{}},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, res := range ix.Search(test.query, 10) {
+			got = append(got, res.Key)
+		}
+
+		if !slices.Equal(got, test.exp) {
+			t.Errorf("%q: expected %v, got %v", test.query, test.exp, got)
+		}
+	}
+
+	if got := ix.Search("luva", 1); len(got) != 1 || got[0].Key != "luva" {
+		t.Errorf("expected only the best result, got %v", got)
+	}
+}
+
+func TestAddAndRemove(t *testing.T) {
+	ix := NewIndex[int]()
+
+	ix.Add(1, Field{"Gaze estéril", 1})
+	ix.Add(2, Field{"Gaze hidrófila", 1})
+	ix.Add(1, Field{"Algodão hidrófilo", 1})
+
+	if got := ix.Search("esteril", 10); len(got) != 0 {
+		t.Errorf("expected replaced terms to be gone, got %v", got)
+	}
+
+	if got := ix.Search("hidrof", 10); len(got) != 2 || got[0].Key != 1 {
+		t.Errorf("expected documents to keep their rank, got %v", got)
+	}
+
+	ix.Remove(2)
+	ix.Remove(3)
+
+	if got := ix.Search("gaze", 10); len(got) != 0 || ix.Len() != 1 {
+		t.Errorf("expected removed documents to be gone, got %v", got)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package totp implements the Time-Based One-Time Password
+// algorithm, as defined in RFC 6238, over the HMAC-Based
+// One-Time Password algorithm, as defined in RFC 4226.
+//
+// Only the parameters understood by virtually every
+// authenticator application are supported: HMAC-SHA1, 6
+// digits and 30 seconds steps.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits is the number of digits of a generated code.
+	Digits = 6
+
+	// Period is the duration of a time step.
+	Period = 30 * time.Second
+
+	// SecretSize is the size, in bytes, of a generated secret, as
+	// recommended by RFC 4226 section 4.
+	SecretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var ErrBadSecret = errors.New("totp: secret is not valid base32")
+
+// NewSecret generates a new random secret.
+func NewSecret() []byte {
+	secret := make([]byte, SecretSize)
+	rand.Read(secret)
+	return secret
+}
+
+// EncodeSecret encodes a secret in unpadded base32, the format
+// expected by authenticator applications.
+func EncodeSecret(secret []byte) string {
+	return encoding.EncodeToString(secret)
+}
+
+// DecodeSecret decodes a secret from base32, padded or not,
+// ignoring spaces and letter case.
+func DecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+
+	buf, err := encoding.DecodeString(secret)
+	if err != nil {
+		return nil, ErrBadSecret
+	}
+
+	return buf, nil
+}
+
+// Step returns the time step t falls into.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code for the time step t falls into.
+func Code(secret []byte, t time.Time) string {
+	return hotp(secret, uint64(Step(t)), Digits)
+}
+
+// Verify checks code against the codes of the time step t falls into
+// and of skew steps before and after it, to account for clock drift.
+// It returns the matching step, that callers should store to refuse
+// the reuse of a code, and whether any step matched.
+func Verify(secret []byte, code string, t time.Time, skew int) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	step := Step(t)
+	for i := -int64(skew); i <= int64(skew); i++ {
+		expected := hotp(secret, uint64(step+i), Digits)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step + i, true
+		}
+	}
+
+	return 0, false
+}
+
+// URI returns the otpauth URI for the secret, as understood by
+// authenticator applications, usually transmitted through a QR code.
+//
+// See https://github.com/google/google-authenticator/wiki/Key-Uri-Format.
+func URI(issuer, account string, secret []byte) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+
+	query := url.Values{}
+	query.Set("secret", EncodeSecret(secret))
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period/time.Second)))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// hotp implements the HOTP algorithm, see RFC 4226 section 5.
+func hotp(secret []byte, counter uint64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	binary := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range digits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, binary%mod)
+}
@@ -0,0 +1,83 @@
+package totp_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/alan-b-lima/almodon/pkg/totp"
+)
+
+var secret = []byte("12345678901234567890")
+
+// TestCode uses the test vectors of RFC 6238 appendix B, truncated to
+// 6 digits, which are equivalent to the last 6 digits of the 8 digit
+// codes given there.
+func TestCode(t *testing.T) {
+	type Tests struct {
+		unix int64
+		code string
+	}
+
+	tests := []Tests{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range tests {
+		if code := Code(secret, time.Unix(test.unix, 0)); code != test.code {
+			t.Errorf("at %d: expected %s, got %s", test.unix, test.code, code)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code := Code(secret, now)
+
+	if step, ok := Verify(secret, code, now.Add(Period), 1); !ok || step != Step(now) {
+		t.Errorf("code of the previous step should be accepted with skew 1")
+	}
+
+	if _, ok := Verify(secret, code, now.Add(2*Period), 1); ok {
+		t.Errorf("code of two steps before should not be accepted with skew 1")
+	}
+
+	if _, ok := Verify(secret, "12345", now, 1); ok {
+		t.Errorf("code with wrong length should not be accepted")
+	}
+}
+
+func TestSecretEncoding(t *testing.T) {
+	for range 100 {
+		secret := NewSecret()
+
+		decoded, err := DecodeSecret(EncodeSecret(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(decoded) != string(secret) {
+			t.Errorf("%x and %x should be equal", secret, decoded)
+		}
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("Almodon", "123456", secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Almodon:123456" {
+		t.Errorf("unexpected URI %v", uri)
+	}
+
+	if uri.Query().Get("secret") != EncodeSecret(secret) {
+		t.Errorf("unexpected secret in URI %v", uri)
+	}
+}
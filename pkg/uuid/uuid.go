@@ -34,6 +34,9 @@ const (
 var (
 	source rand.Source // The source for all pseudo-random number needed.
 	mulock sync.Mutex  // A mutex for safe concurrent UUID generation.
+
+	lastMillis  uint64 // The timestamp of the last UUIDv7 generated.
+	lastCounter uint64 // The counter of the last UUIDv7 generated.
 )
 
 func init() {
@@ -52,7 +55,9 @@ var (
 var _UUIDFormat = "%02x%02x%02x%02x-%02x%02x-%02x%02x-%02x%02x-%02x%02x%02x%02x%02x%02x"
 
 // Generates a new UUID accourding to version 7. It's safe to call
-// this function from multiple goroutines.
+// this function from multiple goroutines. UUIDs generated by the
+// process are strictly ascending, as they are ordered by the counter
+// within the same millisecond, see RFC9562 section 6.2, method 1.
 //
 // The memory layout of a UUIDv7 is as follows:
 //
@@ -63,8 +68,10 @@ var _UUIDFormat = "%02x%02x%02x%02x-%02x%02x-%02x%02x-%02x%02x-%02x%02x%02x%02x%
 //   - Version: 4-bit version field, set to 0b0111 (7). Occupies bits
 //     48 through 51, octet 6.
 //
-//   - Random A: 12-bit pseudorandom data to provide uniqueness.
-//     Occupies bits 52 through 63, octects 6 through 7.
+//   - Counter: 12-bit counter, seeded with 11-bit pseudorandom data
+//     on each millisecond and incremented for every UUID generated
+//     within it, the timestamp is advanced if it overflows. Occupies
+//     bits 52 through 63, octects 6 through 7.
 //
 //   - Variant: 2-bit variant field, set to 0b10. Occupies bits 64
 //     through 65, octet 8.
@@ -80,7 +87,16 @@ func NewUUIDv7() UUID {
 	unixTimestamp := uint64(time.Now().UnixMilli() & _48BitMask)
 
 	mulock.Lock()
-	randA := source.Uint64() & _12BitMask
+	if unixTimestamp > lastMillis {
+		lastMillis = unixTimestamp
+		lastCounter = source.Uint64() & (_12BitMask >> 1)
+	} else if lastCounter++; lastCounter > _12BitMask {
+		lastMillis++
+		lastCounter = 0
+	}
+
+	unixTimestamp = lastMillis
+	randA := lastCounter
 	randB := source.Uint64() & _62BitMask
 	mulock.Unlock()
 
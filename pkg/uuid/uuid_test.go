@@ -1,6 +1,7 @@
 package uuid_test
 
 import (
+	"bytes"
 	"crypto/rand"
 	"sync"
 	"testing"
@@ -42,6 +43,20 @@ func TestConcurrentUUIDGeneration(t *testing.T) {
 	}
 }
 
+func TestUUIDv7Ordering(t *testing.T) {
+	const numTests = 10000
+
+	prev := NewUUIDv7()
+	for range numTests {
+		uuid := NewUUIDv7()
+		if bytes.Compare(prev[:], uuid[:]) >= 0 {
+			t.Fatalf("%v should come before %v", prev, uuid)
+		}
+
+		prev = uuid
+	}
+}
+
 func TestInversabilityBetweenStringAndFromString(t *testing.T) {
 	const numTests = 1000
 
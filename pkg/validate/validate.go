@@ -0,0 +1,181 @@
+// Copyright (C) 2025 Alan Barbosa Lima.
+//
+// Almodon is licensed under the GNU General Public License
+// version 3. You should have received a copy of the
+// license, located in LICENSE, at the root of the source
+// tree. If not, see <https://www.gnu.org/licenses/>.
+
+// Package validate implements declarative validation of values
+// through rules, reporting failures along with the field they are
+// about, located by a JSON pointer, as defined by RFC 6901.
+//
+// Rules are plain functions, those given here are built over the
+// error to be reported, so that each failure keeps its own kind,
+// title and message:
+//
+//	err := errors.Join(
+//		validate.Field("/name", name, validate.Required[string](ErrNameEmpty)),
+//		validate.Field("/email", email, validate.Match(reEmail, ErrEmailInvalid)),
+//	)
+package validate
+
+import (
+	"cmp"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alan-b-lima/almodon/pkg/errors"
+)
+
+// Rule checks a value, returning the reason it fails, if it does.
+type Rule[T any] func(T) error
+
+// Value checks the value against the rules, in order, returning the
+// first failure.
+func Value[T any](val T, rules ...Rule[T]) error {
+	for _, rule := range rules {
+		if err := rule(val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Field checks the value of the field located by the pointer, as
+// [Value] does, reporting the failure as a [*FieldError].
+func Field[T any](pointer string, val T, rules ...Rule[T]) error {
+	return At(pointer, Value(val, rules...))
+}
+
+// At locates the error at the field pointed to, nil errors are left
+// nil.
+func At(pointer string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &FieldError{Pointer: pointer, Err: err}
+}
+
+// Pointer builds a JSON pointer out of its reference tokens, escaping
+// them as needed.
+func Pointer(tokens ...string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(_Escaper.Replace(token))
+	}
+
+	return b.String()
+}
+
+var _Escaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// FieldError is the failure of a field, located by a JSON pointer.
+type FieldError struct {
+	Pointer string
+	Err     error
+}
+
+// Error implements the [error] interface.
+func (e *FieldError) Error() string {
+	return e.Pointer + ": " + e.Err.Error()
+}
+
+// Unwrap returns the failure.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON implements the [json.Marshaler] interface on the type.
+// The failure is marshaled as by [errors.Wrap], with the pointer
+// added to it, if an object, or else along with it, as its message.
+func (e FieldError) MarshalJSON() ([]byte, error) {
+	buf, err := json.Marshal(errors.Wrap(e.Err))
+	if err != nil {
+		return nil, err
+	}
+
+	pointer := `{"pointer":` + strconv.Quote(e.Pointer)
+
+	switch {
+	case string(buf) == "{}":
+		return []byte(pointer + `}`), nil
+
+	case buf[0] == '{':
+		return append([]byte(pointer+`,`), buf[1:]...), nil
+	}
+
+	return append(append([]byte(pointer+`,"message":`), buf...), '}'), nil
+}
+
+// Required requires values to be other than zero.
+func Required[T comparable](err error) Rule[T] {
+	return func(val T) error {
+		var zero T
+		if val == zero {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// MinLength requires strings to be at least n bytes long.
+func MinLength(n int, err error) Rule[string] {
+	return func(val string) error {
+		if len(val) < n {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// MaxLength requires strings to be at most n bytes long.
+func MaxLength(n int, err error) Rule[string] {
+	return func(val string) error {
+		if len(val) > n {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// Match requires strings to match the regular expression.
+func Match(re *regexp.Regexp, err error) Rule[string] {
+	return func(val string) error {
+		if !re.MatchString(val) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// Range requires values to be within min and max, inclusive.
+func Range[T cmp.Ordered](min, max T, err error) Rule[T] {
+	return func(val T) error {
+		if val < min || max < val {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// OneOf requires values to be one of the given ones.
+func OneOf[T comparable](values []T, err error) Rule[T] {
+	return func(val T) error {
+		if !slices.Contains(values, val) {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+package validate_test
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"regexp"
+	"testing"
+
+	"github.com/alan-b-lima/almodon/pkg/errors"
+	. "github.com/alan-b-lima/almodon/pkg/validate"
+)
+
+var (
+	errEmpty   = errors.New(errors.InvalidInput, "empty", "must not be empty", nil)
+	errShort   = errors.New(errors.InvalidInput, "short", "too short", nil)
+	errDigits  = errors.New(errors.InvalidInput, "digits", "must be digits", nil)
+	errRange   = errors.New(errors.InvalidInput, "range", "out of range", nil)
+	errUnknown = errors.New(errors.InvalidInput, "unknown", "unknown value", nil)
+)
+
+func TestValue(t *testing.T) {
+	rules := []Rule[string]{
+		Required[string](errEmpty),
+		MinLength(3, errShort),
+		Match(regexp.MustCompile(`^[0-9]+$`), errDigits),
+	}
+
+	type Tests struct {
+		val string
+		err error
+	}
+
+	tests := []Tests{
+		{"", errEmpty},
+		{"12", errShort},
+		{"12a", errDigits},
+		{"123", nil},
+	}
+
+	for _, test := range tests {
+		if err := Value(test.val, rules...); err != test.err {
+			t.Errorf("%q: expected %v, got %v", test.val, test.err, err)
+		}
+	}
+
+	if err := Value(11, Range(1, 10, errRange)); err != errRange {
+		t.Errorf("expected %v, got %v", errRange, err)
+	}
+
+	if err := Value("c", OneOf([]string{"a", "b"}, errUnknown)); err != errUnknown {
+		t.Errorf("expected %v, got %v", errUnknown, err)
+	}
+}
+
+func TestField(t *testing.T) {
+	if err := Field("/name", "x", Required[string](errEmpty)); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	err := Field("/name", "", Required[string](errEmpty))
+
+	var ferr *FieldError
+	if !stderrors.As(err, &ferr) || ferr.Pointer != "/name" || !stderrors.Is(err, errEmpty) {
+		t.Fatalf("expected the failure at /name, got %v", err)
+	}
+}
+
+func TestPointer(t *testing.T) {
+	type Tests struct {
+		tokens []string
+		exp    string
+	}
+
+	tests := []Tests{
+		{nil, ""},
+		{[]string{"items", "0", "name"}, "/items/0/name"},
+		{[]string{"a/b", "m~n"}, "/a~1b/m~0n"},
+	}
+
+	for _, test := range tests {
+		if got := Pointer(test.tokens...); got != test.exp {
+			t.Errorf("%q: expected %q, got %q", test.tokens, test.exp, got)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	type Tests struct {
+		err error
+		exp string
+	}
+
+	tests := []Tests{
+		{At("/name", errEmpty), `{"pointer":"/name","kind":"invalid input","title":"empty","message":"must not be empty"}`},
+		{At("/name", stderrors.New("bad name")), `{"pointer":"/name","message":"bad name"}`},
+		{
+			errors.Join(At("/name", errEmpty), At("/code", errDigits)),
+			`[{"pointer":"/name","kind":"invalid input","title":"empty","message":"must not be empty"},` +
+				`{"pointer":"/code","kind":"invalid input","title":"digits","message":"must be digits"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		buf, err := json.Marshal(test.err)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(buf) != test.exp {
+			t.Errorf("expected %s, got %s", test.exp, buf)
+		}
+	}
+}